@@ -5,7 +5,8 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // User ID context key
@@ -13,6 +14,7 @@ type contextKey string
 
 const (
 	UserIDKey contextKey = "userID"
+	ClaimsKey contextKey = "claims"
 )
 
 // GetUserIDFromContext extracts userID from context
@@ -24,84 +26,89 @@ func GetUserIDFromContext(ctx context.Context) (string, error) {
 	return userID, nil
 }
 
-// HasAdminRole checks if the token has an admin role
-// In a real implementation, this would parse and validate the JWT
-// and check for admin roles in the claims
-func HasAdminRole(token string) (bool, error) {
-	// TODO: Implement proper JWT validation and role checking
-	// For now, we'll just check if the token contains "admin" as a simple simulation
-	// This is NOT secure and should be replaced with proper JWT validation
-	return strings.Contains(strings.ToLower(token), "admin"), nil
+// GetClaimsFromContext extracts the verified token claims from context
+func GetClaimsFromContext(ctx context.Context) (jwt.MapClaims, error) {
+	claims, ok := ctx.Value(ClaimsKey).(jwt.MapClaims)
+	if !ok || claims == nil {
+		return nil, errors.New("token claims not found in context")
+	}
+	return claims, nil
 }
 
-// AuthMiddleware extracts user ID from the auth token and puts it in the request context
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip authentication for OPTIONS requests (for CORS preflight)
-		if r.Method == http.MethodOptions {
-			next.ServeHTTP(w, r)
-			return
-		}
-
-		// Extract token from request
-		token, err := ExtractTokenFromRequest(r)
-		if err != nil {
-			log.Printf("Error extracting token: %v", err)
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		// Extract user ID from token
-		userID, err := ExtractUserIDFromJWT(token)
-		if err != nil {
-			log.Printf("Error extracting user ID from JWT: %v", err)
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
-
-		log.Printf("User authenticated with ID: %s", userID)
-
-		// Add user ID to request context
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-
-		// Call the next handler with the updated context
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// AuthMiddleware verifies the bearer token against the realm JWKS and puts the
+// user ID and claims in the request context
+func AuthMiddleware(verifier *TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip authentication for OPTIONS requests (for CORS preflight)
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Extract token from request
+			token, err := ExtractTokenFromRequest(r)
+			if err != nil {
+				log.Printf("Error extracting token: %v", err)
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
+
+			// Verify signature and standard claims
+			claims, err := verifier.VerifyToken(token)
+			if err != nil {
+				log.Printf("Error verifying JWT: %v", err)
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			userID, err := UserIDFromClaims(claims)
+			if err != nil {
+				log.Printf("Error extracting user ID from JWT: %v", err)
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			log.Printf("User authenticated with ID: %s", userID)
+
+			// Add user ID and claims to request context
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, ClaimsKey, claims)
+
+			// Call the next handler with the updated context
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
-// AdminMiddleware checks if the user has admin role
-func AdminMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip authentication for OPTIONS requests (for CORS preflight)
-		if r.Method == http.MethodOptions {
+// AdminMiddleware checks that the verified token grants one of the given roles.
+// It must run after AuthMiddleware, which puts the claims in the request context.
+func AdminMiddleware(authorizer *RoleAuthorizer, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip authentication for OPTIONS requests (for CORS preflight)
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := GetClaimsFromContext(r.Context())
+			if err != nil {
+				log.Printf("Error getting claims from context: %v", err)
+				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+				return
+			}
+
+			if !authorizer.HasAnyRole(claims, roles...) {
+				log.Printf("User %v lacks required roles %v", claims["sub"], roles)
+				http.Error(w, "Forbidden - Admin access required", http.StatusForbidden)
+				return
+			}
+
+			// Call the next handler
 			next.ServeHTTP(w, r)
-			return
-		}
-
-		// Extract token from request
-		token, err := ExtractTokenFromRequest(r)
-		if err != nil {
-			log.Printf("Error extracting token: %v", err)
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		// Check if user has admin role
-		isAdmin, err := HasAdminRole(token)
-		if err != nil {
-			log.Printf("Error checking admin role: %v", err)
-			http.Error(w, "Failed to validate authorization", http.StatusInternalServerError)
-			return
-		}
-
-		if !isAdmin {
-			http.Error(w, "Forbidden - Admin access required", http.StatusForbidden)
-			return
-		}
-
-		// Call the next handler
-		next.ServeHTTP(w, r)
-	})
+		})
+	}
 }
 
 // extractSimulatedUserID extracts a user ID from a token for simulation
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwk represents a single JSON Web Key as published by Keycloak
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// JWKSCache fetches and caches the signing keys of a Keycloak realm.
+// Keys are refreshed periodically and whenever a token references an unknown kid,
+// which is how Keycloak key rotation is picked up.
+type JWKSCache struct {
+	url        string
+	httpClient *http.Client
+	ttl        time.Duration
+	// minRefreshInterval limits how often an unknown kid can trigger a refetch
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	fetching    chan struct{} // closed when the fetch in progress finishes, nil when there is none
+}
+
+// NewJWKSCache creates a cache for the JWKS published at the given URL
+func NewJWKSCache(jwksURL string, httpClient *http.Client, ttl time.Duration) *JWKSCache {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKSCache{
+		url:                jwksURL,
+		httpClient:         httpClient,
+		ttl:                ttl,
+		minRefreshInterval: 10 * time.Second,
+		keys:               make(map[string]interface{}),
+	}
+}
+
+// RealmJWKSURL builds the JWKS (certs) endpoint for a Keycloak realm
+func RealmJWKSURL(keycloakURL, realm string) string {
+	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", keycloakURL, realm)
+}
+
+// errRefreshRateLimited is returned when the key set was fetched less than minRefreshInterval ago
+var errRefreshRateLimited = errors.New("JWKS refresh rate limited")
+
+// GetKey returns the public key for the given kid, refreshing the key set if needed
+func (c *JWKSCache) GetKey(kid string) (interface{}, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	expired := c.expired()
+	c.mu.RUnlock()
+
+	if ok && !expired {
+		return key, nil
+	}
+
+	// Unknown kid (rotation) or stale cache - try to refresh
+	if err := c.refresh(kid); err != nil {
+		// Keep serving a known key if the refresh failed on expiry only
+		if ok {
+			if !errors.Is(err, errRefreshRateLimited) {
+				log.Printf("Warning: failed to refresh JWKS, using cached key %s: %v", kid, err)
+			}
+			return key, nil
+		}
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found in JWKS", kid)
+	}
+	return key, nil
+}
+
+// expired reports whether the cached key set is older than the TTL; the caller holds the lock
+func (c *JWKSCache) expired() bool {
+	return c.ttl > 0 && time.Since(c.fetchedAt) > c.ttl
+}
+
+// refresh re-downloads the key set unless another request already did while this one waited.
+// Refreshes are throttled to one per minRefreshInterval, whether they were triggered by an expired
+// cache or an unknown kid, so tokens with bogus kids cannot hammer Keycloak. The key set is fetched
+// without holding the lock, so lookups of cached keys are not blocked by a slow Keycloak; requests
+// that need a refresh while one is in progress wait for it instead.
+func (c *JWKSCache) refresh(kid string) error {
+	c.mu.Lock()
+	for c.fetching != nil {
+		fetching := c.fetching
+		c.mu.Unlock()
+		<-fetching
+		c.mu.Lock()
+	}
+
+	if _, ok := c.keys[kid]; ok && !c.expired() {
+		c.mu.Unlock()
+		return nil
+	}
+	if !c.lastAttempt.IsZero() && time.Since(c.lastAttempt) < c.minRefreshInterval {
+		c.mu.Unlock()
+		return errRefreshRateLimited
+	}
+	c.lastAttempt = time.Now()
+	fetching := make(chan struct{})
+	c.fetching = fetching
+	c.mu.Unlock()
+
+	keys, err := c.fetch()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetching = nil
+	close(fetching)
+	if err != nil {
+		return err
+	}
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	log.Printf("Loaded %d signing keys from JWKS", len(keys))
+	return nil
+}
+
+// fetch downloads the key set and parses its signing keys
+func (c *JWKSCache) fetch() (map[string]interface{}, error) {
+	log.Printf("Fetching JWKS from %s", c.url)
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		// Skip encryption keys, we only verify signatures
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			log.Printf("Skipping JWKS key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+// publicKey converts the JWK into an *rsa.PublicKey or *ecdsa.PublicKey
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "http://keycloak.test/realms/event-ticketing"
+
+// jwksStub serves a mutable JWKS so tests can simulate key rotation
+type jwksStub struct {
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	requests int
+}
+
+func newJWKSStub() *jwksStub {
+	return &jwksStub{keys: make(map[string]*rsa.PrivateKey)}
+}
+
+func (s *jwksStub) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.mu.Lock()
+	s.keys[kid] = key
+	s.mu.Unlock()
+	return key
+}
+
+func (s *jwksStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	set := jwkSet{}
+	for kid, key := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kid: kid,
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(set)
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "user-123",
+		"iss": testIssuer,
+		"aud": "account",
+		"exp": time.Now().Add(5 * time.Minute).Unix(),
+		"nbf": time.Now().Add(-time.Minute).Unix(),
+	}
+}
+
+func newTestVerifier(url string) *TokenVerifier {
+	cache := NewJWKSCache(url, nil, time.Hour)
+	cache.minRefreshInterval = 0
+	return NewTokenVerifierWithJWKS(cache, testIssuer, "account")
+}
+
+func TestVerifyToken(t *testing.T) {
+	stub := newJWKSStub()
+	key := stub.addKey(t, "kid-1")
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	verifier := newTestVerifier(server.URL)
+
+	t.Run("valid token", func(t *testing.T) {
+		userID, err := verifier.ExtractUserIDFromJWT(signToken(t, key, "kid-1", validClaims()))
+		assert.NoError(t, err)
+		assert.Equal(t, "user-123", userID)
+	})
+
+	t.Run("forged signature", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(signToken(t, other, "kid-1", validClaims()))
+		assert.Error(t, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		_, err := verifier.VerifyToken(signToken(t, key, "kid-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("not yet valid", func(t *testing.T) {
+		claims := validClaims()
+		claims["nbf"] = time.Now().Add(time.Hour).Unix()
+		_, err := verifier.VerifyToken(signToken(t, key, "kid-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenNotValidYet)
+	})
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		claims := validClaims()
+		claims["iss"] = "http://evil.test/realms/event-ticketing"
+		_, err := verifier.VerifyToken(signToken(t, key, "kid-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidIssuer)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := validClaims()
+		claims["aud"] = "other-client"
+		_, err := verifier.VerifyToken(signToken(t, key, "kid-1", claims))
+		assert.ErrorIs(t, err, jwt.ErrTokenInvalidAudience)
+	})
+
+	t.Run("unsigned token", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+		signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(signed)
+		assert.Error(t, err)
+	})
+}
+
+func TestVerifyTokenKeyRotation(t *testing.T) {
+	stub := newJWKSStub()
+	oldKey := stub.addKey(t, "kid-old")
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	verifier := newTestVerifier(server.URL)
+
+	_, err := verifier.VerifyToken(signToken(t, oldKey, "kid-old", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stub.requests)
+
+	// Keycloak rotates to a new key; the unknown kid must trigger a refetch
+	newKey := stub.addKey(t, "kid-new")
+	_, err = verifier.VerifyToken(signToken(t, newKey, "kid-new", validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stub.requests)
+
+	// Cached keys do not hit the JWKS endpoint again
+	_, err = verifier.VerifyToken(signToken(t, oldKey, "kid-old", validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stub.requests)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	stub := newJWKSStub()
+	key := stub.addKey(t, "kid-1")
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	var gotUserID string
+	handler := AuthMiddleware(newTestVerifier(server.URL))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID, _ = GetUserIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, key, "kid-1", validClaims()))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "user-123", gotUserID)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims())
+	forgedToken, err := forged.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+forgedToken)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestGetKeyRateLimitsExpiredRefreshesAndServesStaleKeys(t *testing.T) {
+	stub := newJWKSStub()
+	stub.addKey(t, "kid-1")
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	// Every lookup finds the cache expired
+	cache := NewJWKSCache(server.URL, nil, time.Nanosecond)
+
+	_, err := cache.GetKey("kid-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stub.requests)
+
+	for i := 0; i < 5; i++ {
+		key, err := cache.GetKey("kid-1")
+		require.NoError(t, err)
+		assert.NotNil(t, key)
+	}
+	assert.Equal(t, 1, stub.requests)
+}
+
+func TestGetKeyServesCachedKeysWhileARefreshIsInProgress(t *testing.T) {
+	stub := newJWKSStub()
+	stub.addKey(t, "kid-1")
+	release := make(chan struct{})
+	var blocking sync.Once
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stub.requests > 0 {
+			blocking.Do(func() { close(blocked) })
+			<-release
+		}
+		stub.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cache := NewJWKSCache(server.URL, nil, time.Hour)
+	cache.minRefreshInterval = 0
+	_, err := cache.GetKey("kid-1")
+	require.NoError(t, err)
+
+	// A token signed with a rotated key triggers a refresh that hangs in Keycloak
+	stub.addKey(t, "kid-2")
+	rotated := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := cache.GetKey("kid-2")
+			rotated <- err
+		}()
+	}
+	<-blocked
+
+	cached := make(chan error, 1)
+	go func() {
+		_, err := cache.GetKey("kid-1")
+		cached <- err
+	}()
+	select {
+	case err := <-cached:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Error("lookup of a cached key waited for the refresh")
+	}
+
+	close(release)
+	require.NoError(t, <-rotated)
+	require.NoError(t, <-rotated)
+	assert.Equal(t, 2, stub.requests, "the waiting lookup reuses the refresh in progress")
+}
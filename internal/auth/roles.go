@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RoleAuthorizer checks Keycloak roles carried in verified token claims.
+//
+// Role names are matched against realm roles (realm_access.roles) and the roles
+// of the configured client (resource_access.<clientID>.roles). A role written as
+// "client:role" only matches that role on the named client.
+type RoleAuthorizer struct {
+	clientID string
+}
+
+// NewRoleAuthorizer creates an authorizer that reads client roles for clientID
+func NewRoleAuthorizer(clientID string) *RoleAuthorizer {
+	return &RoleAuthorizer{clientID: clientID}
+}
+
+// HasAnyRole reports whether the claims grant at least one of the given roles
+func (a *RoleAuthorizer) HasAnyRole(claims jwt.MapClaims, roles ...string) bool {
+	if claims == nil {
+		return false
+	}
+
+	realmRoles := RealmRoles(claims)
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			continue
+		}
+
+		// Explicit client role, e.g. "event-service:admin"
+		if client, name, ok := strings.Cut(role, ":"); ok {
+			if containsRole(ClientRoles(claims, client), name) {
+				return true
+			}
+			continue
+		}
+
+		if containsRole(realmRoles, role) {
+			return true
+		}
+		if a.clientID != "" && containsRole(ClientRoles(claims, a.clientID), role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealmRoles returns the roles in the realm_access claim
+func RealmRoles(claims jwt.MapClaims) []string {
+	realmAccess, ok := claims["realm_access"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return toStringSlice(realmAccess["roles"])
+}
+
+// ClientRoles returns the roles granted for a client in the resource_access claim
+func ClientRoles(claims jwt.MapClaims, clientID string) []string {
+	resourceAccess, ok := claims["resource_access"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	client, ok := resourceAccess[clientID].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return toStringSlice(client["roles"])
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func toStringSlice(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
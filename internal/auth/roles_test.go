@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func roleClaims() jwt.MapClaims {
+	// Shape matches claims after JSON decoding, as produced by the verifier
+	return jwt.MapClaims{
+		"sub": "user-123",
+		"realm_access": map[string]interface{}{
+			"roles": []interface{}{"offline_access", "event-admin"},
+		},
+		"resource_access": map[string]interface{}{
+			"scheduler-service-client": map[string]interface{}{
+				"roles": []interface{}{"subscriber-viewer"},
+			},
+			"event-service": map[string]interface{}{
+				"roles": []interface{}{"organizer"},
+			},
+		},
+	}
+}
+
+func TestRoleAuthorizerHasAnyRole(t *testing.T) {
+	authorizer := NewRoleAuthorizer("scheduler-service-client")
+	claims := roleClaims()
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  bool
+	}{
+		{"realm role", []string{"event-admin"}, true},
+		{"configured client role", []string{"subscriber-viewer"}, true},
+		{"explicit client role", []string{"event-service:organizer"}, true},
+		{"role on a different client", []string{"organizer"}, false},
+		{"explicit client without role", []string{"event-service:event-admin"}, false},
+		{"any of several", []string{"admin", "event-admin"}, true},
+		{"missing role", []string{"admin"}, false},
+		{"no roles required", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, authorizer.HasAnyRole(claims, tt.roles...))
+		})
+	}
+
+	assert.False(t, authorizer.HasAnyRole(jwt.MapClaims{"sub": "user-123"}, "admin"))
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	stub := newJWKSStub()
+	key := stub.addKey(t, "kid-1")
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	handler := AuthMiddleware(newTestVerifier(server.URL))(
+		AdminMiddleware(NewRoleAuthorizer("scheduler-service-client"), "event-admin")(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
+
+	serve := func(claims jwt.MapClaims) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, key, "kid-1", claims))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	admin := validClaims()
+	for k, v := range roleClaims() {
+		if k != "sub" {
+			admin[k] = v
+		}
+	}
+	require.Equal(t, http.StatusOK, serve(admin))
+
+	// A token that merely mentions "admin" somewhere is not enough anymore
+	user := validClaims()
+	user["preferred_username"] = "admin"
+	assert.Equal(t, http.StatusForbidden, serve(user))
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -151,31 +152,83 @@ func ExtractTokenFromRequest(r *http.Request) (string, error) {
 	return parts[1], nil
 }
 
-// ExtractUserIDFromJWT extracts the user ID from a JWT token
-// This function parses the JWT and extracts the 'sub' claim which contains the user ID
-func ExtractUserIDFromJWT(tokenString string) (string, error) {
+// TokenVerifier validates access tokens issued by the Keycloak realm.
+// It checks the signature against the realm JWKS as well as exp, nbf, iss and aud.
+type TokenVerifier struct {
+	jwks     *JWKSCache
+	issuer   string
+	audience string
+	parser   *jwt.Parser
+}
+
+// NewTokenVerifier creates a verifier for the realm configured in cfg
+func NewTokenVerifier(cfg config.Config, client *http.Client) *TokenVerifier {
+	jwks := NewJWKSCache(RealmJWKSURL(cfg.KeycloakURL, cfg.KeycloakRealm), client, time.Hour)
+
+	issuer := cfg.KeycloakIssuer
+	if issuer == "" {
+		issuer = fmt.Sprintf("%s/realms/%s", cfg.KeycloakURL, cfg.KeycloakRealm)
+	}
+
+	return NewTokenVerifierWithJWKS(jwks, issuer, cfg.KeycloakAudience)
+}
+
+// NewTokenVerifierWithJWKS creates a verifier using an existing key cache.
+// An empty audience disables the aud check.
+func NewTokenVerifierWithJWKS(jwks *JWKSCache, issuer, audience string) *TokenVerifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512"}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	return &TokenVerifier{
+		jwks:     jwks,
+		issuer:   issuer,
+		audience: audience,
+		parser:   jwt.NewParser(opts...),
+	}
+}
+
+// VerifyToken parses and validates the token, returning its claims
+func (v *TokenVerifier) VerifyToken(tokenString string) (jwt.MapClaims, error) {
 	if tokenString == "" {
-		return "", errors.New("empty token")
+		return nil, errors.New("empty token")
 	}
 
-	// Parse the JWT without validating the signature
-	// In a production environment, you should validate the signature
-	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, errors.New("token header has no kid")
+		}
+		return v.jwks.GetKey(kid)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("failed to verify token: %w", err)
 	}
 
-	// Extract claims from token
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", errors.New("invalid token claims")
+	return claims, nil
+}
+
+// ExtractUserIDFromJWT verifies the token and extracts the user ID from its 'sub' claim
+func (v *TokenVerifier) ExtractUserIDFromJWT(tokenString string) (string, error) {
+	claims, err := v.VerifyToken(tokenString)
+	if err != nil {
+		return "", err
 	}
+	return UserIDFromClaims(claims)
+}
 
-	// Extract the subject claim which contains the user ID
+// UserIDFromClaims extracts the subject claim which contains the user ID
+func UserIDFromClaims(claims jwt.MapClaims) (string, error) {
 	sub, ok := claims["sub"].(string)
 	if !ok || sub == "" {
 		return "", errors.New("subject claim not found in token")
 	}
-
 	return sub, nil
 }
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"ms-scheduling/internal/models"
+)
+
+// unsubscribeIssuer is set on every unsubscribe token so they can't be confused with other HS256 tokens
+const unsubscribeIssuer = "ms-scheduling/unsubscribe"
+
+// UnsubscribeClaims identifies the subscription an unsubscribe link removes
+type UnsubscribeClaims struct {
+	SubscriberID int                         `json:"sid"`
+	Category     models.SubscriptionCategory `json:"cat"`
+	TargetUUID   string                      `json:"tgt"`
+	jwt.RegisteredClaims
+}
+
+// UnsubscribeSigner issues and verifies HMAC-signed, expiring unsubscribe tokens
+type UnsubscribeSigner struct {
+	secret  []byte
+	ttl     time.Duration
+	baseURL string
+	parser  *jwt.Parser
+}
+
+// NewUnsubscribeSigner creates a signer; links point to baseURL, the public URL of the unsubscribe endpoint
+func NewUnsubscribeSigner(secret string, ttl time.Duration, baseURL string) *UnsubscribeSigner {
+	return &UnsubscribeSigner{
+		secret:  []byte(secret),
+		ttl:     ttl,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{"HS256"}),
+			jwt.WithIssuer(unsubscribeIssuer),
+			jwt.WithExpirationRequired(),
+		),
+	}
+}
+
+// Issue creates a token for removing one subscription
+func (s *UnsubscribeSigner) Issue(subscriberID int, category models.SubscriptionCategory, targetUUID string) (string, error) {
+	now := time.Now()
+	claims := UnsubscribeClaims{
+		SubscriberID: subscriberID,
+		Category:     category,
+		TargetUUID:   targetUUID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    unsubscribeIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("error signing unsubscribe token: %w", err)
+	}
+	return token, nil
+}
+
+// URL creates a signed unsubscribe link for one subscription
+func (s *UnsubscribeSigner) URL(subscriberID int, category models.SubscriptionCategory, targetUUID string) (string, error) {
+	token, err := s.Issue(subscriberID, category, targetUUID)
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + "?token=" + url.QueryEscape(token), nil
+}
+
+// Verify checks the token signature and expiry and returns the subscription it identifies
+func (s *UnsubscribeSigner) Verify(tokenString string) (*UnsubscribeClaims, error) {
+	if tokenString == "" {
+		return nil, errors.New("empty token")
+	}
+
+	claims := &UnsubscribeClaims{}
+	_, err := s.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify unsubscribe token: %w", err)
+	}
+
+	if claims.SubscriberID <= 0 || claims.TargetUUID == "" {
+		return nil, errors.New("unsubscribe token does not identify a subscription")
+	}
+	switch claims.Category {
+	case models.SubscriptionCategoryEvent, models.SubscriptionCategorySession, models.SubscriptionCategoryOrganization, models.SubscriptionCategoryWaitlist:
+	default:
+		return nil, fmt.Errorf("unsubscribe token has unknown category %q", claims.Category)
+	}
+
+	return claims, nil
+}
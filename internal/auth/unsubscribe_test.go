@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ms-scheduling/internal/models"
+)
+
+func TestUnsubscribeTokenRoundTrip(t *testing.T) {
+	signer := NewUnsubscribeSigner("secret", time.Hour, "https://api.ticketly.com/api/scheduler/unsubscribe/v1")
+
+	token, err := signer.Issue(42, models.SubscriptionCategorySession, "session-1")
+	require.NoError(t, err)
+
+	claims, err := signer.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, 42, claims.SubscriberID)
+	assert.Equal(t, models.SubscriptionCategorySession, claims.Category)
+	assert.Equal(t, "session-1", claims.TargetUUID)
+}
+
+func TestUnsubscribeURLCarriesToken(t *testing.T) {
+	signer := NewUnsubscribeSigner("secret", time.Hour, "https://api.ticketly.com/api/scheduler/unsubscribe/v1/")
+
+	link, err := signer.URL(7, models.SubscriptionCategoryEvent, "event-1")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/scheduler/unsubscribe/v1", parsed.Path)
+
+	claims, err := signer.Verify(parsed.Query().Get("token"))
+	require.NoError(t, err)
+	assert.Equal(t, "event-1", claims.TargetUUID)
+}
+
+func TestUnsubscribeTokenRejectsWrongSecret(t *testing.T) {
+	token, err := NewUnsubscribeSigner("secret", time.Hour, "").Issue(42, models.SubscriptionCategorySession, "session-1")
+	require.NoError(t, err)
+
+	_, err = NewUnsubscribeSigner("other-secret", time.Hour, "").Verify(token)
+	assert.Error(t, err)
+}
+
+func TestUnsubscribeTokenRejectsExpiredToken(t *testing.T) {
+	signer := NewUnsubscribeSigner("secret", -time.Minute, "")
+
+	token, err := signer.Issue(42, models.SubscriptionCategorySession, "session-1")
+	require.NoError(t, err)
+
+	_, err = signer.Verify(token)
+	assert.Error(t, err)
+}
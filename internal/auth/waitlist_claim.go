@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// waitlistClaimIssuer is set on every claim token so they can't be confused with other HS256 tokens
+const waitlistClaimIssuer = "ms-scheduling/waitlist-claim"
+
+// WaitlistClaimClaims identifies the waitlist offer a claim link accepts
+type WaitlistClaimClaims struct {
+	OfferID      int    `json:"oid"`
+	SubscriberID int    `json:"sid"`
+	SessionID    string `json:"ses"`
+	jwt.RegisteredClaims
+}
+
+// WaitlistClaimSigner issues and verifies HMAC-signed claim links that expire with their offer
+type WaitlistClaimSigner struct {
+	secret  []byte
+	baseURL string
+	parser  *jwt.Parser
+}
+
+// NewWaitlistClaimSigner creates a signer; links point to baseURL, the public URL of the claim endpoint
+func NewWaitlistClaimSigner(secret string, baseURL string) *WaitlistClaimSigner {
+	return &WaitlistClaimSigner{
+		secret:  []byte(secret),
+		baseURL: strings.TrimRight(baseURL, "/"),
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{"HS256"}),
+			jwt.WithIssuer(waitlistClaimIssuer),
+			jwt.WithExpirationRequired(),
+		),
+	}
+}
+
+// Issue creates a token for claiming one offer, valid until the offer expires
+func (s *WaitlistClaimSigner) Issue(offerID, subscriberID int, sessionID string, expiresAt time.Time) (string, error) {
+	claims := WaitlistClaimClaims{
+		OfferID:      offerID,
+		SubscriberID: subscriberID,
+		SessionID:    sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    waitlistClaimIssuer,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("error signing waitlist claim token: %w", err)
+	}
+	return token, nil
+}
+
+// URL creates a signed claim link for one offer
+func (s *WaitlistClaimSigner) URL(offerID, subscriberID int, sessionID string, expiresAt time.Time) (string, error) {
+	token, err := s.Issue(offerID, subscriberID, sessionID, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + "?token=" + url.QueryEscape(token), nil
+}
+
+// Verify checks the token signature and expiry and returns the offer it identifies
+func (s *WaitlistClaimSigner) Verify(tokenString string) (*WaitlistClaimClaims, error) {
+	if tokenString == "" {
+		return nil, errors.New("empty token")
+	}
+
+	claims := &WaitlistClaimClaims{}
+	_, err := s.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify waitlist claim token: %w", err)
+	}
+
+	if claims.OfferID <= 0 || claims.SubscriberID <= 0 || claims.SessionID == "" {
+		return nil, errors.New("waitlist claim token does not identify an offer")
+	}
+	return claims, nil
+}
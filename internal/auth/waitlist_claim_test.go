@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ms-scheduling/internal/models"
+)
+
+func TestWaitlistClaimURLRoundTrip(t *testing.T) {
+	signer := NewWaitlistClaimSigner("secret", "https://api.ticketly.com/api/scheduler/waitlist/v1/claim/")
+
+	link, err := signer.URL(12, 42, "session-1", time.Now().Add(30*time.Minute))
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/scheduler/waitlist/v1/claim", parsed.Path)
+
+	claims, err := signer.Verify(parsed.Query().Get("token"))
+	require.NoError(t, err)
+	assert.Equal(t, 12, claims.OfferID)
+	assert.Equal(t, 42, claims.SubscriberID)
+	assert.Equal(t, "session-1", claims.SessionID)
+}
+
+func TestWaitlistClaimTokenExpiresWithOffer(t *testing.T) {
+	signer := NewWaitlistClaimSigner("secret", "")
+
+	token, err := signer.Issue(12, 42, "session-1", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	_, err = signer.Verify(token)
+	assert.Error(t, err)
+}
+
+func TestWaitlistClaimRejectsUnsubscribeToken(t *testing.T) {
+	token, err := NewUnsubscribeSigner("secret", time.Hour, "").Issue(42, models.SubscriptionCategoryWaitlist, "session-1")
+	require.NoError(t, err)
+
+	_, err = NewWaitlistClaimSigner("secret", "").Verify(token)
+	assert.Error(t, err)
+}
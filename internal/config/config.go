@@ -1,32 +1,50 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds the application configuration
 type Config struct {
-	AWSRegion                    string
-	AWSEndpoint                  string
-	AWSAccessKeyID               string
-	AWSSecretAccessKey           string
-	EventServiceURL              string
-	EventQueryServiceURL         string
-	KeycloakURL                  string
-	KeycloakRealm                string
-	ClientID                     string
-	ClientSecret                 string
-	KafkaURL                     string
-	EventSessionsKafkaTopic      string
-	OrdersKafkaTopic             string
-	OrdersUpdatedKafkaTopic      string
-	OrdersCancelledKafkaTopic    string
-	EventsKafkaTopic             string
+	AWSRegion                 string
+	AWSEndpoint               string
+	AWSAccessKeyID            string
+	AWSSecretAccessKey        string
+	EventServiceURL           string
+	EventQueryServiceURL      string
+	KeycloakURL               string
+	KeycloakRealm             string
+	KeycloakIssuer            string
+	KeycloakAudience          string
+	ClientID                  string
+	ClientSecret              string
+	KafkaURL                  string
+	EventSessionsKafkaTopic   string
+	OrdersKafkaTopic          string
+	OrdersUpdatedKafkaTopic   string
+	OrdersCancelledKafkaTopic string
+	EventsKafkaTopic          string
+	PaymentSuccessKafkaTopic  string
+	PaymentFailedKafkaTopic   string
+	PaymentRefundedKafkaTopic string
+
+	// Kafka consumer retry configuration
+	KafkaRetryInitialBackoff  time.Duration
+	KafkaRetryMaxBackoff      time.Duration
+	KafkaSessionRetryAttempts int
+	KafkaEventRetryAttempts   int
+	KafkaOrderRetryAttempts   int
+	KafkaPaymentRetryAttempts int
+	KafkaDLQSuffix            string
+
 	FrontendURL                  string
 	SQSSessionSchedulingQueueURL string
 	SQSSessionSchedulingQueueARN string
@@ -34,9 +52,52 @@ type Config struct {
 	SQSSessionRemindersQueueARN  string
 	SQSTrendingQueueURL          string
 	SQSTrendingQueueARN          string
+	SQSSchedulingWorkers         int
+	SQSRemindersWorkers          int
+	SQSTrendingWorkers           int
+	SQSVisibilityTimeout         time.Duration
+	SQSRetryInitialBackoff       time.Duration
+	SQSRetryMaxBackoff           time.Duration
+	SQSSchedulingMaxReceives     int
+	SQSSchedulingDLQURL          string
 	SchedulerRoleARN             string
 	SchedulerGroupName           string
 
+	// Scheduler backend configuration ("eventbridge" or "postgres")
+	SchedulerBackend             string
+	SchedulerPollInterval        time.Duration
+	SchedulerBatchSize           int
+	SchedulerMaxAttempts         int
+	SchedulerRetryInitialBackoff time.Duration
+	SchedulerRetryMaxBackoff     time.Duration
+
+	// Queue backend configuration ("sqs", "postgres" or "memory")
+	QueueBackend      string
+	QueuePollInterval time.Duration
+
+	// Schedule reconciliation configuration
+	ScheduleReconcileInterval time.Duration
+	ScheduleReconcileDryRun   bool
+	ScheduleReconcilePageSize int
+
+	// Handling of jobs whose fire time has passed when they are scheduled ("skip", "fire" or "clamp")
+	SchedulePastDuePolicy string
+	ReminderPastDuePolicy string
+	PastDueClampDelay     time.Duration
+
+	// Default reminder rules as "name:anchor:offset:TYPE[:template]", overridable per organization
+	ReminderRules []string
+	// Delay after a session ends before ticket holders get the follow-up email, 0 disables it
+	FollowUpDelay time.Duration
+	// Keycloak groups of the organizers told about event reviews; {organizationId} is replaced
+	OrganizerGroupPaths []string
+
+	// Authorization configuration (Keycloak realm roles, or "client:role" for client roles)
+	AdminRoles                   []string
+	EventSubscribersRoles        []string
+	SessionSubscribersRoles      []string
+	OrganizationSubscribersRoles []string
+
 	// Database configuration
 	PostgresDSN string
 
@@ -48,10 +109,39 @@ type Config struct {
 	FromEmail    string
 	FromName     string
 
+	// Email outbox configuration
+	EmailOutboxMaxAttempts  int
+	EmailOutboxBatchSize    int
+	EmailOutboxPollInterval time.Duration
+	EmailOutboxBaseBackoff  time.Duration
+	EmailOutboxMaxBackoff   time.Duration
+
+	// Notification channel configuration
+	NotifyDefaultChannels []string
+	NotifyWebhookURL      string
+
+	// Unsubscribe link configuration
+	UnsubscribeSecret   string
+	UnsubscribeTokenTTL time.Duration
+	UnsubscribeBaseURL  string
+
+	// Waitlist configuration
+	WaitlistClaimSecret    string
+	WaitlistClaimBaseURL   string
+	WaitlistClaimTTL       time.Duration
+	WaitlistNotifyBatch    int
+	WaitlistNotifyInterval time.Duration
+	WaitlistReopenSeats    int
+
 	// HTTP server configuration
 	ServerHost string
 	ServerPort string
 
+	// Shutdown and worker supervision configuration
+	ShutdownTimeout             time.Duration
+	WorkerRestartInitialBackoff time.Duration
+	WorkerRestartMaxBackoff     time.Duration
+
 	// CORS configuration
 	AllowedOrigins []string
 	AllowedMethods []string
@@ -106,6 +196,9 @@ func Load() Config {
 	// Set default max age to 3600 seconds
 	maxAge := 3600
 
+	// Roles allowed to use admin endpoints; per-route lists default to these
+	adminRoles := getEnvList("ADMIN_ROLES", []string{"admin"})
+
 	return Config{
 		AWSRegion:                    getEnv("AWS_REGION", "ap-south-1"),
 		AWSEndpoint:                  getEnv("AWS_LOCAL_ENDPOINT_URL", ""),
@@ -115,6 +208,8 @@ func Load() Config {
 		EventQueryServiceURL:         getEnv("EVENT_QUERY_SERVICE_URL", "http://localhost:8082/api/event-query"),
 		KeycloakURL:                  getEnv("KEYCLOAK_URL", "http://auth.ticketly.com:8080"),
 		KeycloakRealm:                getEnv("KEYCLOAK_REALM", "event-ticketing"),
+		KeycloakIssuer:               getEnv("KEYCLOAK_ISSUER", ""),
+		KeycloakAudience:             getEnv("KEYCLOAK_AUDIENCE", "account"),
 		ClientID:                     getEnv("KEYCLOAK_CLIENT_ID", "scheduler-service-client"),
 		ClientSecret:                 getEnv("SCHEDULER_CLIENT_SECRET", ""),
 		KafkaURL:                     getEnv("KAFKA_URL", "localhost:9092"),
@@ -124,13 +219,52 @@ func Load() Config {
 		SQSSessionRemindersQueueARN:  getEnv("AWS_SQS_SESSION_REMINDERS_ARN", ""),
 		SQSTrendingQueueURL:          getEnv("AWS_SQS_TRENDING_JOB_URL", ""),
 		SQSTrendingQueueARN:          getEnv("AWS_SQS_TRENDING_JOB_ARN", ""),
+		SQSSchedulingWorkers:         getEnvInt("AWS_SQS_SESSION_SCHEDULING_WORKERS", 4),
+		SQSRemindersWorkers:          getEnvInt("AWS_SQS_SESSION_REMINDERS_WORKERS", 2),
+		SQSTrendingWorkers:           getEnvInt("AWS_SQS_TRENDING_JOB_WORKERS", 1),
+		SQSVisibilityTimeout:         getEnvDuration("AWS_SQS_VISIBILITY_TIMEOUT", time.Minute),
+		SQSRetryInitialBackoff:       getEnvDuration("AWS_SQS_RETRY_INITIAL_BACKOFF", 30*time.Second),
+		SQSRetryMaxBackoff:           getEnvDuration("AWS_SQS_RETRY_MAX_BACKOFF", 15*time.Minute),
+		SQSSchedulingMaxReceives:     getEnvInt("AWS_SQS_SESSION_SCHEDULING_MAX_RECEIVES", 8),
+		SQSSchedulingDLQURL:          getEnv("AWS_SQS_SESSION_SCHEDULING_DLQ_URL", ""),
 		SchedulerRoleARN:             getEnv("AWS_SCHEDULER_ROLE_ARN", ""),
 		SchedulerGroupName:           getEnv("AWS_SCHEDULER_GROUP_NAME", "default"),
+		SchedulerBackend:             getEnv("SCHEDULER_BACKEND", "eventbridge"),
+		SchedulerPollInterval:        getEnvDuration("SCHEDULER_POLL_INTERVAL", 10*time.Second),
+		SchedulerBatchSize:           getEnvInt("SCHEDULER_BATCH_SIZE", 50),
+		SchedulerMaxAttempts:         getEnvInt("SCHEDULER_MAX_ATTEMPTS", 10),
+		SchedulerRetryInitialBackoff: getEnvDuration("SCHEDULER_RETRY_INITIAL_BACKOFF", 30*time.Second),
+		SchedulerRetryMaxBackoff:     getEnvDuration("SCHEDULER_RETRY_MAX_BACKOFF", 30*time.Minute),
+		QueueBackend:                 getEnv("QUEUE_BACKEND", "sqs"),
+		QueuePollInterval:            getEnvDuration("QUEUE_POLL_INTERVAL", time.Second),
+		ScheduleReconcileInterval:    getEnvDuration("SCHEDULE_RECONCILE_INTERVAL", time.Hour),
+		ScheduleReconcileDryRun:      getEnvBool("SCHEDULE_RECONCILE_DRY_RUN", false),
+		ScheduleReconcilePageSize:    getEnvInt("SCHEDULE_RECONCILE_PAGE_SIZE", 100),
+		SchedulePastDuePolicy:        getEnv("SCHEDULE_PAST_DUE_POLICY", "fire"),
+		ReminderPastDuePolicy:        getEnv("SCHEDULE_REMINDER_PAST_DUE_POLICY", "skip"),
+		PastDueClampDelay:            getEnvDuration("SCHEDULE_PAST_DUE_CLAMP_DELAY", time.Minute),
+		ReminderRules:                getEnvList("REMINDER_RULES", []string{"session-start-reminder:start:-24h:SESSION_START", "sale-start-reminder:sales-start:-30m:SALE_START"}),
+		FollowUpDelay:                getEnvDuration("SESSION_FOLLOW_UP_DELAY", 2*time.Hour),
+		OrganizerGroupPaths:          getEnvList("ORGANIZER_GROUP_PATHS", []string{"/organizations/{organizationId}/owners", "/organizations/{organizationId}/admins"}),
+		AdminRoles:                   adminRoles,
+		EventSubscribersRoles:        getEnvList("EVENT_SUBSCRIBERS_ROLES", adminRoles),
+		SessionSubscribersRoles:      getEnvList("SESSION_SUBSCRIBERS_ROLES", adminRoles),
+		OrganizationSubscribersRoles: getEnvList("ORGANIZATION_SUBSCRIBERS_ROLES", adminRoles),
 		EventSessionsKafkaTopic:      getEnv("EVENT_SESSIONS_KAFKA_TOPIC", "dbz.ticketly.public.event_sessions"),
 		OrdersKafkaTopic:             getEnv("ORDERS_KAFKA_TOPIC", "ticketly.order.created"),
 		OrdersUpdatedKafkaTopic:      getEnv("ORDERS_UPDATED_KAFKA_TOPIC", "ticketly.order.updated"),
 		OrdersCancelledKafkaTopic:    getEnv("ORDERS_CANCELLED_KAFKA_TOPIC", "ticketly.order.cancelled"),
 		EventsKafkaTopic:             getEnv("EVENTS_KAFKA_TOPIC", "dbz.ticketly.public.events"),
+		PaymentSuccessKafkaTopic:     getEnv("PAYMENT_SUCCESS_KAFKA_TOPIC", "ticketly.payment.success"),
+		PaymentFailedKafkaTopic:      getEnv("PAYMENT_FAILED_KAFKA_TOPIC", "ticketly.payment.failed"),
+		PaymentRefundedKafkaTopic:    getEnv("PAYMENT_REFUNDED_KAFKA_TOPIC", "ticketly.payment.refunded"),
+		KafkaRetryInitialBackoff:     getEnvDuration("KAFKA_RETRY_INITIAL_BACKOFF", time.Second),
+		KafkaRetryMaxBackoff:         getEnvDuration("KAFKA_RETRY_MAX_BACKOFF", 30*time.Second),
+		KafkaSessionRetryAttempts:    getEnvInt("KAFKA_SESSION_RETRY_ATTEMPTS", 5),
+		KafkaEventRetryAttempts:      getEnvInt("KAFKA_EVENT_RETRY_ATTEMPTS", 5),
+		KafkaOrderRetryAttempts:      getEnvInt("KAFKA_ORDER_RETRY_ATTEMPTS", 5),
+		KafkaPaymentRetryAttempts:    getEnvInt("KAFKA_PAYMENT_RETRY_ATTEMPTS", 5),
+		KafkaDLQSuffix:               getEnv("KAFKA_DLQ_SUFFIX", ".dlq"),
 		FrontendURL:                  getEnv("FRONTEND_URL", "https://ticketly.dpiyumal.me"),
 
 		// Database configuration
@@ -144,10 +278,39 @@ func Load() Config {
 		FromEmail:    getEnv("FROM_EMAIL", "noreply@ticketly.com"),
 		FromName:     getEnv("FROM_NAME", "Ticketly"),
 
+		// Email outbox configuration
+		EmailOutboxMaxAttempts:  getEnvInt("EMAIL_OUTBOX_MAX_ATTEMPTS", 5),
+		EmailOutboxBatchSize:    getEnvInt("EMAIL_OUTBOX_BATCH_SIZE", 20),
+		EmailOutboxPollInterval: getEnvDuration("EMAIL_OUTBOX_POLL_INTERVAL", 5*time.Second),
+		EmailOutboxBaseBackoff:  getEnvDuration("EMAIL_OUTBOX_BASE_BACKOFF", 30*time.Second),
+		EmailOutboxMaxBackoff:   getEnvDuration("EMAIL_OUTBOX_MAX_BACKOFF", 30*time.Minute),
+
+		// Notification channel configuration
+		NotifyDefaultChannels: getEnvList("NOTIFY_DEFAULT_CHANNELS", []string{"EMAIL"}),
+		NotifyWebhookURL:      getEnv("NOTIFY_WEBHOOK_URL", ""),
+
+		// Unsubscribe link configuration
+		UnsubscribeSecret:   getEnv("UNSUBSCRIBE_SECRET", ""),
+		UnsubscribeTokenTTL: getEnvDuration("UNSUBSCRIBE_TOKEN_TTL", 90*24*time.Hour),
+		UnsubscribeBaseURL:  getEnv("UNSUBSCRIBE_BASE_URL", "http://localhost:8085/api/scheduler/unsubscribe/v1"),
+
+		// Waitlist configuration
+		WaitlistClaimSecret:    getEnv("WAITLIST_CLAIM_SECRET", getEnv("UNSUBSCRIBE_SECRET", "")),
+		WaitlistClaimBaseURL:   getEnv("WAITLIST_CLAIM_BASE_URL", "http://localhost:8085/api/scheduler/waitlist/v1/claim"),
+		WaitlistClaimTTL:       getEnvDuration("WAITLIST_CLAIM_TTL", 30*time.Minute),
+		WaitlistNotifyBatch:    getEnvInt("WAITLIST_NOTIFY_BATCH", 5),
+		WaitlistNotifyInterval: getEnvDuration("WAITLIST_NOTIFY_INTERVAL", time.Minute),
+		WaitlistReopenSeats:    getEnvInt("WAITLIST_REOPEN_SEATS", 10),
+
 		// HTTP server configuration
 		ServerHost: getEnv("SERVER_HOST", "0.0.0.0"),
 		ServerPort: getEnv("SERVER_PORT", "8085"),
 
+		// Shutdown and worker supervision configuration
+		ShutdownTimeout:             getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		WorkerRestartInitialBackoff: getEnvDuration("WORKER_RESTART_INITIAL_BACKOFF", time.Second),
+		WorkerRestartMaxBackoff:     getEnvDuration("WORKER_RESTART_MAX_BACKOFF", time.Minute),
+
 		// CORS configuration
 		AllowedOrigins: allowedOrigins,
 		AllowedMethods: allowedMethods,
@@ -156,11 +319,110 @@ func Load() Config {
 	}
 }
 
+// secretEnvVars are the env vars whose values are never logged
+var secretEnvVars = map[string]bool{
+	"AWS_SECRET_ACCESS_KEY":   true,
+	"SCHEDULER_CLIENT_SECRET": true,
+	"POSTGRES_DSN":            true,
+	"SMTP_PASSWORD":           true,
+	"UNSUBSCRIBE_SECRET":      true,
+	"WAITLIST_CLAIM_SECRET":   true,
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok && value != "" {
-		log.Printf("Loaded env var %s: %s", key, value)
+		log.Printf("Loaded env var %s: %s", key, logValue(key, value))
 		return value
 	}
-	log.Printf("Env var %s not set or empty, using fallback: %s", key, fallback)
+	log.Printf("Env var %s not set or empty, using fallback: %s", key, logValue(key, fallback))
 	return fallback
 }
+
+// logValue returns the value of an env var as it may be logged
+func logValue(key, value string) string {
+	if secretEnvVars[key] {
+		return redact(value)
+	}
+	return value
+}
+
+// redact hides a secret, keeping whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
+// String formats the configuration for logs with its secrets redacted
+func (c Config) String() string {
+	type plainConfig Config // drops this method so formatting does not recurse
+	redacted := plainConfig(c)
+	redacted.AWSSecretAccessKey = redact(c.AWSSecretAccessKey)
+	redacted.ClientSecret = redact(c.ClientSecret)
+	redacted.PostgresDSN = redact(c.PostgresDSN)
+	redacted.SMTPPassword = redact(c.SMTPPassword)
+	redacted.UnsubscribeSecret = redact(c.UnsubscribeSecret)
+	redacted.WaitlistClaimSecret = redact(c.WaitlistClaimSecret)
+	return fmt.Sprintf("%+v", redacted)
+}
+
+// getEnvList reads a comma-separated list, trimming whitespace around each item
+func getEnvList(key string, fallback []string) []string {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return fallback
+	}
+	return items
+}
+
+// getEnvInt reads an integer, falling back when the variable is unset or invalid
+func getEnvInt(key string, fallback int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for env var %s: %s, using fallback: %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvDuration reads a Go duration such as "30s" or "5m", falling back when unset or invalid
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for env var %s: %s, using fallback: %s", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvBool reads a boolean such as "true" or "0", falling back when unset or invalid
+func getEnvBool(key string, fallback bool) bool {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean for env var %s: %s, using fallback: %t", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
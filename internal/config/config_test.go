@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigStringRedactsSecrets(t *testing.T) {
+	cfg := Config{
+		ClientID:            "scheduler-service-client",
+		ClientSecret:        "client-secret",
+		AWSSecretAccessKey:  "aws-secret",
+		PostgresDSN:         "host=db user=postgres password=db-secret",
+		SMTPPassword:        "smtp-secret",
+		UnsubscribeSecret:   "unsubscribe-secret",
+		WaitlistClaimSecret: "waitlist-secret",
+	}
+
+	logged := fmt.Sprintf("Loaded config: %+v", cfg)
+
+	assert.Contains(t, logged, "ClientID:scheduler-service-client")
+	assert.Contains(t, logged, "UnsubscribeSecret:[REDACTED]")
+	for _, secret := range []string{"client-secret", "aws-secret", "db-secret", "smtp-secret", "unsubscribe-secret", "waitlist-secret"} {
+		assert.NotContains(t, logged, secret)
+	}
+}
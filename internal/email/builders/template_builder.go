@@ -125,6 +125,17 @@ func (b *EmailBuilder) AddButton(text, url string) *EmailBuilder {
 	return b
 }
 
+// AddImage adds a full-width image, such as an event cover photo
+func (b *EmailBuilder) AddImage(src, alt string) *EmailBuilder {
+	image := fmt.Sprintf(`
+		<div style="width: 100%%; max-height: 300px; overflow: hidden; border-radius: 8px; margin-bottom: 20px;">
+			<img src="%s" alt="%s" style="width: 100%%; height: auto; display: block;">
+		</div>
+	`, src, alt)
+	b.content = append(b.content, image)
+	return b
+}
+
 // AddDivider adds a horizontal divider
 func (b *EmailBuilder) AddDivider() *EmailBuilder {
 	divider := `<hr style="border: none; border-top: 1px solid #E5E7EB; margin: 30px 0;">`
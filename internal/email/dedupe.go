@@ -0,0 +1,47 @@
+package email
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+)
+
+// Deduplicator remembers which notifications were already sent
+type Deduplicator interface {
+	Claim(fingerprint, sourceKey, recipient, emailType string) (bool, error)
+	Release(fingerprint string) error
+}
+
+// Fingerprint identifies one notification: the source change, the recipient and the email type
+func Fingerprint(sourceKey, recipient, emailType string) string {
+	sum := sha256.Sum256([]byte(sourceKey + "|" + recipient + "|" + emailType))
+	return hex.EncodeToString(sum[:])
+}
+
+// SendOnce calls send unless the notification was already sent for sourceKey.
+// Without a deduplicator or source key the email is always sent. If the dedupe
+// store is unavailable the email is sent anyway, preferring a duplicate over a lost notification.
+func SendOnce(d Deduplicator, sourceKey, recipient, emailType string, send func() error) error {
+	if d == nil || sourceKey == "" {
+		return send()
+	}
+
+	fingerprint := Fingerprint(sourceKey, recipient, emailType)
+	claimed, err := d.Claim(fingerprint, sourceKey, recipient, emailType)
+	if err != nil {
+		log.Printf("[Dedupe] %v, sending %s email to %s without dedupe", err, emailType, recipient)
+		return send()
+	}
+	if !claimed {
+		log.Printf("[Dedupe] Skipping duplicate %s email to %s for %s", emailType, recipient, sourceKey)
+		return nil
+	}
+
+	if err := send(); err != nil {
+		if releaseErr := d.Release(fingerprint); releaseErr != nil {
+			log.Printf("[Dedupe] %v", releaseErr)
+		}
+		return err
+	}
+	return nil
+}
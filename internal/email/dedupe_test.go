@@ -0,0 +1,67 @@
+package email
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryDeduplicator is an in-memory Deduplicator for tests
+type memoryDeduplicator struct {
+	seen map[string]bool
+}
+
+func (d *memoryDeduplicator) Claim(fingerprint, sourceKey, recipient, emailType string) (bool, error) {
+	if d.seen[fingerprint] {
+		return false, nil
+	}
+	d.seen[fingerprint] = true
+	return true, nil
+}
+
+func (d *memoryDeduplicator) Release(fingerprint string) error {
+	delete(d.seen, fingerprint)
+	return nil
+}
+
+func TestSendOnce(t *testing.T) {
+	d := &memoryDeduplicator{seen: make(map[string]bool)}
+	sent := 0
+	send := func() error {
+		sent++
+		return nil
+	}
+
+	source := "public.event_sessions:s-1:u:lsn=42:tx=7"
+	assert.NoError(t, SendOnce(d, source, "a@example.com", "SESSION_UPDATED", send))
+	assert.NoError(t, SendOnce(d, source, "a@example.com", "SESSION_UPDATED", send))
+	assert.Equal(t, 1, sent, "replayed source change must not send twice")
+
+	// Different recipient or email type is a different notification
+	assert.NoError(t, SendOnce(d, source, "b@example.com", "SESSION_UPDATED", send))
+	assert.NoError(t, SendOnce(d, source, "a@example.com", "SESSION_CREATED", send))
+	assert.Equal(t, 3, sent)
+
+	// Without a source key dedupe is disabled
+	assert.NoError(t, SendOnce(d, "", "a@example.com", "SESSION_REMINDER", send))
+	assert.NoError(t, SendOnce(d, "", "a@example.com", "SESSION_REMINDER", send))
+	assert.Equal(t, 5, sent)
+}
+
+func TestSendOnceReleasesOnFailure(t *testing.T) {
+	d := &memoryDeduplicator{seen: make(map[string]bool)}
+	source := "order:o-1:completed"
+
+	err := SendOnce(d, source, "a@example.com", "ORDER_CONFIRMED", func() error {
+		return errors.New("smtp down")
+	})
+	assert.Error(t, err)
+
+	sent := false
+	assert.NoError(t, SendOnce(d, source, "a@example.com", "ORDER_CONFIRMED", func() error {
+		sent = true
+		return nil
+	}))
+	assert.True(t, sent, "a failed send must be retried")
+}
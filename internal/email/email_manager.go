@@ -12,21 +12,53 @@ type EmailSender interface {
 	SendEmail(to, subject, body string) error
 }
 
+// TypedEmailSender is implemented by senders that record the notification type,
+// such as the email outbox
+type TypedEmailSender interface {
+	EmailSender
+	SendTypedEmail(to, emailType, subject, body string) error
+}
+
+// HeaderEmailSender is implemented by senders that can attach extra headers, such as List-Unsubscribe
+type HeaderEmailSender interface {
+	SendEmailWithHeaders(to, emailType, subject, body string, headers map[string]string) error
+}
+
+// UnsubscribeURLPlaceholder marks the unsubscribe link in templates shared by all subscribers.
+// It is replaced with the link of each subscriber when the email is sent.
+const UnsubscribeURLPlaceholder = "{{UNSUBSCRIBE_URL}}"
+
+// UnsubscribeHeaders returns the RFC 8058 one-click unsubscribe headers for an unsubscribe URL
+func UnsubscribeHeaders(unsubscribeURL string) map[string]string {
+	return map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
 // TemplateGenerator is an interface for generating email templates
 type TemplateGenerator interface {
-	GenerateSessionCreatedEmail(session *models.EventSession, eventTitle string) EmailTemplate
-	GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle string) EmailTemplate
-	GenerateSessionCancelledEmail(session *models.EventSession, eventTitle string) EmailTemplate
+	GenerateSessionCreatedEmail(session *models.EventSession, eventTitle, unsubscribeURL string) EmailTemplate
+	GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle, unsubscribeURL string) EmailTemplate
+	GenerateSessionCancelledEmail(session *models.EventSession, eventTitle, unsubscribeURL string) EmailTemplate
 	GenerateSessionReminderEmail(session *models.EventSession, eventTitle string, hoursUntil int) EmailTemplate
-	GenerateEventCreatedEmail(event *models.Event, organizationName string) EmailTemplate
-	GenerateEventUpdatedEmail(before, after *models.Event, organizationName string) EmailTemplate
+	GenerateSessionStartReminderEmail(reminder interface{}) EmailTemplate
+	GenerateSessionSalesReminderEmail(reminder interface{}) EmailTemplate
+	GenerateSessionFollowUpEmail(followUp interface{}) EmailTemplate
+	GenerateEventCreatedEmail(event *models.Event, organizationName, unsubscribeURL string) EmailTemplate
+	GenerateEventUpdatedEmail(before, after *models.Event, organizationName, unsubscribeURL string) EmailTemplate
 	GenerateEventApprovedEmail(event *models.Event, organizationName string) EmailTemplate
 	GenerateEventRejectedEmail(event *models.Event, organizationName string) EmailTemplate
-	GenerateEventCancelledEmail(event *models.Event, organizationName string) EmailTemplate
+	GenerateEventCancelledEmail(event *models.Event, organizationName, unsubscribeURL string) EmailTemplate
 	GenerateOrderConfirmedEmail(order interface{}) EmailTemplate
 	GenerateOrderPendingEmail(order interface{}) EmailTemplate
 	GenerateOrderCancelledEmail(order interface{}) EmailTemplate
 	GenerateOrderUpdatedEmail(order interface{}) EmailTemplate
+	GeneratePaymentSuccessEmail(payment interface{}) EmailTemplate
+	GeneratePaymentFailedEmail(payment interface{}, reason string) EmailTemplate
+	GeneratePaymentPendingEmail(payment interface{}) EmailTemplate
+	GeneratePaymentRefundedEmail(payment interface{}) EmailTemplate
+	GenerateWaitlistSeatAvailableEmail(offer interface{}) EmailTemplate
 }
 
 // EmailManager centralizes all email sending operations
@@ -34,6 +66,8 @@ type EmailManager struct {
 	emailSender       EmailSender
 	config            config.Config
 	templateGenerator TemplateGenerator
+	deduplicator      Deduplicator
+	source            string // source change key used for dedupe, see WithSource
 }
 
 // NewEmailManager creates a new email manager
@@ -45,11 +79,51 @@ func NewEmailManager(emailSender EmailSender, cfg config.Config, templateGen Tem
 	}
 }
 
-// SendEmail sends an email using the provided template
+// SetDeduplicator enables skipping notifications that were already sent
+func (m *EmailManager) SetDeduplicator(deduplicator Deduplicator) {
+	m.deduplicator = deduplicator
+}
+
+// WithSource returns a manager whose emails are deduplicated against the given source change key
+func (m *EmailManager) WithSource(source string) *EmailManager {
+	if m == nil {
+		return nil
+	}
+	clone := *m
+	clone.source = source
+	return &clone
+}
+
+// Templates returns the generator that renders the emails, for callers that render once and send to many
+func (m *EmailManager) Templates() TemplateGenerator {
+	return m.templateGenerator
+}
+
+// SendEmail sends an email using the provided template, skipping it if it was already sent for the source
 func (m *EmailManager) SendEmail(to string, template EmailTemplate) error {
+	return m.SendEmailWithHeaders(to, template, nil)
+}
+
+// SendEmailWithHeaders is SendEmail with extra headers such as List-Unsubscribe.
+// The headers are dropped when the sender cannot attach them.
+func (m *EmailManager) SendEmailWithHeaders(to string, template EmailTemplate, headers map[string]string) error {
+	return SendOnce(m.deduplicator, m.source, to, template.Type.String(), func() error {
+		return m.send(to, template, headers)
+	})
+}
+
+// send hands the email to the underlying sender
+func (m *EmailManager) send(to string, template EmailTemplate, headers map[string]string) error {
 	log.Printf("[EmailManager] Sending %s email to %s", template.Type.String(), to)
 
-	err := m.emailSender.SendEmail(to, template.Subject, template.HTML)
+	var err error
+	if headerSender, ok := m.emailSender.(HeaderEmailSender); ok && len(headers) > 0 {
+		err = headerSender.SendEmailWithHeaders(to, template.Type.String(), template.Subject, template.HTML, headers)
+	} else if typed, ok := m.emailSender.(TypedEmailSender); ok {
+		err = typed.SendTypedEmail(to, template.Type.String(), template.Subject, template.HTML)
+	} else {
+		err = m.emailSender.SendEmail(to, template.Subject, template.HTML)
+	}
 	if err != nil {
 		log.Printf("[EmailManager] Failed to send %s email to %s: %v", template.Type.String(), to, err)
 		return err
@@ -62,17 +136,17 @@ func (m *EmailManager) SendEmail(to string, template EmailTemplate) error {
 // Session Email Methods
 
 func (m *EmailManager) SendSessionCreatedEmail(to string, session *models.EventSession, eventTitle string) error {
-	template := m.templateGenerator.GenerateSessionCreatedEmail(session, eventTitle)
+	template := m.templateGenerator.GenerateSessionCreatedEmail(session, eventTitle, "")
 	return m.SendEmail(to, template)
 }
 
 func (m *EmailManager) SendSessionUpdatedEmail(to string, before, after *models.EventSession, eventTitle string) error {
-	template := m.templateGenerator.GenerateSessionUpdatedEmail(before, after, eventTitle)
+	template := m.templateGenerator.GenerateSessionUpdatedEmail(before, after, eventTitle, "")
 	return m.SendEmail(to, template)
 }
 
 func (m *EmailManager) SendSessionCancelledEmail(to string, session *models.EventSession, eventTitle string) error {
-	template := m.templateGenerator.GenerateSessionCancelledEmail(session, eventTitle)
+	template := m.templateGenerator.GenerateSessionCancelledEmail(session, eventTitle, "")
 	return m.SendEmail(to, template)
 }
 
@@ -84,12 +158,12 @@ func (m *EmailManager) SendSessionReminderEmail(to string, session *models.Event
 // Event Email Methods
 
 func (m *EmailManager) SendEventCreatedEmail(to string, event *models.Event, organizationName string) error {
-	template := m.templateGenerator.GenerateEventCreatedEmail(event, organizationName)
+	template := m.templateGenerator.GenerateEventCreatedEmail(event, organizationName, "")
 	return m.SendEmail(to, template)
 }
 
 func (m *EmailManager) SendEventUpdatedEmail(to string, before, after *models.Event, organizationName string) error {
-	template := m.templateGenerator.GenerateEventUpdatedEmail(before, after, organizationName)
+	template := m.templateGenerator.GenerateEventUpdatedEmail(before, after, organizationName, "")
 	return m.SendEmail(to, template)
 }
 
@@ -104,7 +178,7 @@ func (m *EmailManager) SendEventRejectedEmail(to string, event *models.Event, or
 }
 
 func (m *EmailManager) SendEventCancelledEmail(to string, event *models.Event, organizationName string) error {
-	template := m.templateGenerator.GenerateEventCancelledEmail(event, organizationName)
+	template := m.templateGenerator.GenerateEventCancelledEmail(event, organizationName, "")
 	return m.SendEmail(to, template)
 }
 
@@ -130,6 +204,28 @@ func (m *EmailManager) SendOrderUpdatedEmail(to string, order interface{}) error
 	return m.SendEmail(to, template)
 }
 
+// Payment Email Methods
+
+func (m *EmailManager) SendPaymentSuccessEmail(to string, payment interface{}) error {
+	template := m.templateGenerator.GeneratePaymentSuccessEmail(payment)
+	return m.SendEmail(to, template)
+}
+
+func (m *EmailManager) SendPaymentFailedEmail(to string, payment interface{}, reason string) error {
+	template := m.templateGenerator.GeneratePaymentFailedEmail(payment, reason)
+	return m.SendEmail(to, template)
+}
+
+func (m *EmailManager) SendPaymentPendingEmail(to string, payment interface{}) error {
+	template := m.templateGenerator.GeneratePaymentPendingEmail(payment)
+	return m.SendEmail(to, template)
+}
+
+func (m *EmailManager) SendPaymentRefundedEmail(to string, payment interface{}) error {
+	template := m.templateGenerator.GeneratePaymentRefundedEmail(payment)
+	return m.SendEmail(to, template)
+}
+
 // Batch sending methods for multiple recipients
 
 func (m *EmailManager) SendSessionCreatedEmailBatch(subscribers []models.Subscriber, session *models.EventSession, eventTitle string) {
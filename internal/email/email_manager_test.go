@@ -0,0 +1,57 @@
+package email
+
+import (
+	"testing"
+
+	"ms-scheduling/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSender records how each email was handed over
+type recordingSender struct {
+	calls   []string
+	headers map[string]string
+}
+
+func (s *recordingSender) SendEmail(to, subject, body string) error {
+	s.calls = append(s.calls, "plain")
+	return nil
+}
+
+func (s *recordingSender) SendTypedEmail(to, emailType, subject, body string) error {
+	s.calls = append(s.calls, "typed:"+emailType)
+	return nil
+}
+
+func (s *recordingSender) SendEmailWithHeaders(to, emailType, subject, body string, headers map[string]string) error {
+	s.calls = append(s.calls, "headers:"+emailType)
+	s.headers = headers
+	return nil
+}
+
+func TestSendEmailWithHeadersAttachesHeadersOnlyWhenGiven(t *testing.T) {
+	sender := &recordingSender{}
+	manager := NewEmailManager(sender, config.Config{}, nil)
+	template := EmailTemplate{Type: EmailSessionStartReminder, Subject: "Reminder", HTML: "<p>Hi</p>"}
+
+	require.NoError(t, manager.SendEmail("a@example.com", template))
+	require.NoError(t, manager.SendEmailWithHeaders("a@example.com", template, UnsubscribeHeaders("https://example.com/u")))
+
+	assert.Equal(t, []string{"typed:SESSION_START_REMINDER", "headers:SESSION_START_REMINDER"}, sender.calls)
+	assert.Equal(t, "<https://example.com/u>", sender.headers["List-Unsubscribe"])
+}
+
+func TestSendEmailWithHeadersIsDeduplicatedPerSource(t *testing.T) {
+	sender := &recordingSender{}
+	manager := NewEmailManager(sender, config.Config{}, nil)
+	manager.SetDeduplicator(&memoryDeduplicator{seen: make(map[string]bool)})
+	template := EmailTemplate{Type: EmailSessionFollowUp, Subject: "Thanks", HTML: "<p>Hi</p>"}
+
+	headers := UnsubscribeHeaders("https://example.com/u")
+	require.NoError(t, manager.WithSource("reminder:1").SendEmailWithHeaders("a@example.com", template, headers))
+	require.NoError(t, manager.WithSource("reminder:1").SendEmailWithHeaders("a@example.com", template, headers))
+
+	assert.Len(t, sender.calls, 1)
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // GenerateEventCreatedEmail generates an email for event creation/approval
-func GenerateEventCreatedEmail(event *models.Event, organizationName string) email.EmailTemplate {
+func GenerateEventCreatedEmail(event *models.Event, organizationName, unsubscribeURL string) email.EmailTemplate {
 	builder := builders.NewEmailBuilder("Ticketly", "#10B981")
 
 	builder.SetHeader("🎊 New Event Published!", "An exciting new event is now available")
@@ -42,6 +42,8 @@ func GenerateEventCreatedEmail(event *models.Event, organizationName string) ema
 	builder.AddParagraph("Sessions for this event will be announced soon. You'll receive notifications when they become available.")
 	// builder.AddButton("View Event Details", fmt.Sprintf("https://ticketly.com/events/%s", event.ID))
 
+	setUnsubscribeFooter(builder, unsubscribeURL, "This is an automated notification for an organization you are subscribed to.")
+
 	return email.EmailTemplate{
 		Type:    email.EmailEventCreated,
 		Subject: fmt.Sprintf("🎊 New Event: %s", event.Title),
@@ -50,7 +52,7 @@ func GenerateEventCreatedEmail(event *models.Event, organizationName string) ema
 }
 
 // GenerateEventUpdatedEmail generates an email for event updates
-func GenerateEventUpdatedEmail(before, after *models.Event, organizationName string) email.EmailTemplate {
+func GenerateEventUpdatedEmail(before, after *models.Event, organizationName, unsubscribeURL string) email.EmailTemplate {
 	builder := builders.NewEmailBuilder("Ticketly", "#4F46E5")
 
 	builder.SetHeader("📝 Event Update", "An event you're following has been updated")
@@ -75,6 +77,8 @@ func GenerateEventUpdatedEmail(before, after *models.Event, organizationName str
 	}
 	builder.AddDetailsList(details)
 
+	setUnsubscribeFooter(builder, unsubscribeURL, "This is an automated notification for an event you are subscribed to.")
+
 	return email.EmailTemplate{
 		Type:    email.EmailEventUpdated,
 		Subject: fmt.Sprintf("Event Updated: %s", after.Title),
@@ -150,7 +154,7 @@ func GenerateEventRejectedEmail(event *models.Event, organizationName string) em
 }
 
 // GenerateEventCancelledEmail generates an email when an event is cancelled
-func GenerateEventCancelledEmail(event *models.Event, organizationName string) email.EmailTemplate {
+func GenerateEventCancelledEmail(event *models.Event, organizationName, unsubscribeURL string) email.EmailTemplate {
 	builder := builders.NewEmailBuilder("Ticketly", "#EF4444")
 
 	builder.SetHeader("❌ Event Cancelled", "Important: An event has been cancelled")
@@ -176,6 +180,8 @@ func GenerateEventCancelledEmail(event *models.Event, organizationName string) e
 	builder.AddParagraph("If you have purchased tickets for this event, you will be automatically refunded within 5-7 business days. You will receive a separate confirmation email once the refund is processed.")
 	builder.AddParagraph("For any questions or concerns, please contact our support team.")
 
+	setUnsubscribeFooter(builder, unsubscribeURL, "This is an automated notification for an event you are subscribed to.")
+
 	return email.EmailTemplate{
 		Type:    email.EmailEventCancelled,
 		Subject: fmt.Sprintf("⚠️ Event Cancelled: %s", event.Title),
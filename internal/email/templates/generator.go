@@ -14,29 +14,53 @@ func NewStandardTemplateGenerator() *StandardTemplateGenerator {
 }
 
 // Session templates
-func (g *StandardTemplateGenerator) GenerateSessionCreatedEmail(session *models.EventSession, eventTitle string) email.EmailTemplate {
-	return GenerateSessionCreatedEmail(session, eventTitle)
+func (g *StandardTemplateGenerator) GenerateSessionCreatedEmail(session *models.EventSession, eventTitle, unsubscribeURL string) email.EmailTemplate {
+	return GenerateSessionCreatedEmail(session, eventTitle, unsubscribeURL)
 }
 
-func (g *StandardTemplateGenerator) GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle string) email.EmailTemplate {
-	return GenerateSessionUpdatedEmail(before, after, eventTitle)
+func (g *StandardTemplateGenerator) GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle, unsubscribeURL string) email.EmailTemplate {
+	return GenerateSessionUpdatedEmail(before, after, eventTitle, unsubscribeURL)
 }
 
-func (g *StandardTemplateGenerator) GenerateSessionCancelledEmail(session *models.EventSession, eventTitle string) email.EmailTemplate {
-	return GenerateSessionCancelledEmail(session, eventTitle)
+func (g *StandardTemplateGenerator) GenerateSessionCancelledEmail(session *models.EventSession, eventTitle, unsubscribeURL string) email.EmailTemplate {
+	return GenerateSessionCancelledEmail(session, eventTitle, unsubscribeURL)
 }
 
 func (g *StandardTemplateGenerator) GenerateSessionReminderEmail(session *models.EventSession, eventTitle string, hoursUntil int) email.EmailTemplate {
 	return GenerateSessionReminderEmail(session, eventTitle, hoursUntil)
 }
 
+func (g *StandardTemplateGenerator) GenerateSessionStartReminderEmail(reminder interface{}) email.EmailTemplate {
+	reminderData, ok := reminder.(*SessionReminderData)
+	if !ok {
+		return email.EmailTemplate{}
+	}
+	return GenerateSessionStartReminderEmail(*reminderData)
+}
+
+func (g *StandardTemplateGenerator) GenerateSessionSalesReminderEmail(reminder interface{}) email.EmailTemplate {
+	reminderData, ok := reminder.(*SessionReminderData)
+	if !ok {
+		return email.EmailTemplate{}
+	}
+	return GenerateSessionSalesReminderEmail(*reminderData)
+}
+
+func (g *StandardTemplateGenerator) GenerateSessionFollowUpEmail(followUp interface{}) email.EmailTemplate {
+	followUpData, ok := followUp.(*SessionFollowUpData)
+	if !ok {
+		return email.EmailTemplate{}
+	}
+	return GenerateSessionFollowUpEmail(*followUpData)
+}
+
 // Event templates
-func (g *StandardTemplateGenerator) GenerateEventCreatedEmail(event *models.Event, organizationName string) email.EmailTemplate {
-	return GenerateEventCreatedEmail(event, organizationName)
+func (g *StandardTemplateGenerator) GenerateEventCreatedEmail(event *models.Event, organizationName, unsubscribeURL string) email.EmailTemplate {
+	return GenerateEventCreatedEmail(event, organizationName, unsubscribeURL)
 }
 
-func (g *StandardTemplateGenerator) GenerateEventUpdatedEmail(before, after *models.Event, organizationName string) email.EmailTemplate {
-	return GenerateEventUpdatedEmail(before, after, organizationName)
+func (g *StandardTemplateGenerator) GenerateEventUpdatedEmail(before, after *models.Event, organizationName, unsubscribeURL string) email.EmailTemplate {
+	return GenerateEventUpdatedEmail(before, after, organizationName, unsubscribeURL)
 }
 
 func (g *StandardTemplateGenerator) GenerateEventApprovedEmail(event *models.Event, organizationName string) email.EmailTemplate {
@@ -47,8 +71,8 @@ func (g *StandardTemplateGenerator) GenerateEventRejectedEmail(event *models.Eve
 	return GenerateEventRejectedEmail(event, organizationName)
 }
 
-func (g *StandardTemplateGenerator) GenerateEventCancelledEmail(event *models.Event, organizationName string) email.EmailTemplate {
-	return GenerateEventCancelledEmail(event, organizationName)
+func (g *StandardTemplateGenerator) GenerateEventCancelledEmail(event *models.Event, organizationName, unsubscribeURL string) email.EmailTemplate {
+	return GenerateEventCancelledEmail(event, organizationName, unsubscribeURL)
 }
 
 // Order templates
@@ -116,3 +140,12 @@ func (g *StandardTemplateGenerator) GeneratePaymentRefundedEmail(payment interfa
 	}
 	return GeneratePaymentRefundedEmail(paymentData)
 }
+
+// Waitlist templates
+func (g *StandardTemplateGenerator) GenerateWaitlistSeatAvailableEmail(offer interface{}) email.EmailTemplate {
+	offerData, ok := offer.(*WaitlistOfferData)
+	if !ok {
+		return email.EmailTemplate{}
+	}
+	return GenerateWaitlistSeatAvailableEmail(*offerData)
+}
@@ -0,0 +1,248 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/email/builders"
+)
+
+// SessionReminderData holds what the session start and sales start reminder emails show
+type SessionReminderData struct {
+	SessionID        string
+	EventTitle       string
+	Status           string
+	StartTime        time.Time
+	EndTime          time.Time
+	SalesStartTime   time.Time
+	VenueDetails     string // venue JSON of the session
+	EventOverview    string
+	EventDescription string
+	CoverPhotoURL    string
+	OrganizationName string
+	OrganizationLogo string
+	SessionURL       string
+	CalendarURL      string // .ics download of the session
+	UnsubscribeURL   string
+}
+
+// GenerateSessionStartReminderEmail generates the reminder sent at the offsets of the session start reminder rules
+func GenerateSessionStartReminderEmail(data SessionReminderData) email.EmailTemplate {
+	builder := builders.NewEmailBuilder("Ticketly", "#4F46E5")
+
+	eventTitle := data.EventTitle
+	if eventTitle == "" {
+		eventTitle = "Your Event"
+	}
+
+	// Reminder rules can fire at any offset, so say how far away the session actually is
+	when := timeUntilPhrase(data.StartTime, time.Now())
+
+	builder.SetHeader("🔔 Event Reminder", fmt.Sprintf("%s is happening %s!", eventTitle, when))
+	addEventIntro(builder, data)
+
+	builder.AddParagraph(fmt.Sprintf("This is a friendly reminder about your upcoming event %s.", when))
+
+	details := map[string]string{
+		"📌 Event":     eventTitle,
+		"📆 Date":      data.StartTime.Format("Monday, January 2, 2006"),
+		"🕐 Time":      fmt.Sprintf("%s to %s", data.StartTime.Format("3:04 PM"), data.EndTime.Format("3:04 PM")),
+		"⏱️ Duration": formatDuration(data.EndTime.Sub(data.StartTime)),
+		"✅ Status":    data.Status,
+	}
+	builder.AddDetailsList(details)
+
+	if data.VenueDetails != "" {
+		title, venueHTML := formatVenueLocation(data.VenueDetails)
+		builder.AddSection(title, venueHTML)
+	}
+
+	if data.SessionURL != "" {
+		builder.AddButton("View Event Details", data.SessionURL)
+	}
+
+	googleCalendarURL := fmt.Sprintf("https://calendar.google.com/calendar/render?action=TEMPLATE&text=%s&dates=%s/%s&details=%s&location=%s",
+		url.QueryEscape(eventTitle),
+		data.StartTime.UTC().Format("20060102T150405Z"),
+		data.EndTime.UTC().Format("20060102T150405Z"),
+		url.QueryEscape(eventTitle),
+		url.QueryEscape(venueName(data.VenueDetails)))
+	calendarLinks := fmt.Sprintf(`<p style="text-align: center;"><a href="%s" target="_blank">📅 Google Calendar</a>`, googleCalendarURL)
+	if data.CalendarURL != "" {
+		calendarLinks += fmt.Sprintf(` | <a href="%s" target="_blank">🍎 Apple Calendar</a>`, data.CalendarURL)
+	}
+	builder.AddSection("📱 Add to Calendar", calendarLinks+"</p>")
+
+	builder.AddInfoBox(`<strong>📋 Pre-Event Checklist</strong>
+		<ul style="line-height: 1.8;">
+			<li>Plan your route to the venue</li>
+			<li>Have your tickets ready</li>
+			<li>Check weather conditions</li>
+			<li>Arrive early to find good parking</li>
+		</ul>`, "warning")
+
+	builder.AddParagraph(fmt.Sprintf("We look forward to seeing you %s! 🎉", when))
+	setUnsubscribeFooter(builder, data.UnsubscribeURL, "This is an automated reminder for a session you are subscribed to.")
+
+	return email.EmailTemplate{
+		Type:    email.EmailSessionStartReminder,
+		Subject: fmt.Sprintf("🔔 Reminder: %s is %s!", eventTitle, when),
+		HTML:    builder.Build(),
+	}
+}
+
+// GenerateSessionSalesReminderEmail generates the reminder sent at the offsets of the sales start reminder rules
+func GenerateSessionSalesReminderEmail(data SessionReminderData) email.EmailTemplate {
+	builder := builders.NewEmailBuilder("Ticketly", "#F59E0B")
+
+	eventTitle := data.EventTitle
+	if eventTitle == "" {
+		eventTitle = "Event"
+	}
+
+	builder.SetHeader("🎟️ Tickets Available Soon!", fmt.Sprintf("Tickets for %s will be available %s", eventTitle, timeUntilPhrase(data.SalesStartTime, time.Now())))
+	addEventIntro(builder, data)
+
+	builder.AddParagraph("Don't miss your chance to secure your spot for this event. Tickets will be available for purchase shortly.")
+
+	details := map[string]string{
+		"⏰ Sales Start": data.SalesStartTime.Format("Monday, January 2, 2006 at 3:04 PM"),
+		"📅 Event Date":  data.StartTime.Format("Monday, January 2, 2006 at 3:04 PM"),
+		"📌 Event":       eventTitle,
+	}
+	builder.AddDetailsList(details)
+
+	if data.VenueDetails != "" {
+		title, venueHTML := formatVenueLocation(data.VenueDetails)
+		builder.AddSection(title, venueHTML)
+	}
+
+	if data.SessionURL != "" {
+		builder.AddButton("🎫 Buy Tickets When Available", data.SessionURL)
+	}
+
+	builder.AddInfoBox(`<strong>💡 Tips for Quick Purchase</strong>
+		<ul style="line-height: 1.8;">
+			<li>Sign in to your account before sales begin</li>
+			<li>Have your payment method ready</li>
+			<li>Check that your billing information is up to date</li>
+			<li>Be ready at your computer when sales start</li>
+		</ul>`, "info")
+
+	builder.AddParagraph("Be ready to purchase as soon as tickets are available! ⏱️")
+	setUnsubscribeFooter(builder, data.UnsubscribeURL, "This is an automated notification for a session you are subscribed to.")
+
+	return email.EmailTemplate{
+		Type:    email.EmailSessionSalesReminder,
+		Subject: fmt.Sprintf("🎟️ Tickets for %s will be available soon!", eventTitle),
+		HTML:    builder.Build(),
+	}
+}
+
+// addEventIntro adds the cover photo, organizer and event description shared by the reminder emails
+func addEventIntro(builder *builders.EmailBuilder, data SessionReminderData) {
+	if data.CoverPhotoURL != "" {
+		builder.AddImage(data.CoverPhotoURL, "Event Cover")
+	}
+
+	if data.OrganizationName != "" {
+		organizer := fmt.Sprintf("Organized by <strong>%s</strong>", data.OrganizationName)
+		if data.OrganizationLogo != "" {
+			organizer = fmt.Sprintf(`<img src="%s" alt="%s" style="width: 40px; height: 40px; border-radius: 50%%; object-fit: cover; vertical-align: middle; margin-right: 10px;">%s`,
+				data.OrganizationLogo, data.OrganizationName, organizer)
+		}
+		builder.AddParagraph(organizer)
+	}
+
+	description := data.EventOverview
+	if description == "" {
+		description = data.EventDescription
+	}
+	if description != "" {
+		builder.AddSection("About this Event", fmt.Sprintf(`<p style="line-height: 1.6;">%s</p>`, description))
+	}
+}
+
+// setUnsubscribeFooter sets a footer with the unsubscribe link, keeping the default footer without one
+func setUnsubscribeFooter(builder *builders.EmailBuilder, unsubscribeURL, reason string) {
+	if unsubscribeURL == "" {
+		return
+	}
+	builder.SetFooter(fmt.Sprintf(`
+		<p>Thank you for using Ticketly!</p>
+		<p style="font-size: 11px; color: #9CA3AF; margin-top: 10px;">
+			%s <a href="%s">Unsubscribe</a> from these notifications.
+		</p>
+	`, reason, unsubscribeURL))
+}
+
+// venueDetails is the venue JSON stored on a session
+type venueDetails struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	OnlineLink string `json:"onlineLink"`
+	Location   struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"location"`
+}
+
+// venueName returns the venue name of the venue JSON, or the raw value if it is not JSON
+func venueName(venueJSON string) string {
+	var venue venueDetails
+	if err := json.Unmarshal([]byte(venueJSON), &venue); err != nil {
+		return venueJSON
+	}
+	return venue.Name
+}
+
+// formatVenueLocation returns the section title and HTML for a venue: a join link for online
+// events, directions for venues with coordinates and the address otherwise
+func formatVenueLocation(venueJSON string) (string, string) {
+	var venue venueDetails
+	if err := json.Unmarshal([]byte(venueJSON), &venue); err != nil {
+		return "📍 Venue", fmt.Sprintf("<p>%s</p>", venueJSON)
+	}
+
+	if venue.OnlineLink != "" {
+		return "💻 Online Event", fmt.Sprintf(`<p><strong>%s</strong></p>
+			<p style="text-align: center;"><a href="%s">Join Online Event</a></p>`, venue.Name, venue.OnlineLink)
+	}
+
+	venueHTML := fmt.Sprintf("<p><strong>%s</strong></p>", venue.Name)
+	if venue.Address != "" {
+		venueHTML += fmt.Sprintf("<p>📮 %s</p>", venue.Address)
+	}
+
+	lat, lng := venue.Location.Y, venue.Location.X
+	if lat != 0 && lng != 0 {
+		directionsURL := fmt.Sprintf("https://www.google.com/maps/dir/?api=1&destination=%f,%f", lat, lng)
+		venueHTML += fmt.Sprintf(`<p style="text-align: center;"><a href="%s">🗺️ Get Directions</a></p>`, directionsURL)
+		return "📍 Venue Location", venueHTML
+	}
+	return "📍 Venue", venueHTML
+}
+
+// timeUntilPhrase describes how far t is from now, e.g. "in 30 minutes", "tomorrow" or "in 7 days".
+// Values are rounded so a reminder that fires a little late still reads naturally.
+func timeUntilPhrase(t, now time.Time) string {
+	d := t.Sub(now)
+	switch {
+	case d < 2*time.Minute:
+		return "soon"
+	case d < 90*time.Minute:
+		minutes := int(d.Round(5*time.Minute) / time.Minute)
+		if minutes < 5 {
+			minutes = int(d.Round(time.Minute) / time.Minute)
+		}
+		return fmt.Sprintf("in %d minutes", minutes)
+	case d < 20*time.Hour:
+		return fmt.Sprintf("in %d hours", int(d.Round(time.Hour)/time.Hour))
+	case d < 36*time.Hour:
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", int(d.Round(24*time.Hour)/(24*time.Hour)))
+}
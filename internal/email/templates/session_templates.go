@@ -12,7 +12,7 @@ import (
 )
 
 // GenerateSessionCreatedEmail generates an email for session creation
-func GenerateSessionCreatedEmail(session *models.EventSession, eventTitle string) email.EmailTemplate {
+func GenerateSessionCreatedEmail(session *models.EventSession, eventTitle, unsubscribeURL string) email.EmailTemplate {
 	builder := builders.NewEmailBuilder("Ticketly", "#4F46E5")
 
 	start := time.Unix(session.StartTime/1000000, 0)
@@ -45,6 +45,8 @@ func GenerateSessionCreatedEmail(session *models.EventSession, eventTitle string
 	builder.AddParagraph("Don't miss out! This session is now available for registration.")
 	// builder.AddButton("View Session Details", fmt.Sprintf("https://ticketly.com/sessions/%s", session.ID))
 
+	setUnsubscribeFooter(builder, unsubscribeURL, "This is an automated notification for an event you are subscribed to.")
+
 	return email.EmailTemplate{
 		Type:    email.EmailSessionCreated,
 		Subject: fmt.Sprintf("New Session Available - %s", eventTitle),
@@ -53,7 +55,7 @@ func GenerateSessionCreatedEmail(session *models.EventSession, eventTitle string
 }
 
 // GenerateSessionUpdatedEmail generates an email for session updates
-func GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle string) email.EmailTemplate {
+func GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle, unsubscribeURL string) email.EmailTemplate {
 	builder := builders.NewEmailBuilder("Ticketly", "#4F46E5")
 
 	builder.SetHeader("📝 Session Update", "A session you're following has been updated")
@@ -86,6 +88,8 @@ func GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle
 		builder.AddSection("📍 Venue Information", venueHTML)
 	}
 
+	setUnsubscribeFooter(builder, unsubscribeURL, "This is an automated notification for a session you are subscribed to.")
+
 	return email.EmailTemplate{
 		Type:    email.EmailSessionUpdated,
 		Subject: fmt.Sprintf("Session Updated - %s", eventTitle),
@@ -94,7 +98,7 @@ func GenerateSessionUpdatedEmail(before, after *models.EventSession, eventTitle
 }
 
 // GenerateSessionCancelledEmail generates an email for session cancellation
-func GenerateSessionCancelledEmail(session *models.EventSession, eventTitle string) email.EmailTemplate {
+func GenerateSessionCancelledEmail(session *models.EventSession, eventTitle, unsubscribeURL string) email.EmailTemplate {
 	builder := builders.NewEmailBuilder("Ticketly", "#EF4444")
 
 	builder.SetHeader("❌ Session Cancelled", "Important: A session has been cancelled")
@@ -119,6 +123,8 @@ func GenerateSessionCancelledEmail(session *models.EventSession, eventTitle stri
 	builder.AddParagraph("If you have purchased tickets for this session, you will receive a separate email regarding refunds.")
 	builder.AddParagraph("For any questions or concerns, please contact our support team.")
 
+	setUnsubscribeFooter(builder, unsubscribeURL, "This is an automated notification for a session you are subscribed to.")
+
 	return email.EmailTemplate{
 		Type:    email.EmailSessionCancelled,
 		Subject: fmt.Sprintf("⚠️ Session Cancelled - %s", eventTitle),
@@ -172,6 +178,64 @@ func GenerateSessionReminderEmail(session *models.EventSession, eventTitle strin
 	}
 }
 
+// SessionFollowUpData holds what the post-session follow-up email shows
+type SessionFollowUpData struct {
+	SessionID        string
+	EventTitle       string
+	OrganizationName string
+	StartTime        time.Time
+	EndTime          time.Time
+	FeedbackURL      string // a rating from 1 to 5 is appended as ?rating=N
+	UnsubscribeURL   string
+}
+
+// GenerateSessionFollowUpEmail generates the thank-you email sent to ticket holders after a session ended,
+// asking them to rate the session
+func GenerateSessionFollowUpEmail(data SessionFollowUpData) email.EmailTemplate {
+	builder := builders.NewEmailBuilder("Ticketly", "#10B981")
+
+	builder.SetHeader("🙏 Thanks for Coming!", "We hope you enjoyed the event")
+
+	host := "the organizer"
+	if data.OrganizationName != "" {
+		host = data.OrganizationName
+	}
+	builder.AddParagraph(fmt.Sprintf("Thank you for attending <strong>%s</strong>. %s would love to hear how it went.", data.EventTitle, host))
+
+	details := map[string]string{
+		"Event":   data.EventTitle,
+		"Date":    data.StartTime.Format("Monday, January 2, 2006"),
+		"Time":    fmt.Sprintf("%s - %s", data.StartTime.Format("3:04 PM"), data.EndTime.Format("3:04 PM")),
+		"Session": data.SessionID,
+	}
+	builder.AddDetailsList(details)
+
+	var stars []string
+	for rating := 1; rating <= 5; rating++ {
+		stars = append(stars, fmt.Sprintf(`<a href="%s?rating=%d" style="font-size: 28px; text-decoration: none;" title="%d of 5">⭐</a>`,
+			data.FeedbackURL, rating, rating))
+	}
+	builder.AddSection("⭐ Rate This Session", "<p style='text-align: center;'>"+strings.Join(stars, " ")+"</p>")
+	builder.AddButton("Leave Feedback", data.FeedbackURL)
+
+	builder.AddDivider()
+	builder.AddParagraph("Your feedback helps organizers make the next event even better.")
+	if data.UnsubscribeURL != "" {
+		builder.SetFooter(fmt.Sprintf(`
+			<p>Thank you for using Ticketly!</p>
+			<p style="font-size: 11px; color: #9CA3AF; margin-top: 10px;">
+				You received this email because you have tickets for this session. <a href="%s">Unsubscribe</a>
+			</p>
+		`, data.UnsubscribeURL))
+	}
+
+	return email.EmailTemplate{
+		Type:    email.EmailSessionFollowUp,
+		Subject: fmt.Sprintf("How was %s? Share your feedback", data.EventTitle),
+		HTML:    builder.Build(),
+	}
+}
+
 // Helper functions
 
 func formatVenueDetails(venueJSON string) string {
@@ -0,0 +1,55 @@
+package templates
+
+import (
+	"fmt"
+	"time"
+
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/email/builders"
+)
+
+// WaitlistOfferData holds what the waitlist seat offer email shows
+type WaitlistOfferData struct {
+	SessionID      string
+	EventTitle     string
+	ClaimURL       string
+	ExpiresAt      time.Time
+	UnsubscribeURL string
+}
+
+// GenerateWaitlistSeatAvailableEmail generates the email offering a freed seat to a waitlisted subscriber
+func GenerateWaitlistSeatAvailableEmail(data WaitlistOfferData) email.EmailTemplate {
+	builder := builders.NewEmailBuilder("Ticketly", "#F59E0B")
+
+	builder.SetHeader("🎟️ A Seat Opened Up!", "You're next on the waitlist")
+
+	builder.AddParagraph(fmt.Sprintf("Good news! A seat for <strong>%s</strong> just became available and you are next on the waitlist to book it. The seat is not held for you, so book it soon.", data.EventTitle))
+
+	builder.AddInfoBox(fmt.Sprintf("<strong>Claim it before %s UTC.</strong> After that the offer goes to the next person on the waitlist.",
+		data.ExpiresAt.UTC().Format("Monday, January 2, 3:04 PM")), "warning")
+
+	details := map[string]string{
+		"Event":   data.EventTitle,
+		"Session": data.SessionID,
+	}
+	builder.AddDetailsList(details)
+
+	builder.AddButton("Claim My Seat", data.ClaimURL)
+
+	builder.AddDivider()
+	builder.AddParagraph("Not interested anymore? Ignore this email and the seat will be offered to someone else.")
+	if data.UnsubscribeURL != "" {
+		builder.SetFooter(fmt.Sprintf(`
+			<p>Thank you for using Ticketly!</p>
+			<p style="font-size: 11px; color: #9CA3AF; margin-top: 10px;">
+				You received this email because you joined the waitlist for this session. <a href="%s">Leave the waitlist</a>
+			</p>
+		`, data.UnsubscribeURL))
+	}
+
+	return email.EmailTemplate{
+		Type:    email.EmailWaitlistSeatAvailable,
+		Subject: fmt.Sprintf("A seat is available for %s - claim it now", data.EventTitle),
+		HTML:    builder.Build(),
+	}
+}
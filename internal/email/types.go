@@ -9,6 +9,7 @@ const (
 	CategoryOrganization EmailCategory = "ORGANIZATION"
 	CategoryPayment      EmailCategory = "PAYMENT"
 	CategoryOrder        EmailCategory = "ORDER"
+	CategoryWaitlist     EmailCategory = "WAITLIST"
 )
 
 // EmailAction represents the action that triggered the email
@@ -27,6 +28,11 @@ const (
 	ActionFailed    EmailAction = "FAILED"
 	ActionRefunded  EmailAction = "REFUNDED"
 	ActionReminder  EmailAction = "REMINDER"
+	ActionFollowUp  EmailAction = "FOLLOW_UP"
+	ActionAvailable EmailAction = "SEAT_AVAILABLE"
+
+	ActionStartReminder EmailAction = "START_REMINDER"
+	ActionSalesReminder EmailAction = "SALES_REMINDER"
 )
 
 // EmailType represents a specific type of email combining category and action
@@ -43,6 +49,10 @@ var (
 	EmailSessionCancelled = EmailType{CategorySession, ActionCancelled}
 	EmailSessionDeleted   = EmailType{CategorySession, ActionDeleted}
 	EmailSessionReminder  = EmailType{CategorySession, ActionReminder}
+	EmailSessionFollowUp  = EmailType{CategorySession, ActionFollowUp}
+
+	EmailSessionStartReminder = EmailType{CategorySession, ActionStartReminder}
+	EmailSessionSalesReminder = EmailType{CategorySession, ActionSalesReminder}
 
 	// Event emails
 	EmailEventCreated   = EmailType{CategoryEvent, ActionCreated}
@@ -69,6 +79,9 @@ var (
 	EmailPaymentFailed   = EmailType{CategoryPayment, ActionFailed}
 	EmailPaymentPending  = EmailType{CategoryPayment, ActionPending}
 	EmailPaymentRefunded = EmailType{CategoryPayment, ActionRefunded}
+
+	// Waitlist emails
+	EmailWaitlistSeatAvailable = EmailType{CategoryWaitlist, ActionAvailable}
 )
 
 // EmailTemplate represents a complete email template with subject and body
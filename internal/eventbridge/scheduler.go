@@ -1,14 +1,11 @@
-// internal/eventbridge/scheduler.gopackage eventbridge
-
 package eventbridge
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"ms-scheduling/internal/models"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,15 +13,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
 
 	appconfig "ms-scheduling/internal/config"
+	"ms-scheduling/internal/schedule"
 )
 
-// Service encapsulates the EventBridge Scheduler functionality.
+// scheduleTimeLayout is the time format of EventBridge Scheduler expressions: at(YYYY-MM-DDTHH:mm:ss)
+const scheduleTimeLayout = "2006-01-02T15:04:05"
+
+// Service is the EventBridge Scheduler backend of schedule.Scheduler.
 type Service struct {
 	SchedulerClient *scheduler.Client
 	Config          appconfig.Config
 }
 
-// NewService creates a new scheduler service.
+// NewService creates a new EventBridge scheduler backend.
 func NewService(cfg appconfig.Config, schedulerClient *scheduler.Client) *Service {
 	return &Service{
 		SchedulerClient: schedulerClient,
@@ -32,56 +33,24 @@ func NewService(cfg appconfig.Config, schedulerClient *scheduler.Client) *Servic
 	}
 }
 
-// CreateOrUpdateSchedule handles the idempotent logic for creating/updating a standard schedule.
-func (s *Service) CreateOrUpdateSchedule(sessionID string, scheduleTime time.Time, namePrefix, action, logContext string) error {
-	// Create standard message body
-	messageBody := models.SQSMessageBody{
-		SessionID: sessionID,
-		Action:    action,
-	}
-
-	// Use the common scheduling method with the Session Scheduling Queue ARN
-	return s.createOrUpdateScheduleWithPayload(sessionID, scheduleTime, namePrefix, s.Config.SQSSessionSchedulingQueueARN, messageBody, logContext)
-}
-
-// CreateOrUpdateReminderSchedule creates or updates a reminder-specific schedule
-func (s *Service) CreateOrUpdateReminderSchedule(sessionID string, scheduleTime time.Time, namePrefix, reminderType, logContext string) error {
-	// Create reminder-specific message body with only necessary fields
-	messageBody := models.SQSReminderMessageBody{
-		SessionID:      sessionID,
-		ReminderType:   reminderType,
-		TemplateID:     "session-reminder-template",
-		NotificationID: fmt.Sprintf("reminder-%s-%s", reminderType, sessionID),
-	}
-
-	// Use the common scheduling method with the reminder message body
-	return s.createOrUpdateScheduleWithPayload(sessionID, scheduleTime, namePrefix, s.Config.SQSSessionRemindersQueueARN, messageBody, logContext)
-}
-
-// createOrUpdateScheduleWithPayload is a generic method that handles the scheduling logic with any payload
-func (s *Service) createOrUpdateScheduleWithPayload(sessionID string, scheduleTime time.Time, namePrefix, queueArn string, payload interface{}, logContext string) error {
-	scheduleName := namePrefix + sessionID
-	log.Printf("Creating/updating schedule '%s' at time: %s", scheduleName, scheduleTime)
-
-	// Format time for EventBridge Scheduler expression: at(YYYY-MM-DDTHH:mm:ss)
-	scheduleExpression := fmt.Sprintf("at(%s)", scheduleTime.UTC().Format("2006-01-02T15:04:05"))
-
-	// Marshal the payload to JSON
-	inputJSON, err := json.Marshal(payload)
+// CreateOrUpdate creates the schedule, or updates it when a schedule with the same name already exists.
+func (s *Service) CreateOrUpdate(ctx context.Context, job schedule.Job) error {
+	queueArn, err := s.queueArn(job.Target)
 	if err != nil {
-		log.Printf("Error marshaling message body to JSON: %v", err)
 		return err
 	}
 
+	scheduleExpression := fmt.Sprintf("at(%s)", job.FireAt.UTC().Format(scheduleTimeLayout))
+
 	target := types.Target{
 		Arn:     aws.String(queueArn),
 		RoleArn: aws.String(s.Config.SchedulerRoleARN),
-		Input:   aws.String(string(inputJSON)),
+		Input:   aws.String(job.Payload),
 	}
 
 	// First, try to create the schedule
-	_, err = s.SchedulerClient.CreateSchedule(context.TODO(), &scheduler.CreateScheduleInput{
-		Name:                       aws.String(scheduleName),
+	_, err = s.SchedulerClient.CreateSchedule(ctx, &scheduler.CreateScheduleInput{
+		Name:                       aws.String(job.Name),
 		GroupName:                  aws.String(s.Config.SchedulerGroupName),
 		ScheduleExpression:         aws.String(scheduleExpression),
 		Target:                     &target,
@@ -89,60 +58,131 @@ func (s *Service) createOrUpdateScheduleWithPayload(sessionID string, scheduleTi
 		ActionAfterCompletion:      types.ActionAfterCompletionDelete,
 		ScheduleExpressionTimezone: aws.String("UTC"),
 	})
+	if err == nil {
+		return nil
+	}
 
-	if err != nil {
-		var conflict *types.ConflictException
-		if errors.As(err, &conflict) {
-			log.Printf("Schedule '%s' already exists. Attempting to update.", scheduleName)
-			_, updateErr := s.SchedulerClient.UpdateSchedule(context.TODO(), &scheduler.UpdateScheduleInput{
-				Name:                       aws.String(scheduleName),
-				GroupName:                  aws.String(s.Config.SchedulerGroupName),
-				ScheduleExpression:         aws.String(scheduleExpression),
-				Target:                     &target,
-				FlexibleTimeWindow:         &types.FlexibleTimeWindow{Mode: types.FlexibleTimeWindowModeOff},
-				ActionAfterCompletion:      types.ActionAfterCompletionDelete,
-				ScheduleExpressionTimezone: aws.String("UTC"),
-			})
-			if updateErr != nil {
-				log.Printf("Failed to update EventBridge schedule for %s: %v", logContext, updateErr)
-				return updateErr
-			}
-			log.Printf("Successfully updated EventBridge schedule for %s.", logContext)
-			return nil
-		}
-		// It was a different error
-		log.Printf("Failed to create EventBridge schedule for %s: %v", logContext, err)
-		return err
+	var conflict *types.ConflictException
+	if !errors.As(err, &conflict) {
+		return fmt.Errorf("error creating EventBridge schedule %s: %w", job.Name, err)
 	}
 
-	log.Printf("Successfully created EventBridge schedule for %s.", logContext)
+	log.Printf("Schedule '%s' already exists. Attempting to update.", job.Name)
+	_, err = s.SchedulerClient.UpdateSchedule(ctx, &scheduler.UpdateScheduleInput{
+		Name:                       aws.String(job.Name),
+		GroupName:                  aws.String(s.Config.SchedulerGroupName),
+		ScheduleExpression:         aws.String(scheduleExpression),
+		Target:                     &target,
+		FlexibleTimeWindow:         &types.FlexibleTimeWindow{Mode: types.FlexibleTimeWindowModeOff},
+		ActionAfterCompletion:      types.ActionAfterCompletionDelete,
+		ScheduleExpressionTimezone: aws.String("UTC"),
+	})
+	if err != nil {
+		return fmt.Errorf("error updating EventBridge schedule %s: %w", job.Name, err)
+	}
 	return nil
 }
 
-// DeleteSchedule removes a schedule from EventBridge.
-func (s *Service) DeleteSchedule(sessionID, namePrefix string) {
-	scheduleName := namePrefix + sessionID
-	log.Printf("Deleting schedule '%s'", scheduleName)
-
-	_, err := s.SchedulerClient.DeleteSchedule(context.TODO(), &scheduler.DeleteScheduleInput{
-		Name:      aws.String(scheduleName),
+// Delete removes a schedule from EventBridge.
+func (s *Service) Delete(ctx context.Context, name string) error {
+	_, err := s.SchedulerClient.DeleteSchedule(ctx, &scheduler.DeleteScheduleInput{
+		Name:      aws.String(name),
 		GroupName: aws.String(s.Config.SchedulerGroupName),
 	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return schedule.ErrNotFound
+		}
+		return fmt.Errorf("error deleting EventBridge schedule %s: %w", name, err)
+	}
+	return nil
+}
 
+// Get returns a single schedule by name.
+func (s *Service) Get(ctx context.Context, name string) (*schedule.Job, error) {
+	output, err := s.SchedulerClient.GetSchedule(ctx, &scheduler.GetScheduleInput{
+		Name:      aws.String(name),
+		GroupName: aws.String(s.Config.SchedulerGroupName),
+	})
 	if err != nil {
 		var notFound *types.ResourceNotFoundException
 		if errors.As(err, &notFound) {
-			// This is not an error, the schedule might have already run and deleted itself.
-			log.Printf("Schedule '%s' not found for deletion, it may have already completed.", scheduleName)
-			return
+			return nil, schedule.ErrNotFound
 		}
-		log.Printf("Error deleting schedule '%s': %v", scheduleName, err)
-	} else {
-		log.Printf("Successfully deleted schedule '%s'", scheduleName)
+		return nil, fmt.Errorf("error getting EventBridge schedule %s: %w", name, err)
 	}
+
+	job := &schedule.Job{Name: aws.ToString(output.Name)}
+	if output.Target != nil {
+		job.Target = s.targetFor(aws.ToString(output.Target.Arn))
+		job.Payload = aws.ToString(output.Target.Input)
+	}
+	if job.FireAt, err = parseAtExpression(aws.ToString(output.ScheduleExpression)); err != nil {
+		return nil, fmt.Errorf("error parsing EventBridge schedule %s: %w", name, err)
+	}
+	return job, nil
 }
 
-// MicrosecondsToTime converts a Debezium microsecond timestamp to a Go time.Time object.
-func MicrosecondsToTime(microseconds int64) time.Time {
-	return time.Unix(0, microseconds*1000)
+// List returns every schedule in the configured group whose name starts with namePrefix.
+// ListSchedules only returns summaries, so each schedule is fetched to read its time and payload.
+func (s *Service) List(ctx context.Context, namePrefix string) ([]schedule.Job, error) {
+	input := &scheduler.ListSchedulesInput{
+		GroupName: aws.String(s.Config.SchedulerGroupName),
+	}
+	if namePrefix != "" {
+		input.NamePrefix = aws.String(namePrefix)
+	}
+
+	var jobs []schedule.Job
+	paginator := scheduler.NewListSchedulesPaginator(s.SchedulerClient, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing EventBridge schedules with prefix %s: %w", namePrefix, err)
+		}
+
+		for _, summary := range page.Schedules {
+			job, err := s.Get(ctx, aws.ToString(summary.Name))
+			if errors.Is(err, schedule.ErrNotFound) {
+				// Completed and deleted itself since the page was listed
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+// queueArn returns the SQS queue ARN schedules for target are delivered to
+func (s *Service) queueArn(target schedule.Target) (string, error) {
+	switch target {
+	case schedule.TargetSessionScheduling:
+		return s.Config.SQSSessionSchedulingQueueARN, nil
+	case schedule.TargetSessionReminders:
+		return s.Config.SQSSessionRemindersQueueARN, nil
+	}
+	return "", fmt.Errorf("unknown schedule target %q", target)
+}
+
+// targetFor maps a queue ARN back to its schedule target
+func (s *Service) targetFor(queueArn string) schedule.Target {
+	switch queueArn {
+	case s.Config.SQSSessionSchedulingQueueARN:
+		return schedule.TargetSessionScheduling
+	case s.Config.SQSSessionRemindersQueueARN:
+		return schedule.TargetSessionReminders
+	}
+	return schedule.Target(queueArn)
+}
+
+// parseAtExpression reads the fire time of a one-off "at(...)" schedule expression
+func parseAtExpression(expression string) (time.Time, error) {
+	if !strings.HasPrefix(expression, "at(") || !strings.HasSuffix(expression, ")") {
+		return time.Time{}, fmt.Errorf("unsupported schedule expression %q", expression)
+	}
+	return time.ParseInLocation(scheduleTimeLayout, expression[len("at("):len(expression)-1], time.UTC)
 }
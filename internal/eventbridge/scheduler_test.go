@@ -0,0 +1,20 @@
+package eventbridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAtExpression(t *testing.T) {
+	fireAt, err := parseAtExpression("at(2030-05-10T18:00:00)")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC).Equal(fireAt))
+}
+
+func TestParseAtExpressionRejectsRecurringSchedules(t *testing.T) {
+	_, err := parseAtExpression("rate(5 minutes)")
+	assert.Error(t, err)
+}
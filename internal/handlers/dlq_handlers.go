@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/config"
+	"ms-scheduling/internal/kafka"
+)
+
+// DLQHandler exposes dead-lettered Kafka messages to administrators
+type DLQHandler struct {
+	dlq    *kafka.DeadLetterQueue
+	topics map[string]bool
+}
+
+// NewDLQHandler creates a handler for the dead-letter topics of all consumed Kafka topics
+func NewDLQHandler(dlq *kafka.DeadLetterQueue, cfg config.Config) *DLQHandler {
+	topics := make(map[string]bool)
+	for _, topic := range []string{
+		cfg.EventSessionsKafkaTopic,
+		cfg.EventsKafkaTopic,
+		cfg.OrdersKafkaTopic,
+		cfg.OrdersUpdatedKafkaTopic,
+		cfg.OrdersCancelledKafkaTopic,
+		cfg.PaymentSuccessKafkaTopic,
+		cfg.PaymentFailedKafkaTopic,
+		cfg.PaymentRefundedKafkaTopic,
+	} {
+		if topic != "" {
+			topics[topic] = true
+		}
+	}
+
+	return &DLQHandler{
+		dlq:    dlq,
+		topics: topics,
+	}
+}
+
+// ListEntries handles GET /admin/v1/dlq/{topic}
+func (h *DLQHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	topic, ok := h.sourceTopic(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		limitInt, err := strconv.Atoi(limitParam)
+		if err == nil && limitInt > 0 && limitInt <= 500 {
+			limit = limitInt
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	entries, err := h.dlq.List(ctx, topic, limit)
+	if err != nil {
+		log.Printf("Error listing dead-lettered messages for %s: %v", topic, err)
+		http.Error(w, "Failed to list dead-lettered messages", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"topic":    topic,
+		"dlqTopic": h.dlq.TopicFor(topic),
+		"entries":  entries,
+	})
+}
+
+// Replay handles POST /admin/v1/dlq/{topic}/replay
+func (h *DLQHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	topic, ok := h.sourceTopic(w, r)
+	if !ok {
+		return
+	}
+
+	var replayRequest struct {
+		Partition *int   `json:"partition"`
+		Offset    *int64 `json:"offset"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&replayRequest); err != nil {
+		log.Printf("Error decoding request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if replayRequest.Partition == nil || replayRequest.Offset == nil {
+		http.Error(w, "partition and offset are required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	entry, err := h.dlq.Replay(ctx, topic, *replayRequest.Partition, *replayRequest.Offset)
+	if err != nil {
+		log.Printf("Error replaying dead-lettered message for %s: %v", topic, err)
+		http.Error(w, "Failed to replay message", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Message replayed successfully",
+		"entry":   entry,
+	})
+}
+
+// sourceTopic reads the topic from the URL and checks that it is one of the consumed topics
+func (h *DLQHandler) sourceTopic(w http.ResponseWriter, r *http.Request) (string, bool) {
+	topic := mux.Vars(r)["topic"]
+	if topic == "" {
+		http.Error(w, "Topic is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	if !h.topics[topic] {
+		http.Error(w, "Unknown topic", http.StatusNotFound)
+		return "", false
+	}
+
+	return topic, true
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/outbox"
+)
+
+// EmailHistoryHandler exposes the delivery log of the email outbox to administrators
+type EmailHistoryHandler struct {
+	store *outbox.Store
+}
+
+// NewEmailHistoryHandler creates a handler reading the email history from the outbox store
+func NewEmailHistoryHandler(store *outbox.Store) *EmailHistoryHandler {
+	return &EmailHistoryHandler{store: store}
+}
+
+// GetSubscriberHistory handles GET /admin/v1/emails/subscribers/{subscriberId}
+func (h *EmailHistoryHandler) GetSubscriberHistory(w http.ResponseWriter, r *http.Request) {
+	subscriberID, err := strconv.Atoi(mux.Vars(r)["subscriberId"])
+	if err != nil || subscriberID <= 0 {
+		http.Error(w, "Invalid subscriber ID", http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.store.GetSubscriberHistory(subscriberID, historyLimit(r))
+	if err != nil {
+		log.Printf("Error getting email history for subscriber %d: %v", subscriberID, err)
+		http.Error(w, "Failed to get email history", http.StatusInternalServerError)
+		return
+	}
+
+	if messages == nil {
+		messages = []outbox.Message{}
+	}
+	writeEmailHistory(w, map[string]interface{}{
+		"subscriberId": subscriberID,
+		"emails":       messages,
+	})
+}
+
+// GetTypeHistory handles GET /admin/v1/emails/types/{emailType}
+func (h *EmailHistoryHandler) GetTypeHistory(w http.ResponseWriter, r *http.Request) {
+	emailType := strings.ToUpper(mux.Vars(r)["emailType"])
+	if emailType == "" {
+		http.Error(w, "Email type is required", http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.store.GetTypeHistory(emailType, historyLimit(r))
+	if err != nil {
+		log.Printf("Error getting email history for type %s: %v", emailType, err)
+		http.Error(w, "Failed to get email history", http.StatusInternalServerError)
+		return
+	}
+
+	if messages == nil {
+		messages = []outbox.Message{}
+	}
+	writeEmailHistory(w, map[string]interface{}{
+		"emailType": emailType,
+		"emails":    messages,
+	})
+}
+
+// historyLimit reads the limit query parameter, 50 by default and at most 500
+func historyLimit(r *http.Request) int {
+	limit := 50
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		limitInt, err := strconv.Atoi(limitParam)
+		if err == nil && limitInt > 0 && limitInt <= 500 {
+			limit = limitInt
+		}
+	}
+	return limit
+}
+
+func writeEmailHistory(w http.ResponseWriter, response map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -52,6 +52,11 @@ func (h *HealthHandler) registerDefaultChecks() {
 	}
 }
 
+// AddLivenessCheck registers a check that fails the liveness probe while it returns an error
+func (h *HealthHandler) AddLivenessCheck(name string, check func() error) {
+	h.livenessChecks[name] = check
+}
+
 // HandleReadiness handles readiness probe requests
 func (h *HealthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
 	details := make(map[string]string)
@@ -113,6 +118,7 @@ func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
 
 	if !allOk {
 		response.Status = "DOWN"
+		response.Details = details
 		w.WriteHeader(http.StatusServiceUnavailable)
 	} else {
 		w.WriteHeader(http.StatusOK)
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/config"
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type OrganizationSubscriptionHandler struct {
+	subscriberService *services.SubscriberService
+	cfg               config.Config
+	authorizer        *auth.RoleAuthorizer
+}
+
+func NewOrganizationSubscriptionHandler(subscriberService *services.SubscriberService, cfg config.Config) *OrganizationSubscriptionHandler {
+	return &OrganizationSubscriptionHandler{
+		subscriberService: subscriberService,
+		cfg:               cfg,
+		authorizer:        auth.NewRoleAuthorizer(cfg.ClientID),
+	}
+}
+
+// Subscribe handles POST /organization-subscription/v1/subscribe
+func (h *OrganizationSubscriptionHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse request body
+	var subscribeRequest struct {
+		OrganizationID string `json:"organizationId"`
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&subscribeRequest)
+	if err != nil {
+		log.Printf("Error decoding request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if subscribeRequest.OrganizationID == "" {
+		http.Error(w, "OrganizationID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get or create subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting/creating subscriber: %v", err)
+		http.Error(w, "Failed to process subscription", http.StatusInternalServerError)
+		return
+	}
+
+	// Add subscription
+	err = h.subscriberService.AddSubscription(subscriber.SubscriberID, models.SubscriptionCategoryOrganization, subscribeRequest.OrganizationID)
+	if err != nil {
+		log.Printf("Error adding subscription: %v", err)
+		http.Error(w, "Failed to create subscription", http.StatusInternalServerError)
+		return
+	}
+
+	// Return success
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":        "Subscription created successfully",
+		"organizationId": subscribeRequest.OrganizationID,
+	})
+}
+
+// Unsubscribe handles DELETE /organization-subscription/v1/unsubscribe/:organizationId
+func (h *OrganizationSubscriptionHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get organization ID from URL path
+	vars := mux.Vars(r)
+	organizationID := vars["organizationId"]
+	if organizationID == "" {
+		http.Error(w, "OrganizationID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to process unsubscription", http.StatusInternalServerError)
+		return
+	}
+
+	// Remove subscription
+	err = h.subscriberService.RemoveSubscription(subscriber.SubscriberID, models.SubscriptionCategoryOrganization, organizationID)
+	if err != nil {
+		log.Printf("Error removing subscription: %v", err)
+		http.Error(w, "Failed to remove subscription", http.StatusInternalServerError)
+		return
+	}
+
+	// Return success
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":        "Unsubscribed successfully",
+		"organizationId": organizationID,
+	})
+}
+
+// IsSubscribed handles GET /organization-subscription/v1/is-subscribed/:organizationId
+func (h *OrganizationSubscriptionHandler) IsSubscribed(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get organization ID from URL path
+	vars := mux.Vars(r)
+	organizationID := vars["organizationId"]
+	if organizationID == "" {
+		http.Error(w, "OrganizationID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to check subscription", http.StatusInternalServerError)
+		return
+	}
+
+	// Check subscription
+	isSubscribed, err := h.subscriberService.IsSubscribed(subscriber.SubscriberID, models.SubscriptionCategoryOrganization, organizationID)
+	if err != nil {
+		log.Printf("Error checking subscription: %v", err)
+		http.Error(w, "Failed to check subscription", http.StatusInternalServerError)
+		return
+	}
+
+	// Return result
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"isSubscribed":   isSubscribed,
+		"organizationId": organizationID,
+	})
+}
+
+// GetUserSubscriptions handles GET /organization-subscription/v1/user-subscriptions
+func (h *OrganizationSubscriptionHandler) GetUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to get subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	// Get subscriptions for organizations only
+	subscriptions, err := h.subscriberService.GetOrganizationSubscriptionsForSubscriber(subscriber.SubscriberID)
+	if err != nil {
+		log.Printf("Error getting organization subscriptions: %v", err)
+		http.Error(w, "Failed to get subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	// Return result
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscriptions": subscriptions,
+	})
+}
+
+// GetOrganizationSubscribers handles GET /organization-subscription/v1/organization-subscribers/:organizationId
+func (h *OrganizationSubscriptionHandler) GetOrganizationSubscribers(w http.ResponseWriter, r *http.Request) {
+	// Check if user is admin
+	isAdmin, err := h.isUserAdmin(r)
+	if err != nil || !isAdmin {
+		log.Printf("User is not authorized to access this endpoint: %v", err)
+		http.Error(w, "Unauthorized - Admin access required", http.StatusForbidden)
+		return
+	}
+
+	// Get organization ID from URL path
+	vars := mux.Vars(r)
+	organizationID := vars["organizationId"]
+	if organizationID == "" {
+		http.Error(w, "OrganizationID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get subscribers
+	subscribers, err := h.subscriberService.GetOrganizationSubscribers(organizationID)
+	if err != nil {
+		log.Printf("Error getting organization subscribers: %v", err)
+		http.Error(w, "Failed to get subscribers", http.StatusInternalServerError)
+		return
+	}
+
+	// For simple implementation, we'll do manual pagination in memory
+	totalCount := len(subscribers)
+
+	// Parse pagination parameters
+	page := 1
+	pageSize := 20
+
+	// Parse query parameters
+	pageParam := r.URL.Query().Get("page")
+	if pageParam != "" {
+		pageInt, err := strconv.Atoi(pageParam)
+		if err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	pageSizeParam := r.URL.Query().Get("pageSize")
+	if pageSizeParam != "" {
+		pageSizeInt, err := strconv.Atoi(pageSizeParam)
+		if err == nil && pageSizeInt > 0 && pageSizeInt <= 100 {
+			pageSize = pageSizeInt
+		}
+	}
+
+	// Calculate pagination info
+	totalPages := (totalCount + pageSize - 1) / pageSize
+	hasNext := page < totalPages
+	hasPrev := page > 1
+
+	// Apply pagination manually
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start >= len(subscribers) {
+		// Return empty list if start is beyond the available data
+		subscribers = []models.Subscriber{}
+	} else if end > len(subscribers) {
+		// If end is beyond the available data, limit to available data
+		subscribers = subscribers[start:]
+	} else {
+		subscribers = subscribers[start:end]
+	}
+
+	// Return result
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscribers": subscribers,
+		"pagination": map[string]interface{}{
+			"page":       page,
+			"pageSize":   pageSize,
+			"totalCount": totalCount,
+			"totalPages": totalPages,
+			"hasNext":    hasNext,
+			"hasPrev":    hasPrev,
+		},
+	})
+}
+
+// isUserAdmin checks if the verified token grants one of the roles allowed to list subscribers
+func (h *OrganizationSubscriptionHandler) isUserAdmin(r *http.Request) (bool, error) {
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		return false, err
+	}
+
+	return h.authorizer.HasAnyRole(claims, h.cfg.OrganizationSubscribersRoles...), nil
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/notify"
+	"ms-scheduling/internal/preferences"
+	"ms-scheduling/internal/services"
+)
+
+// PreferenceHandler lets subscribers choose which notifications they receive and over which channels
+type PreferenceHandler struct {
+	subscriberService *services.SubscriberService
+	store             *preferences.Store
+}
+
+func NewPreferenceHandler(subscriberService *services.SubscriberService, store *preferences.Store) *PreferenceHandler {
+	return &PreferenceHandler{
+		subscriberService: subscriberService,
+		store:             store,
+	}
+}
+
+// GetPreferences handles GET /preferences/v1
+func (h *PreferenceHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to get preferences", http.StatusInternalServerError)
+		return
+	}
+
+	// Get preferences
+	prefs, err := h.store.List(subscriber.SubscriberID)
+	if err != nil {
+		log.Printf("Error getting preferences: %v", err)
+		http.Error(w, "Failed to get preferences", http.StatusInternalServerError)
+		return
+	}
+
+	// Return result
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"preferences": prefs,
+	})
+}
+
+// SavePreference handles PUT /preferences/v1
+func (h *PreferenceHandler) SavePreference(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Parse request body
+	var preferenceRequest struct {
+		Category  string               `json:"category"`
+		EmailType string               `json:"emailType"`
+		Enabled   *bool                `json:"enabled"`
+		Channels  []notify.ChannelType `json:"channels"`
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&preferenceRequest)
+	if err != nil {
+		log.Printf("Error decoding request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate request
+	if preferenceRequest.Category == "" || preferenceRequest.Enabled == nil {
+		http.Error(w, "category and enabled are required", http.StatusBadRequest)
+		return
+	}
+
+	// Get or create subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting/creating subscriber: %v", err)
+		http.Error(w, "Failed to save preference", http.StatusInternalServerError)
+		return
+	}
+
+	preference := preferences.Preference{
+		SubscriberID: subscriber.SubscriberID,
+		Category:     email.EmailCategory(preferenceRequest.Category),
+		EmailType:    preferenceRequest.EmailType,
+		Enabled:      *preferenceRequest.Enabled,
+		Channels:     preferenceRequest.Channels,
+	}
+	if err := preference.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Save preference
+	saved, err := h.store.Save(preference)
+	if err != nil {
+		log.Printf("Error saving preference: %v", err)
+		http.Error(w, "Failed to save preference", http.StatusInternalServerError)
+		return
+	}
+
+	// Return success
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Preference saved successfully",
+		"preference": saved,
+	})
+}
+
+// DeletePreference handles DELETE /preferences/v1/{category}/{emailType}
+func (h *PreferenceHandler) DeletePreference(w http.ResponseWriter, r *http.Request) {
+	// Extract user ID from token
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Get category and email type from URL path
+	vars := mux.Vars(r)
+	category := email.EmailCategory(strings.ToUpper(vars["category"]))
+	emailType := strings.ToUpper(vars["emailType"])
+	if category == "" || emailType == "" {
+		http.Error(w, "category and emailType are required", http.StatusBadRequest)
+		return
+	}
+
+	// Get subscriber
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to delete preference", http.StatusInternalServerError)
+		return
+	}
+
+	// Delete preference
+	deleted, err := h.store.Delete(subscriber.SubscriberID, category, emailType)
+	if err != nil {
+		log.Printf("Error deleting preference: %v", err)
+		http.Error(w, "Failed to delete preference", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Preference not found", http.StatusNotFound)
+		return
+	}
+
+	// Return success
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Preference deleted successfully",
+		"category":  category,
+		"emailType": emailType,
+	})
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/schedule"
+)
+
+// ReminderRuleHandler manages the per-organization reminder rules
+type ReminderRuleHandler struct {
+	store *schedule.RuleStore
+}
+
+// NewReminderRuleHandler creates a handler for reading and replacing reminder rules
+func NewReminderRuleHandler(store *schedule.RuleStore) *ReminderRuleHandler {
+	return &ReminderRuleHandler{store: store}
+}
+
+// GetDefaultRules handles GET /admin/v1/reminder-rules/defaults
+func (h *ReminderRuleHandler) GetDefaultRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rules": h.store.Defaults(),
+	})
+}
+
+// GetRules handles GET /admin/v1/reminder-rules/{organizationId}
+// An organization without rules of its own reports the defaults it uses.
+func (h *ReminderRuleHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	organizationID := mux.Vars(r)["organizationId"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	rules, err := h.store.List(ctx, organizationID)
+	if err != nil {
+		log.Printf("Error listing reminder rules for organization %s: %v", organizationID, err)
+		http.Error(w, "Failed to list reminder rules", http.StatusInternalServerError)
+		return
+	}
+
+	usesDefaults := len(rules) == 0
+	if usesDefaults {
+		rules = h.store.Defaults()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"organizationId": organizationID,
+		"rules":          rules,
+		"defaults":       usesDefaults,
+	})
+}
+
+// PutRules handles PUT /admin/v1/reminder-rules/{organizationId}
+// The body {"rules": [...]} replaces every rule of the organization. Existing sessions pick the
+// new rules up on their next change or reconciliation.
+func (h *ReminderRuleHandler) PutRules(w http.ResponseWriter, r *http.Request) {
+	organizationID := mux.Vars(r)["organizationId"]
+
+	var req struct {
+		Rules []schedule.ReminderRule `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Rules) == 0 {
+		http.Error(w, "At least one rule is required, delete the rules to use the defaults", http.StatusBadRequest)
+		return
+	}
+	if err := schedule.ValidateRules(req.Rules); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.Replace(ctx, organizationID, req.Rules); err != nil {
+		log.Printf("Error replacing reminder rules for organization %s: %v", organizationID, err)
+		http.Error(w, "Failed to save reminder rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"organizationId": organizationID,
+		"rules":          req.Rules,
+		"defaults":       false,
+	})
+}
+
+// DeleteRules handles DELETE /admin/v1/reminder-rules/{organizationId}
+// The organization falls back to the default rules.
+func (h *ReminderRuleHandler) DeleteRules(w http.ResponseWriter, r *http.Request) {
+	organizationID := mux.Vars(r)["organizationId"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.Delete(ctx, organizationID); err != nil {
+		log.Printf("Error deleting reminder rules for organization %s: %v", organizationID, err)
+		http.Error(w, "Failed to delete reminder rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Reminder rules deleted, the organization uses the defaults",
+	})
+}
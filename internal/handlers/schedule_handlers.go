@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/schedule"
+)
+
+// ScheduleHandler exposes the schedule registry to administrators
+type ScheduleHandler struct {
+	schedulerService *schedule.Service
+	registry         *schedule.Registry
+	reconciler       *schedule.Reconciler
+}
+
+// NewScheduleHandler creates a handler for listing, cancelling, firing and reconciling scheduled jobs
+func NewScheduleHandler(schedulerService *schedule.Service, registry *schedule.Registry, reconciler *schedule.Reconciler) *ScheduleHandler {
+	return &ScheduleHandler{
+		schedulerService: schedulerService,
+		registry:         registry,
+		reconciler:       reconciler,
+	}
+}
+
+// ListJobs handles GET /admin/v1/schedules?sessionId=&eventId=&status=&from=&to=&limit=
+// from and to are RFC 3339 times bounding the fire time.
+func (h *ScheduleHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := schedule.JobFilter{
+		SessionID: query.Get("sessionId"),
+		EventID:   query.Get("eventId"),
+		Status:    schedule.JobStatus(query.Get("status")),
+		Limit:     100,
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid "+param+" time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+		*target = &parsed
+	}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limitInt, err := strconv.Atoi(limitParam)
+		if err == nil && limitInt > 0 && limitInt <= 1000 {
+			filter.Limit = limitInt
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	jobs, err := h.registry.List(ctx, filter)
+	if err != nil {
+		log.Printf("Error listing scheduled jobs: %v", err)
+		http.Error(w, "Failed to list scheduled jobs", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"jobs":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// CancelJob handles POST /admin/v1/schedules/{name}/cancel
+func (h *ScheduleHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if name == "" {
+		http.Error(w, "Schedule name is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	err := h.schedulerService.Cancel(ctx, name)
+	if errors.Is(err, schedule.ErrNotFound) {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error cancelling schedule %s: %v", name, err)
+		http.Error(w, "Failed to cancel schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Schedule cancelled successfully",
+		"name":    name,
+	})
+}
+
+// FireJob handles POST /admin/v1/schedules/{name}/fire
+func (h *ScheduleHandler) FireJob(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	if name == "" {
+		http.Error(w, "Schedule name is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	job, err := h.schedulerService.FireNow(ctx, name)
+	if errors.Is(err, schedule.ErrNotFound) {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, schedule.ErrNotFireable) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error firing schedule %s: %v", name, err)
+		http.Error(w, "Failed to fire schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Schedule fired successfully",
+		"job":     job,
+	})
+}
+
+// GetReconcileReport handles GET /admin/v1/schedules/reconcile and returns the last run's diff summary
+func (h *ScheduleHandler) GetReconcileReport(w http.ResponseWriter, r *http.Request) {
+	report := h.reconciler.LastReport()
+	if report == nil {
+		http.Error(w, "No reconciliation has run yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Reconcile handles POST /admin/v1/schedules/reconcile?dryRun=true
+// A dry run only reports the differences; otherwise they are repaired.
+func (h *ScheduleHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	defer cancel()
+
+	report, err := h.reconciler.Reconcile(ctx, dryRun)
+	if errors.Is(err, schedule.ErrReconcileRunning) {
+		http.Error(w, "Reconciliation already running", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		// The report still covers the sessions checked before the error
+		log.Printf("Error reconciling schedules: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/sqsworker"
+)
+
+// maxSQSDLQLimit is the most dead-lettered messages listed or redriven per request
+const maxSQSDLQLimit = 10
+
+// SQSDLQHandler exposes the SQS dead-letter queues of the queue processors to administrators
+type SQSDLQHandler struct {
+	queues map[string]*sqsworker.DeadLetterQueue
+}
+
+// NewSQSDLQHandler creates a handler for the given dead-letter queues, keyed by the name used in the URL
+func NewSQSDLQHandler(queues map[string]*sqsworker.DeadLetterQueue) *SQSDLQHandler {
+	return &SQSDLQHandler{queues: queues}
+}
+
+// ListEntries handles GET /admin/v1/sqs-dlq/{queue}
+func (h *SQSDLQHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	name, dlq, ok := h.deadLetterQueue(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	entries, err := dlq.List(ctx, sqsDLQLimit(r.URL.Query().Get("limit")))
+	if err != nil {
+		log.Printf("Error listing dead-lettered messages for %s: %v", name, err)
+		http.Error(w, "Failed to list dead-lettered messages", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"queue":    name,
+		"dlqQueue": dlq.Name(),
+		"entries":  entries,
+	})
+}
+
+// Redrive handles POST /admin/v1/sqs-dlq/{queue}/redrive
+func (h *SQSDLQHandler) Redrive(w http.ResponseWriter, r *http.Request) {
+	name, dlq, ok := h.deadLetterQueue(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	moved, err := dlq.Redrive(ctx, sqsDLQLimit(r.URL.Query().Get("limit")))
+	if err != nil {
+		log.Printf("Error redriving dead-lettered messages for %s after %d messages: %v", name, len(moved), err)
+		http.Error(w, "Failed to redrive messages", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Messages redriven successfully",
+		"count":   len(moved),
+		"entries": moved,
+	})
+}
+
+// deadLetterQueue reads the queue name from the URL and looks up its dead-letter queue
+func (h *SQSDLQHandler) deadLetterQueue(w http.ResponseWriter, r *http.Request) (string, *sqsworker.DeadLetterQueue, bool) {
+	name := mux.Vars(r)["queue"]
+	if name == "" {
+		http.Error(w, "Queue is required", http.StatusBadRequest)
+		return "", nil, false
+	}
+
+	dlq, ok := h.queues[name]
+	if !ok {
+		http.Error(w, "Unknown queue", http.StatusNotFound)
+		return "", nil, false
+	}
+
+	return name, dlq, true
+}
+
+// sqsDLQLimit parses the limit query parameter, defaulting to and capped at one SQS batch
+func sqsDLQLimit(limitParam string) int {
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit <= 0 || limit > maxSQSDLQLimit {
+		return maxSQSDLQLimit
+	}
+	return limit
+}
@@ -9,7 +9,6 @@ import (
 	"ms-scheduling/internal/services"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -17,12 +16,14 @@ import (
 type SubscriptionHandler struct {
 	subscriberService *services.SubscriberService
 	cfg               config.Config
+	authorizer        *auth.RoleAuthorizer
 }
 
 func NewSubscriptionHandler(subscriberService *services.SubscriberService, cfg config.Config) *SubscriptionHandler {
 	return &SubscriptionHandler{
 		subscriberService: subscriberService,
 		cfg:               cfg,
+		authorizer:        auth.NewRoleAuthorizer(cfg.ClientID),
 	}
 }
 
@@ -283,23 +284,12 @@ func (h *SubscriptionHandler) GetEventSubscribers(w http.ResponseWriter, r *http
 	})
 }
 
-// isUserAdmin checks if the user has admin role in their token
+// isUserAdmin checks if the verified token grants one of the roles allowed to list subscribers
 func (h *SubscriptionHandler) isUserAdmin(r *http.Request) (bool, error) {
-	// Get the Authorization header
-	authHeader := r.Header.Get("Authorization")
-	if authHeader == "" {
-		return false, nil
-	}
-
-	// Extract the token
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return false, nil
+	claims, err := auth.GetClaimsFromContext(r.Context())
+	if err != nil {
+		return false, err
 	}
-	token := parts[1]
 
-	// Check if token has admin role
-	// In a real implementation, this would verify the JWT and check for admin role
-	// For now, we'll use a simple check based on token claims
-	return auth.HasAdminRole(token)
+	return h.authorizer.HasAnyRole(claims, h.cfg.EventSubscribersRoles...), nil
 }
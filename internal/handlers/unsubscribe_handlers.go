@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/services"
+)
+
+// UnsubscribeHandler serves the signed unsubscribe links sent in notification emails.
+// It needs no access token; the signed link itself identifies the subscription.
+type UnsubscribeHandler struct {
+	subscriberService *services.SubscriberService
+	signer            *auth.UnsubscribeSigner
+}
+
+func NewUnsubscribeHandler(subscriberService *services.SubscriberService, signer *auth.UnsubscribeSigner) *UnsubscribeHandler {
+	return &UnsubscribeHandler{
+		subscriberService: subscriberService,
+		signer:            signer,
+	}
+}
+
+// ConfirmUnsubscribe handles GET /unsubscribe/v1?token=...
+// Link scanners prefetch URLs from emails, so GET only asks for confirmation and never unsubscribes.
+func (h *UnsubscribeHandler) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.signer.Verify(r.URL.Query().Get("token"))
+	if err != nil {
+		log.Printf("Invalid unsubscribe token: %v", err)
+		writeUnsubscribePage(w, http.StatusBadRequest, "Invalid link",
+			"<p>This unsubscribe link is invalid or has expired.</p>")
+		return
+	}
+
+	writeUnsubscribePage(w, http.StatusOK, "Unsubscribe", fmt.Sprintf(
+		`<p>Stop receiving notifications for this %s?</p>
+		<form method="POST" action="?token=%s">
+			<button type="submit">Unsubscribe</button>
+		</form>`,
+		html.EscapeString(string(claims.Category)),
+		html.EscapeString(r.URL.Query().Get("token")),
+	))
+}
+
+// Unsubscribe handles POST /unsubscribe/v1?token=..., sent by the confirmation page or by
+// mail clients supporting RFC 8058 one-click unsubscribe (body "List-Unsubscribe=One-Click")
+func (h *UnsubscribeHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.signer.Verify(r.URL.Query().Get("token"))
+	if err != nil {
+		log.Printf("Invalid unsubscribe token: %v", err)
+		writeUnsubscribePage(w, http.StatusBadRequest, "Invalid link",
+			"<p>This unsubscribe link is invalid or has expired.</p>")
+		return
+	}
+
+	// Links can be used more than once; an already removed subscription is not an error
+	isSubscribed, err := h.subscriberService.IsSubscribed(claims.SubscriberID, claims.Category, claims.TargetUUID)
+	if err != nil {
+		log.Printf("Error checking subscription via unsubscribe link: %v", err)
+		writeUnsubscribePage(w, http.StatusInternalServerError, "Something went wrong",
+			"<p>We could not unsubscribe you. Please try again later.</p>")
+		return
+	}
+	if !isSubscribed {
+		writeUnsubscribePage(w, http.StatusOK, "Unsubscribed",
+			"<p>You are already unsubscribed from these notifications.</p>")
+		return
+	}
+
+	// Remove subscription
+	err = h.subscriberService.RemoveSubscription(claims.SubscriberID, claims.Category, claims.TargetUUID)
+	if err != nil {
+		log.Printf("Error removing subscription via unsubscribe link: %v", err)
+		writeUnsubscribePage(w, http.StatusInternalServerError, "Something went wrong",
+			"<p>We could not unsubscribe you. Please try again later.</p>")
+		return
+	}
+
+	log.Printf("Subscriber %d unsubscribed from %s %s via unsubscribe link", claims.SubscriberID, claims.Category, claims.TargetUUID)
+	writeUnsubscribePage(w, http.StatusOK, "Unsubscribed",
+		"<p>You will no longer receive these notifications.</p>")
+}
+
+// writeUnsubscribePage renders a minimal HTML page for the unsubscribe and waitlist claim links
+func writeUnsubscribePage(w http.ResponseWriter, status int, title, content string) {
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Ticketly - %s</title></head>
+<body style="font-family: Arial, sans-serif; text-align: center; margin-top: 60px;">
+	<h2>%s</h2>
+	%s
+</body>
+</html>`, html.EscapeString(title), html.EscapeString(title), content)
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/config"
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/services"
+	"ms-scheduling/internal/waitlist"
+)
+
+// WaitlistHandler lets users queue for sold-out sessions and claim the seats offered to them
+type WaitlistHandler struct {
+	subscriberService *services.SubscriberService
+	store             *waitlist.Store
+	signer            *auth.WaitlistClaimSigner
+	cfg               config.Config
+}
+
+// NewWaitlistHandler creates a waitlist handler; without a signer claim links are rejected
+func NewWaitlistHandler(subscriberService *services.SubscriberService, store *waitlist.Store, signer *auth.WaitlistClaimSigner, cfg config.Config) *WaitlistHandler {
+	return &WaitlistHandler{
+		subscriberService: subscriberService,
+		store:             store,
+		signer:            signer,
+		cfg:               cfg,
+	}
+}
+
+// Subscribe handles POST /waitlist/v1/subscribe
+func (h *WaitlistHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var subscribeRequest struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&subscribeRequest); err != nil {
+		log.Printf("Error decoding request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if subscribeRequest.SessionID == "" {
+		http.Error(w, "SessionID is required", http.StatusBadRequest)
+		return
+	}
+
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting/creating subscriber: %v", err)
+		http.Error(w, "Failed to join the waitlist", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.Join(ctx, subscriber.SubscriberID, subscribeRequest.SessionID); err != nil {
+		log.Printf("Error joining waitlist: %v", err)
+		http.Error(w, "Failed to join the waitlist", http.StatusInternalServerError)
+		return
+	}
+	status, err := h.store.Status(ctx, subscriber.SubscriberID, subscribeRequest.SessionID)
+	if err != nil {
+		log.Printf("Error getting waitlist status: %v", err)
+		http.Error(w, "Failed to join the waitlist", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(status)
+}
+
+// Unsubscribe handles DELETE /waitlist/v1/unsubscribe/:sessionId
+func (h *WaitlistHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := mux.Vars(r)["sessionId"]
+	if sessionID == "" {
+		http.Error(w, "SessionID is required", http.StatusBadRequest)
+		return
+	}
+
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to leave the waitlist", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.store.Leave(ctx, subscriber.SubscriberID, sessionID); err != nil {
+		log.Printf("Error leaving waitlist: %v", err)
+		http.Error(w, "Failed to leave the waitlist", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Left the waitlist successfully",
+		"sessionId": sessionID,
+	})
+}
+
+// GetStatus handles GET /waitlist/v1/status/:sessionId with the user's position or open offer
+func (h *WaitlistHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := mux.Vars(r)["sessionId"]
+	if sessionID == "" {
+		http.Error(w, "SessionID is required", http.StatusBadRequest)
+		return
+	}
+
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to get waitlist status", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	status, err := h.store.Status(ctx, subscriber.SubscriberID, sessionID)
+	if err != nil {
+		log.Printf("Error getting waitlist status: %v", err)
+		http.Error(w, "Failed to get waitlist status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// GetUserSubscriptions handles GET /waitlist/v1/user-subscriptions
+func (h *WaitlistHandler) GetUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserIDFromContext(r.Context())
+	if err != nil {
+		log.Printf("Error getting user ID from context: %v", err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	subscriber, err := h.subscriberService.GetOrCreateSubscriber(userID)
+	if err != nil {
+		log.Printf("Error getting subscriber: %v", err)
+		http.Error(w, "Failed to get subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	subscriptions, err := h.subscriberService.GetSubscriptionsForSubscriber(subscriber.SubscriberID)
+	if err != nil {
+		log.Printf("Error getting waitlist subscriptions: %v", err)
+		http.Error(w, "Failed to get subscriptions", http.StatusInternalServerError)
+		return
+	}
+
+	waitlists := []models.Subscription{}
+	for _, subscription := range subscriptions {
+		if subscription.Category == models.SubscriptionCategoryWaitlist {
+			waitlists = append(waitlists, subscription)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"subscriptions": waitlists,
+	})
+}
+
+// ConfirmClaim handles GET /waitlist/v1/claim?token=...
+// Link scanners prefetch URLs from emails, so GET only asks for confirmation and never claims.
+func (h *WaitlistHandler) ConfirmClaim(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.verifyClaim(w, r); !ok {
+		return
+	}
+
+	writeUnsubscribePage(w, http.StatusOK, "Claim your seat", fmt.Sprintf(
+		`<p>A seat has opened up for this session. Claim your offer to continue to checkout, where you can book it while seats last.</p>
+		<form method="POST" action="?token=%s">
+			<button type="submit">Claim my seat</button>
+		</form>`,
+		html.EscapeString(r.URL.Query().Get("token")),
+	))
+}
+
+// Claim handles POST /waitlist/v1/claim?token=... and redirects to the session page for checkout
+func (h *WaitlistHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.verifyClaim(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	offer, err := h.store.Claim(ctx, claims.OfferID, claims.SubscriberID)
+	switch {
+	case errors.Is(err, waitlist.ErrOfferExpired), errors.Is(err, waitlist.ErrOfferNotFound):
+		writeUnsubscribePage(w, http.StatusGone, "Offer expired",
+			"<p>This seat offer has expired and was passed on to the next person on the waitlist.</p>")
+		return
+	case err != nil:
+		log.Printf("Error claiming waitlist offer %d: %v", claims.OfferID, err)
+		writeUnsubscribePage(w, http.StatusInternalServerError, "Something went wrong",
+			"<p>We could not claim your seat. Please try again.</p>")
+		return
+	}
+
+	log.Printf("Subscriber %d claimed waitlist offer %d for session %s", claims.SubscriberID, offer.ID, offer.SessionID)
+
+	target := h.cfg.FrontendURL
+	if offer.EventID != "" {
+		target = fmt.Sprintf("%s/events/%s/%s?waitlistOffer=%d", h.cfg.FrontendURL, offer.EventID, offer.SessionID, offer.ID)
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// verifyClaim checks the claim token, writing the error page when it is invalid
+func (h *WaitlistHandler) verifyClaim(w http.ResponseWriter, r *http.Request) (*auth.WaitlistClaimClaims, bool) {
+	if h.signer == nil {
+		writeUnsubscribePage(w, http.StatusNotFound, "Invalid link", "<p>Waitlist claim links are not enabled.</p>")
+		return nil, false
+	}
+	claims, err := h.signer.Verify(r.URL.Query().Get("token"))
+	if err != nil {
+		log.Printf("Invalid waitlist claim token: %v", err)
+		writeUnsubscribePage(w, http.StatusBadRequest, "Invalid link",
+			"<p>This claim link is invalid or has expired.</p>")
+		return nil, false
+	}
+	return claims, true
+}
@@ -3,16 +3,47 @@ package kafka
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 
 	"ms-scheduling/internal/config"
 )
 
+// RetryPolicy controls how often a failing message is retried in-process before giving up
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NewRetryPolicy builds a retry policy from the shared backoff settings and a per-consumer attempt count
+func NewRetryPolicy(cfg config.Config, maxAttempts int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: cfg.KafkaRetryInitialBackoff,
+		MaxBackoff:     cfg.KafkaRetryMaxBackoff,
+	}
+}
+
+// backoff returns the delay after the given failed attempt: InitialBackoff * 2^(attempt-1), capped at MaxBackoff
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
 // BaseConsumer provides common functionality for all Kafka consumers
 type BaseConsumer struct {
-	Reader *kafka.Reader
-	Config config.Config
+	Reader      *kafka.Reader
+	Config      config.Config
+	RetryPolicy RetryPolicy
+	DLQ         *DeadLetterQueue
 }
 
 // NewBaseConsumer creates a new base consumer with the given configuration
@@ -21,11 +52,13 @@ func NewBaseConsumer(cfg config.Config, kafkaURL, topic string) *BaseConsumer {
 	if topic == "" || kafkaURL == "" {
 		log.Println("Empty Kafka topic or URL provided, skipping consumer creation")
 		return &BaseConsumer{
-			Reader: nil,
-			Config: cfg,
+			Reader:      nil,
+			Config:      cfg,
+			RetryPolicy: NewRetryPolicy(cfg, 1),
 		}
 	}
 
+	// Offsets are committed explicitly after a message is handled, see ConsumeMessages
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: []string{kafkaURL},
 		Topic:   topic,
@@ -33,35 +66,127 @@ func NewBaseConsumer(cfg config.Config, kafkaURL, topic string) *BaseConsumer {
 	})
 
 	return &BaseConsumer{
-		Reader: reader,
-		Config: cfg,
+		Reader:      reader,
+		Config:      cfg,
+		RetryPolicy: NewRetryPolicy(cfg, 1),
 	}
 }
 
+// SetDeadLetterQueue sets where messages are published once their retries are exhausted
+func (c *BaseConsumer) SetDeadLetterQueue(dlq *DeadLetterQueue) {
+	c.DLQ = dlq
+}
+
 // Close closes the Kafka reader
 func (c *BaseConsumer) Close() error {
 	return c.Reader.Close()
 }
 
-// ConsumeMessages consumes messages from Kafka and passes them to the provided handler function
+// consume consumes messages until the context is cancelled, then closes the reader so the consumer
+// group is left right away instead of after the session timeout. A panicking handler skips the
+// close, leaving the reader to the consumer the supervisor restarts.
+func (c *BaseConsumer) consume(ctx context.Context, handler func([]byte) error) {
+	c.ConsumeMessages(ctx, handler)
+
+	if err := c.Close(); err != nil {
+		log.Printf("Error closing Kafka reader for topic %s: %v", c.Reader.Config().Topic, err)
+	}
+}
+
+// ConsumeMessages consumes messages from Kafka and passes them to the provided handler function.
+// The offset is only committed once the handler succeeded or the message was dead-lettered, so a
+// message that is in flight when the service stops is delivered again (at-least-once).
 func (c *BaseConsumer) ConsumeMessages(ctx context.Context, handler func([]byte) error) {
 	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, stopping consumer")
-			return
-		default:
-			msg, err := c.Reader.ReadMessage(ctx)
-			if err != nil {
-				log.Printf("Error reading from Kafka: %v", err)
-				continue
+		msg, err := c.Reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Println("Context cancelled, stopping consumer")
+				return
 			}
+			log.Printf("Error reading from Kafka: %v", err)
+			continue
+		}
 
-			log.Printf("Received Kafka message from topic %s", msg.Topic)
+		log.Printf("Received Kafka message from topic %s (partition %d, offset %d)", msg.Topic, msg.Partition, msg.Offset)
 
-			if err := handler(msg.Value); err != nil {
-				log.Printf("Error processing message: %v", err)
+		if attempts, err := c.handleWithRetry(ctx, msg, handler); err != nil {
+			if ctx.Err() != nil {
+				// Leave the offset uncommitted so the message is redelivered after restart
+				log.Println("Context cancelled, stopping consumer")
+				return
 			}
+			log.Printf("Giving up on message from topic %s (partition %d, offset %d) after %d attempts: %v",
+				msg.Topic, msg.Partition, msg.Offset, attempts, err)
+
+			if c.DLQ != nil && !c.deadLetter(ctx, msg, err, attempts) {
+				// Leave the offset uncommitted so the message is not lost
+				log.Println("Context cancelled, stopping consumer")
+				return
+			}
+		}
+
+		// A handled message is also committed while shutting down, so it is not delivered again
+		commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		err = c.Reader.CommitMessages(commitCtx, msg)
+		cancel()
+		if err != nil {
+			log.Printf("Error committing offset %d on topic %s: %v", msg.Offset, msg.Topic, err)
+		}
+	}
+}
+
+// handleWithRetry runs the handler until it succeeds, the retry policy is exhausted, the error is
+// marked as poison or the context is cancelled. It returns the number of attempts made.
+func (c *BaseConsumer) handleWithRetry(ctx context.Context, msg kafka.Message, handler func([]byte) error) (int, error) {
+	maxAttempts := c.maxAttempts()
+
+	var err error
+	attempt := 1
+	for ; attempt <= maxAttempts; attempt++ {
+		if err = handler(msg.Value); err == nil {
+			return attempt, nil
+		}
+
+		if attempt == maxAttempts || IsPoison(err) {
+			break
 		}
+
+		delay := c.RetryPolicy.backoff(attempt)
+		log.Printf("Error processing message (attempt %d/%d), retrying in %s: %v", attempt, maxAttempts, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return attempt, err
+}
+
+// deadLetter publishes the message to the dead-letter queue, retrying until it succeeds.
+// It returns false if the context was cancelled before the message could be published.
+func (c *BaseConsumer) deadLetter(ctx context.Context, msg kafka.Message, cause error, attempts int) bool {
+	for retry := 1; ; retry++ {
+		err := c.DLQ.Publish(ctx, msg, cause, attempts)
+		if err == nil {
+			return true
+		}
+
+		delay := c.RetryPolicy.backoff(retry)
+		log.Printf("Error dead-lettering message from topic %s (offset %d), retrying in %s: %v", msg.Topic, msg.Offset, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *BaseConsumer) maxAttempts() int {
+	if c.RetryPolicy.MaxAttempts < 1 {
+		return 1
 	}
+	return c.RetryPolicy.MaxAttempts
 }
@@ -0,0 +1,66 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+	assert.Equal(t, 5*time.Second, policy.backoff(10))
+}
+
+func TestHandleWithRetryStopsOnSuccess(t *testing.T) {
+	consumer := &BaseConsumer{RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}}
+
+	calls := 0
+	attempts, err := consumer.handleWithRetry(context.Background(), kafka.Message{}, func([]byte) error {
+		calls++
+		if calls < 2 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestHandleWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	consumer := &BaseConsumer{RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}}
+
+	calls := 0
+	attempts, err := consumer.handleWithRetry(context.Background(), kafka.Message{}, func([]byte) error {
+		calls++
+		return errors.New("permanent failure")
+	})
+
+	assert.EqualError(t, err, "permanent failure")
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestHandleWithRetrySkipsRetriesForPoisonMessages(t *testing.T) {
+	consumer := &BaseConsumer{RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}}
+
+	calls := 0
+	attempts, err := consumer.handleWithRetry(context.Background(), kafka.Message{}, func([]byte) error {
+		calls++
+		return Poison(errors.New("invalid JSON"))
+	})
+
+	assert.True(t, IsPoison(err))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, attempts)
+}
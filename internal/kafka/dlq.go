@@ -0,0 +1,296 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers attached to every dead-lettered message
+const (
+	HeaderDLQError           = "dlq.error"
+	HeaderDLQAttempts        = "dlq.attempts"
+	HeaderDLQSourceTopic     = "dlq.source.topic"
+	HeaderDLQSourcePartition = "dlq.source.partition"
+	HeaderDLQSourceOffset    = "dlq.source.offset"
+	HeaderDLQTimestamp       = "dlq.timestamp"
+	HeaderDLQReplayedFrom    = "dlq.replayed.from"
+)
+
+// poisonError marks a message that can never be processed, e.g. because it cannot be unmarshalled
+type poisonError struct {
+	err error
+}
+
+func (e *poisonError) Error() string { return e.err.Error() }
+func (e *poisonError) Unwrap() error { return e.err }
+
+// Poison wraps a handler error so the message is dead-lettered without being retried
+func Poison(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &poisonError{err: err}
+}
+
+// IsPoison reports whether err was marked with Poison
+func IsPoison(err error) bool {
+	var p *poisonError
+	return errors.As(err, &p)
+}
+
+// DLQEntry is a dead-lettered message as returned by the admin API
+type DLQEntry struct {
+	Topic           string    `json:"topic"`
+	Partition       int       `json:"partition"`
+	Offset          int64     `json:"offset"`
+	Key             string    `json:"key,omitempty"`
+	Payload         string    `json:"payload"`
+	Error           string    `json:"error"`
+	Attempts        int       `json:"attempts"`
+	SourceTopic     string    `json:"sourceTopic"`
+	SourcePartition int       `json:"sourcePartition"`
+	SourceOffset    int64     `json:"sourceOffset"`
+	FailedAt        time.Time `json:"failedAt"`
+}
+
+// DeadLetterQueue publishes messages that could not be processed to "<topic><suffix>" and
+// lets operators inspect and replay them
+type DeadLetterQueue struct {
+	kafkaURL string
+	suffix   string
+	writer   *kafka.Writer
+}
+
+// NewDeadLetterQueue creates a dead-letter queue writing to "<source topic><suffix>" topics
+func NewDeadLetterQueue(kafkaURL, suffix string) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		kafkaURL: kafkaURL,
+		suffix:   suffix,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(kafkaURL),
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Close flushes and closes the underlying writer
+func (q *DeadLetterQueue) Close() error {
+	return q.writer.Close()
+}
+
+// TopicFor returns the dead-letter topic for a source topic
+func (q *DeadLetterQueue) TopicFor(sourceTopic string) string {
+	return sourceTopic + q.suffix
+}
+
+// Publish writes the original message to the dead-letter topic together with the failure details
+func (q *DeadLetterQueue) Publish(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	errText := ""
+	if cause != nil {
+		errText = cause.Error()
+	}
+
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQError, Value: []byte(errText)},
+		kafka.Header{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderDLQSourceTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQSourcePartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQSourceOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQTimestamp, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	dlqTopic := q.TopicFor(msg.Topic)
+	err := q.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   dlqTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("error publishing message to dead-letter topic %s: %w", dlqTopic, err)
+	}
+
+	log.Printf("Published message from %s (partition %d, offset %d) to dead-letter topic %s",
+		msg.Topic, msg.Partition, msg.Offset, dlqTopic)
+	return nil
+}
+
+// List returns up to limit of the most recent dead-lettered messages for a source topic, newest first
+func (q *DeadLetterQueue) List(ctx context.Context, sourceTopic string, limit int) ([]DLQEntry, error) {
+	dlqTopic := q.TopicFor(sourceTopic)
+
+	conn, err := kafka.DialContext(ctx, "tcp", q.kafkaURL)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to Kafka: %w", err)
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(dlqTopic)
+	if err != nil {
+		// The topic is created on the first publish, so a missing topic just means nothing failed yet
+		if errors.Is(err, kafka.UnknownTopicOrPartition) {
+			return []DLQEntry{}, nil
+		}
+		return nil, fmt.Errorf("error reading partitions of %s: %w", dlqTopic, err)
+	}
+
+	entries := []DLQEntry{}
+	for _, p := range partitions {
+		partitionEntries, err := q.readPartitionTail(ctx, dlqTopic, p.ID, limit)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, partitionEntries...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FailedAt.After(entries[j].FailedAt)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+// readPartitionTail reads up to limit messages from the end of a single partition
+func (q *DeadLetterQueue) readPartitionTail(ctx context.Context, topic string, partition, limit int) ([]DLQEntry, error) {
+	conn, err := kafka.DialLeader(ctx, "tcp", q.kafkaURL, topic, partition)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to leader of %s/%d: %w", topic, partition, err)
+	}
+	first, last, err := conn.ReadOffsets()
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading offsets of %s/%d: %w", topic, partition, err)
+	}
+
+	start := last - int64(limit)
+	if start < first {
+		start = first
+	}
+	if start >= last {
+		return nil, nil
+	}
+
+	reader := q.partitionReader(topic, partition)
+	defer reader.Close()
+	if err := reader.SetOffset(start); err != nil {
+		return nil, fmt.Errorf("error seeking %s/%d to offset %d: %w", topic, partition, start, err)
+	}
+
+	var entries []DLQEntry
+	for offset := start; offset < last; offset++ {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s/%d: %w", topic, partition, err)
+		}
+		entries = append(entries, toDLQEntry(msg))
+		if msg.Offset >= last-1 {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// Replay publishes a dead-lettered message back onto its source topic
+func (q *DeadLetterQueue) Replay(ctx context.Context, sourceTopic string, partition int, offset int64) (*DLQEntry, error) {
+	dlqTopic := q.TopicFor(sourceTopic)
+
+	reader := q.partitionReader(dlqTopic, partition)
+	defer reader.Close()
+	if err := reader.SetOffset(offset); err != nil {
+		return nil, fmt.Errorf("error seeking %s/%d to offset %d: %w", dlqTopic, partition, offset, err)
+	}
+
+	msg, err := reader.ReadMessage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s/%d offset %d: %w", dlqTopic, partition, offset, err)
+	}
+	if msg.Offset != offset {
+		return nil, fmt.Errorf("message %s/%d offset %d no longer exists", dlqTopic, partition, offset)
+	}
+
+	entry := toDLQEntry(msg)
+	if entry.SourceTopic != "" && entry.SourceTopic != sourceTopic {
+		return nil, fmt.Errorf("message %s/%d offset %d belongs to topic %s", dlqTopic, partition, offset, entry.SourceTopic)
+	}
+
+	// Drop the failure details but remember where the replay came from
+	headers := []kafka.Header{}
+	for _, h := range msg.Headers {
+		if !strings.HasPrefix(h.Key, "dlq.") {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, kafka.Header{
+		Key:   HeaderDLQReplayedFrom,
+		Value: []byte(fmt.Sprintf("%s/%d/%d", dlqTopic, partition, offset)),
+	})
+
+	err = q.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   sourceTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error replaying message onto %s: %w", sourceTopic, err)
+	}
+
+	log.Printf("Replayed dead-lettered message %s/%d/%d onto %s", dlqTopic, partition, offset, sourceTopic)
+	return &entry, nil
+}
+
+func (q *DeadLetterQueue) partitionReader(topic string, partition int) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   []string{q.kafkaURL},
+		Topic:     topic,
+		Partition: partition,
+	})
+}
+
+// toDLQEntry converts a dead-lettered Kafka message into its admin API representation
+func toDLQEntry(msg kafka.Message) DLQEntry {
+	entry := DLQEntry{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Payload:   string(msg.Value),
+		FailedAt:  msg.Time,
+	}
+
+	for _, h := range msg.Headers {
+		value := string(h.Value)
+		switch h.Key {
+		case HeaderDLQError:
+			entry.Error = value
+		case HeaderDLQAttempts:
+			entry.Attempts, _ = strconv.Atoi(value)
+		case HeaderDLQSourceTopic:
+			entry.SourceTopic = value
+		case HeaderDLQSourcePartition:
+			entry.SourcePartition, _ = strconv.Atoi(value)
+		case HeaderDLQSourceOffset:
+			entry.SourceOffset, _ = strconv.ParseInt(value, 10, 64)
+		case HeaderDLQTimestamp:
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				entry.FailedAt = t
+			}
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDLQEntryParsesHeaders(t *testing.T) {
+	msg := kafka.Message{
+		Topic:     "ticketly.order.created.dlq",
+		Partition: 1,
+		Offset:    42,
+		Key:       []byte("order-1"),
+		Value:     []byte(`{"OrderID":"order-1"}`),
+		Headers: []kafka.Header{
+			{Key: HeaderDLQError, Value: []byte("invalid character")},
+			{Key: HeaderDLQAttempts, Value: []byte("5")},
+			{Key: HeaderDLQSourceTopic, Value: []byte("ticketly.order.created")},
+			{Key: HeaderDLQSourcePartition, Value: []byte("3")},
+			{Key: HeaderDLQSourceOffset, Value: []byte("1234")},
+			{Key: HeaderDLQTimestamp, Value: []byte("2025-01-02T03:04:05Z")},
+		},
+	}
+
+	entry := toDLQEntry(msg)
+
+	assert.Equal(t, "ticketly.order.created.dlq", entry.Topic)
+	assert.Equal(t, 1, entry.Partition)
+	assert.Equal(t, int64(42), entry.Offset)
+	assert.Equal(t, "order-1", entry.Key)
+	assert.Equal(t, `{"OrderID":"order-1"}`, entry.Payload)
+	assert.Equal(t, "invalid character", entry.Error)
+	assert.Equal(t, 5, entry.Attempts)
+	assert.Equal(t, "ticketly.order.created", entry.SourceTopic)
+	assert.Equal(t, 3, entry.SourcePartition)
+	assert.Equal(t, int64(1234), entry.SourceOffset)
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), entry.FailedAt)
+}
+
+func TestDeadLetterQueueTopicFor(t *testing.T) {
+	dlq := &DeadLetterQueue{suffix: ".dlq"}
+
+	assert.Equal(t, "dbz.ticketly.public.events.dlq", dlq.TopicFor("dbz.ticketly.public.events"))
+}
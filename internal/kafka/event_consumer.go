@@ -19,6 +19,7 @@ type EventConsumer struct {
 // NewEventConsumer creates a new consumer for event events
 func NewEventConsumer(cfg config.Config, subscriberService *services.SubscriberService) *EventConsumer {
 	baseConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.EventsKafkaTopic)
+	baseConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaEventRetryAttempts)
 
 	return &EventConsumer{
 		BaseConsumer:      *baseConsumer,
@@ -30,7 +31,7 @@ func NewEventConsumer(cfg config.Config, subscriberService *services.SubscriberS
 func (c *EventConsumer) StartConsuming(ctx context.Context) error {
 	log.Printf("Starting event consumer for topic %s", c.Reader.Config().Topic)
 
-	c.ConsumeMessages(ctx, c.processEventEvent)
+	c.consume(ctx, c.processEventEvent)
 
 	return nil
 }
@@ -52,7 +53,7 @@ func (c *EventConsumer) processEventEvent(value []byte) error {
 
 	if err := json.Unmarshal(value, &rawEvent); err != nil {
 		log.Printf("Error unmarshalling event Debezium data: %v", err)
-		return err
+		return Poison(err)
 	}
 
 	// Determine event ID for logging
@@ -88,6 +89,15 @@ func (c *EventConsumer) processEventEvent(value []byte) error {
 			beforeStatus := rawEvent.Payload.Before.Status
 			afterStatus := rawEvent.Payload.After.Status
 
+			// A reviewed event is reported to its organizers before its subscribers hear about it
+			if beforeStatus == "PENDING" && (afterStatus == "APPROVED" || afterStatus == "REJECTED") {
+				source := rawEvent.Payload.Source.NotificationKey(rawEvent.Payload.Op, eventID)
+				if err := c.SubscriberService.SendEventReviewEmails(rawEvent.Payload.After, source); err != nil {
+					log.Printf("Error notifying organizers of event %s review: %v", eventID, err)
+					return err
+				}
+			}
+
 			if beforeStatus == "PENDING" && afterStatus == "APPROVED" {
 				// This is a status change from PENDING to APPROVED - treat as creation
 				if err := c.SubscriberService.ProcessEventCreation(&eventEvent); err != nil {
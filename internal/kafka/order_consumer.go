@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"sync"
 
 	"ms-scheduling/internal/config"
 	"ms-scheduling/internal/models"
@@ -16,6 +17,7 @@ type OrderConsumer struct {
 	UpdatedConsumer   BaseConsumer
 	CancelledConsumer BaseConsumer
 	SubscriberService *services.SubscriberService
+	Waitlist          WaitlistReleaser
 }
 
 // NewOrderConsumer creates a new consumer for order events
@@ -27,23 +29,38 @@ func NewOrderConsumer(cfg config.Config, subscriberService *services.SubscriberS
 	// Only create consumers for non-empty topics
 	if cfg.OrdersKafkaTopic != "" {
 		createdConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.OrdersKafkaTopic)
+		createdConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaOrderRetryAttempts)
 		result.CreatedConsumer = *createdConsumer
 	}
 
 	if cfg.OrdersUpdatedKafkaTopic != "" {
 		updatedConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.OrdersUpdatedKafkaTopic)
+		updatedConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaOrderRetryAttempts)
 		result.UpdatedConsumer = *updatedConsumer
 	}
 
 	if cfg.OrdersCancelledKafkaTopic != "" {
 		cancelledConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.OrdersCancelledKafkaTopic)
+		cancelledConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaOrderRetryAttempts)
 		result.CancelledConsumer = *cancelledConsumer
 	}
 
 	return result
 }
 
-// StartConsuming starts consuming order events
+// SetDeadLetterQueue sets the dead-letter queue on every order topic consumer
+func (c *OrderConsumer) SetDeadLetterQueue(dlq *DeadLetterQueue) {
+	c.CreatedConsumer.SetDeadLetterQueue(dlq)
+	c.UpdatedConsumer.SetDeadLetterQueue(dlq)
+	c.CancelledConsumer.SetDeadLetterQueue(dlq)
+}
+
+// SetWaitlist enables offering the seats of cancelled orders to the session's waitlist
+func (c *OrderConsumer) SetWaitlist(waitlist WaitlistReleaser) {
+	c.Waitlist = waitlist
+}
+
+// StartConsuming consumes order events from every configured topic until the context is cancelled
 func (c *OrderConsumer) StartConsuming(ctx context.Context) error {
 	// Start a goroutine for each configured topic
 
@@ -53,30 +70,39 @@ func (c *OrderConsumer) StartConsuming(ctx context.Context) error {
 		return nil
 	}
 
+	var wg sync.WaitGroup
+
 	// Created orders
 	if c.CreatedConsumer.Reader != nil {
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			log.Printf("Starting order created consumer for topic %s", c.CreatedConsumer.Reader.Config().Topic)
-			c.CreatedConsumer.ConsumeMessages(ctx, c.processOrderCreated)
+			c.CreatedConsumer.consume(ctx, c.processOrderCreated)
 		}()
 	}
 
 	// Updated orders
 	if c.UpdatedConsumer.Reader != nil {
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			log.Printf("Starting order updated consumer for topic %s", c.UpdatedConsumer.Reader.Config().Topic)
-			c.UpdatedConsumer.ConsumeMessages(ctx, c.processOrderUpdated)
+			c.UpdatedConsumer.consume(ctx, c.processOrderUpdated)
 		}()
 	}
 
 	// Cancelled orders
 	if c.CancelledConsumer.Reader != nil {
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			log.Printf("Starting order cancelled consumer for topic %s", c.CancelledConsumer.Reader.Config().Topic)
-			c.CancelledConsumer.ConsumeMessages(ctx, c.processOrderCancelled)
+			c.CancelledConsumer.consume(ctx, c.processOrderCancelled)
 		}()
 	}
 
+	wg.Wait()
 	return nil
 }
 
@@ -85,7 +111,7 @@ func (c *OrderConsumer) processOrderCreated(value []byte) error {
 	var order services.OrderCreatedEvent
 	if err := json.Unmarshal(value, &order); err != nil {
 		log.Printf("Error unmarshalling order.created event: %v", err)
-		return err
+		return Poison(err)
 	}
 	log.Printf("Processing order.created for OrderID=%s UserID=%s", order.OrderID, order.UserID)
 
@@ -136,7 +162,7 @@ func (c *OrderConsumer) processOrderUpdated(value []byte) error {
 	var order services.OrderCreatedEvent
 	if err := json.Unmarshal(value, &order); err != nil {
 		log.Printf("Error unmarshalling order.updated event: %v", err)
-		return err
+		return Poison(err)
 	}
 	log.Printf("Processing order.updated for OrderID=%s UserID=%s", order.OrderID, order.UserID)
 
@@ -186,10 +212,23 @@ func (c *OrderConsumer) processOrderCancelled(value []byte) error {
 	var order services.OrderCreatedEvent
 	if err := json.Unmarshal(value, &order); err != nil {
 		log.Printf("Error unmarshalling order.cancelled event: %v", err)
-		return err
+		return Poison(err)
 	}
 	log.Printf("Processing order.cancelled for OrderID=%s UserID=%s", order.OrderID, order.UserID)
 
+	// Offer the freed seats to the session's waitlist before notifying the buyer; the release is
+	// ignored when nobody is waiting for the session
+	if c.Waitlist != nil && order.SessionID != "" {
+		seats := len(order.Tickets)
+		if seats == 0 {
+			seats = 1
+		}
+		if _, err := c.Waitlist.Release(context.TODO(), "order-cancelled:"+order.OrderID, order.SessionID, order.EventID, seats); err != nil {
+			log.Printf("Error releasing waitlist seats for cancelled order %s: %v", order.OrderID, err)
+			return err
+		}
+	}
+
 	// Get subscriber - don't create if doesn't exist
 	subscriber, err := c.SubscriberService.GetSubscriberByUserID(order.UserID)
 	if err != nil {
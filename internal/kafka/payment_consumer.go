@@ -0,0 +1,234 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"ms-scheduling/internal/config"
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/email/templates"
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/services"
+)
+
+// PaymentEvent represents the structure of the payment Kafka events
+type PaymentEvent struct {
+	PaymentID     string  `json:"PaymentID"`
+	OrderID       string  `json:"OrderID"`
+	UserID        string  `json:"UserID"`
+	EventID       string  `json:"EventID"`
+	SessionID     string  `json:"SessionID"`
+	Status        string  `json:"Status"`
+	Amount        float64 `json:"Amount"`
+	Currency      string  `json:"Currency"`
+	PaymentMethod string  `json:"PaymentMethod"`
+	TransactionID string  `json:"TransactionID"`
+	ProcessedAt   string  `json:"ProcessedAt"`
+	FailureReason string  `json:"FailureReason"`
+	RefundAmount  float64 `json:"RefundAmount"`
+	RefundReason  string  `json:"RefundReason"`
+	EventTitle    string  `json:"EventTitle"`
+	SessionTitle  string  `json:"SessionTitle"`
+}
+
+// NotificationKey identifies the payment status change for notification dedupe
+func (p *PaymentEvent) NotificationKey() string {
+	return "payment:" + p.PaymentID + ":" + p.Status
+}
+
+// PaymentSubscriberService looks up the subscriber a payment email goes to
+type PaymentSubscriberService interface {
+	GetOrCreateSubscriber(userID string) (*models.Subscriber, error)
+}
+
+// PaymentConsumer handles payment-related Kafka events
+type PaymentConsumer struct {
+	SuccessConsumer   BaseConsumer
+	FailedConsumer    BaseConsumer
+	RefundedConsumer  BaseConsumer
+	SubscriberService PaymentSubscriberService
+	EmailManager      *email.EmailManager
+}
+
+// NewPaymentConsumer creates a new consumer for payment events
+func NewPaymentConsumer(cfg config.Config, subscriberService *services.SubscriberService) *PaymentConsumer {
+	result := &PaymentConsumer{
+		SubscriberService: subscriberService,
+		EmailManager:      subscriberService.EmailManager,
+	}
+
+	// Only create consumers for non-empty topics
+	if cfg.PaymentSuccessKafkaTopic != "" {
+		successConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.PaymentSuccessKafkaTopic)
+		successConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaPaymentRetryAttempts)
+		result.SuccessConsumer = *successConsumer
+	}
+
+	if cfg.PaymentFailedKafkaTopic != "" {
+		failedConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.PaymentFailedKafkaTopic)
+		failedConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaPaymentRetryAttempts)
+		result.FailedConsumer = *failedConsumer
+	}
+
+	if cfg.PaymentRefundedKafkaTopic != "" {
+		refundedConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.PaymentRefundedKafkaTopic)
+		refundedConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaPaymentRetryAttempts)
+		result.RefundedConsumer = *refundedConsumer
+	}
+
+	return result
+}
+
+// SetDeadLetterQueue sets the dead-letter queue on every payment topic consumer
+func (c *PaymentConsumer) SetDeadLetterQueue(dlq *DeadLetterQueue) {
+	c.SuccessConsumer.SetDeadLetterQueue(dlq)
+	c.FailedConsumer.SetDeadLetterQueue(dlq)
+	c.RefundedConsumer.SetDeadLetterQueue(dlq)
+}
+
+// StartConsuming consumes payment events from every configured topic until the context is cancelled
+func (c *PaymentConsumer) StartConsuming(ctx context.Context) error {
+	if c.SuccessConsumer.Reader == nil && c.FailedConsumer.Reader == nil && c.RefundedConsumer.Reader == nil {
+		log.Println("No payment Kafka topics configured, skipping payment consumer setup")
+		return nil
+	}
+
+	if c.EmailManager == nil {
+		return errors.New("payment consumer needs an email manager")
+	}
+
+	var wg sync.WaitGroup
+
+	// Successful (or still pending) payments
+	if c.SuccessConsumer.Reader != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Starting payment success consumer for topic %s", c.SuccessConsumer.Reader.Config().Topic)
+			c.SuccessConsumer.consume(ctx, c.processPaymentSuccess)
+		}()
+	}
+
+	// Failed payments
+	if c.FailedConsumer.Reader != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Starting payment failed consumer for topic %s", c.FailedConsumer.Reader.Config().Topic)
+			c.FailedConsumer.consume(ctx, c.processPaymentFailed)
+		}()
+	}
+
+	// Refunded payments
+	if c.RefundedConsumer.Reader != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Starting payment refunded consumer for topic %s", c.RefundedConsumer.Reader.Config().Topic)
+			c.RefundedConsumer.consume(ctx, c.processPaymentRefunded)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// processPaymentSuccess handles ticketly.payment.success events. Payments the provider has not
+// settled yet are announced with the pending email instead.
+func (c *PaymentConsumer) processPaymentSuccess(value []byte) error {
+	return c.processPayment(value, "payment.success", func(payment *PaymentEvent) {
+		if payment.Status == "pending" || payment.Status == "processing" {
+			payment.Status = "pending"
+		} else {
+			payment.Status = "success"
+		}
+	})
+}
+
+// processPaymentFailed handles ticketly.payment.failed events
+func (c *PaymentConsumer) processPaymentFailed(value []byte) error {
+	return c.processPayment(value, "payment.failed", func(payment *PaymentEvent) {
+		payment.Status = "failed"
+	})
+}
+
+// processPaymentRefunded handles ticketly.payment.refunded events
+func (c *PaymentConsumer) processPaymentRefunded(value []byte) error {
+	return c.processPayment(value, "payment.refunded", func(payment *PaymentEvent) {
+		payment.Status = "refunded"
+	})
+}
+
+// processPayment decodes a payment event, lets setStatus fix its status for the topic and emails the payer
+func (c *PaymentConsumer) processPayment(value []byte, kind string, setStatus func(*PaymentEvent)) error {
+	var payment PaymentEvent
+	if err := json.Unmarshal(value, &payment); err != nil {
+		log.Printf("Error unmarshalling %s event: %v", kind, err)
+		return Poison(err)
+	}
+	if payment.UserID == "" {
+		log.Printf("Ignoring %s event for PaymentID=%s without a UserID", kind, payment.PaymentID)
+		return Poison(fmt.Errorf("%s event for payment %s has no user ID", kind, payment.PaymentID))
+	}
+	setStatus(&payment)
+	log.Printf("Processing %s for PaymentID=%s OrderID=%s UserID=%s", kind, payment.PaymentID, payment.OrderID, payment.UserID)
+
+	subscriber, err := c.SubscriberService.GetOrCreateSubscriber(payment.UserID)
+	if err != nil {
+		log.Printf("Error getting/creating subscriber for user %s: %v", payment.UserID, err)
+		return err
+	}
+
+	if err := c.sendPaymentEmail(subscriber, &payment); err != nil {
+		log.Printf("Error sending payment email: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully processed %s for payment %s (email: %s)", kind, payment.PaymentID, subscriber.SubscriberMail)
+	return nil
+}
+
+// sendPaymentEmail sends the payment email matching the payment status. Each status is only
+// announced once, even if the payment event is redelivered.
+func (c *PaymentConsumer) sendPaymentEmail(subscriber *models.Subscriber, payment *PaymentEvent) error {
+	manager := c.EmailManager.WithSource(payment.NotificationKey())
+	paymentData := convertToPaymentData(payment)
+
+	switch payment.Status {
+	case "success":
+		return manager.SendPaymentSuccessEmail(subscriber.SubscriberMail, paymentData)
+	case "failed":
+		return manager.SendPaymentFailedEmail(subscriber.SubscriberMail, paymentData, payment.FailureReason)
+	case "refunded":
+		return manager.SendPaymentRefundedEmail(subscriber.SubscriberMail, paymentData)
+	default:
+		return manager.SendPaymentPendingEmail(subscriber.SubscriberMail, paymentData)
+	}
+}
+
+// convertToPaymentData converts PaymentEvent to templates.PaymentData
+func convertToPaymentData(payment *PaymentEvent) *templates.PaymentData {
+	refundAmount := payment.RefundAmount
+	if payment.Status == "refunded" && refundAmount == 0 {
+		// A refund without an amount is a full refund
+		refundAmount = payment.Amount
+	}
+
+	return &templates.PaymentData{
+		PaymentID:     payment.PaymentID,
+		OrderID:       payment.OrderID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		PaymentMethod: payment.PaymentMethod,
+		TransactionID: payment.TransactionID,
+		ProcessedAt:   payment.ProcessedAt,
+		RefundAmount:  refundAmount,
+		RefundReason:  payment.RefundReason,
+		EventTitle:    payment.EventTitle,
+		SessionTitle:  payment.SessionTitle,
+	}
+}
@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"ms-scheduling/internal/config"
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/email/templates"
+	"ms-scheduling/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTypedSender records the type of every email it is asked to send
+type recordingTypedSender struct {
+	types    []string
+	subjects []string
+}
+
+func (s *recordingTypedSender) SendEmail(to, subject, body string) error {
+	return errors.New("untyped send not expected")
+}
+
+func (s *recordingTypedSender) SendTypedEmail(to, emailType, subject, body string) error {
+	s.types = append(s.types, emailType)
+	s.subjects = append(s.subjects, subject)
+	return nil
+}
+
+func newTestPaymentConsumer(t *testing.T) (*PaymentConsumer, *MockSubscriberService, *recordingTypedSender) {
+	t.Helper()
+	sender := &recordingTypedSender{}
+	subscribers := new(MockSubscriberService)
+	subscribers.On("GetOrCreateSubscriber", "user-1").Return(&models.Subscriber{SubscriberID: 7, SubscriberMail: "buyer@example.com"}, nil)
+
+	return &PaymentConsumer{
+		SubscriberService: subscribers,
+		EmailManager:      email.NewEmailManager(sender, config.Config{}, templates.NewStandardTemplateGenerator()),
+	}, subscribers, sender
+}
+
+func TestPaymentConsumerSendsTemplateForEachTopic(t *testing.T) {
+	consumer, subscribers, sender := newTestPaymentConsumer(t)
+
+	require.NoError(t, consumer.processPaymentSuccess([]byte(`{"PaymentID":"p-1","OrderID":"o-1","UserID":"user-1","Amount":25,"Currency":"USD"}`)))
+	require.NoError(t, consumer.processPaymentSuccess([]byte(`{"PaymentID":"p-2","UserID":"user-1","Status":"processing","Amount":10}`)))
+	require.NoError(t, consumer.processPaymentFailed([]byte(`{"PaymentID":"p-3","UserID":"user-1","Amount":10,"FailureReason":"card declined"}`)))
+	require.NoError(t, consumer.processPaymentRefunded([]byte(`{"PaymentID":"p-1","UserID":"user-1","Amount":25}`)))
+
+	assert.Equal(t, []string{"PAYMENT_SUCCESS", "PAYMENT_PENDING", "PAYMENT_FAILED", "PAYMENT_REFUNDED"}, sender.types)
+	// A refund without an amount refunds the whole payment
+	assert.Equal(t, "Refund Processed - $25.00", sender.subjects[3])
+	subscribers.AssertNumberOfCalls(t, "GetOrCreateSubscriber", 4)
+}
+
+func TestPaymentConsumerRejectsUnusablePayloads(t *testing.T) {
+	consumer, subscribers, sender := newTestPaymentConsumer(t)
+
+	err := consumer.processPaymentSuccess([]byte(`not json`))
+	assert.True(t, IsPoison(err))
+
+	err = consumer.processPaymentFailed([]byte(`{"PaymentID":"p-1"}`))
+	assert.True(t, IsPoison(err))
+
+	assert.Empty(t, sender.types)
+	subscribers.AssertNotCalled(t, "GetOrCreateSubscriber", "user-1")
+}
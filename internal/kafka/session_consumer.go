@@ -3,25 +3,35 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 
 	"ms-scheduling/internal/config"
-	"ms-scheduling/internal/eventbridge"
 	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/schedule"
 	"ms-scheduling/internal/services"
-	"time"
 )
 
+// WaitlistReleaser frees seats of a session for its waitlist. source identifies the change that
+// freed them, so a redelivered message frees them only once.
+type WaitlistReleaser interface {
+	Release(ctx context.Context, source, sessionID, eventID string, seats int) (bool, error)
+}
+
 // SessionConsumer handles event session-related Kafka events
 type SessionConsumer struct {
 	BaseConsumer
-	SchedulerService  *eventbridge.Service
+	SchedulerService  *schedule.Service
 	SubscriberService *services.SubscriberService
+	Waitlist          WaitlistReleaser
+	reopenSeats       int
 }
 
 // NewSessionConsumer creates a new consumer for event session events
-func NewSessionConsumer(cfg config.Config, schedulerService *eventbridge.Service, subscriberService *services.SubscriberService) *SessionConsumer {
+func NewSessionConsumer(cfg config.Config, schedulerService *schedule.Service, subscriberService *services.SubscriberService) *SessionConsumer {
 	baseConsumer := NewBaseConsumer(cfg, cfg.KafkaURL, cfg.EventSessionsKafkaTopic)
+	baseConsumer.RetryPolicy = NewRetryPolicy(cfg, cfg.KafkaSessionRetryAttempts)
 
 	return &SessionConsumer{
 		BaseConsumer:      *baseConsumer,
@@ -30,11 +40,17 @@ func NewSessionConsumer(cfg config.Config, schedulerService *eventbridge.Service
 	}
 }
 
+// SetWaitlist enables offering reopenSeats seats to the waitlist when a sold-out session goes back on sale
+func (c *SessionConsumer) SetWaitlist(waitlist WaitlistReleaser, reopenSeats int) {
+	c.Waitlist = waitlist
+	c.reopenSeats = reopenSeats
+}
+
 // StartConsuming starts consuming event session events
 func (c *SessionConsumer) StartConsuming(ctx context.Context) error {
 	log.Printf("Starting event session consumer for topic %s", c.Reader.Config().Topic)
 
-	c.ConsumeMessages(ctx, c.processSessionEvent)
+	c.consume(ctx, c.processSessionEvent)
 
 	return nil
 }
@@ -45,18 +61,43 @@ func (c *SessionConsumer) processSessionEvent(value []byte) error {
 	var event models.DebeziumEvent
 	if err := json.Unmarshal(value, &event); err != nil {
 		log.Printf("Error unmarshalling Debezium event: %v", err)
-		return err
+		return Poison(err)
 	}
 
-	// Handle both scheduling updates and notifications
-	c.updateSessionSchedules(event)
-	c.updateSessionNotification(event)
+	// Handle scheduling updates, notifications and the waitlist. All are safe to repeat, so a
+	// failure in any of them is returned and the whole message is retried.
+	scheduleErr := c.updateSessionSchedules(event)
+	notificationErr := c.updateSessionNotification(event)
+	waitlistErr := c.releaseWaitlistSeats(event)
+
+	return errors.Join(scheduleErr, notificationErr, waitlistErr)
+}
+
+// releaseWaitlistSeats frees seats for the waitlist when a session's status changes away from SOLD_OUT
+func (c *SessionConsumer) releaseWaitlistSeats(event models.DebeziumEvent) error {
+	if c.Waitlist == nil || event.Payload.Op != "u" {
+		return nil
+	}
+	before, after := event.Payload.Before, event.Payload.After
+	if before == nil || after == nil || before.Status != "SOLD_OUT" || after.Status == "SOLD_OUT" || after.Status == "CANCELLED" {
+		return nil
+	}
 
+	// The session row does not say how many seats opened up, so a fixed number is offered
+	source := fmt.Sprintf("session-reopened:%s:%d", after.ID, event.Payload.TsMs)
+	released, err := c.Waitlist.Release(context.TODO(), source, after.ID, after.EventID, c.reopenSeats)
+	if err != nil {
+		log.Printf("Error releasing waitlist seats for session %s: %v", after.ID, err)
+		return fmt.Errorf("error releasing waitlist seats for session %s: %w", after.ID, err)
+	}
+	if released {
+		log.Printf("Session %s is no longer sold out (status %s), offering %d seats to its waitlist", after.ID, after.Status, c.reopenSeats)
+	}
 	return nil
 }
 
 // updateSessionNotification converts a real Debezium event to session update notification format
-func (c *SessionConsumer) updateSessionNotification(event models.DebeziumEvent) {
+func (c *SessionConsumer) updateSessionNotification(event models.DebeziumEvent) error {
 	log.Printf("Processing session update notification from real Debezium event, operation: %s", event.Payload.Op)
 
 	// Determine session ID for logging
@@ -84,14 +125,15 @@ func (c *SessionConsumer) updateSessionNotification(event models.DebeziumEvent)
 	// Process the session update notification
 	if err := c.SubscriberService.ProcessSessionUpdate(&sessionEvent); err != nil {
 		log.Printf("Error processing session update notification from Debezium: %v", err)
-		return
+		return fmt.Errorf("error processing session update notification for session %s: %w", sessionID, err)
 	}
 
 	log.Printf("Successfully processed session update notification from Debezium event for session %s", sessionID)
+	return nil
 }
 
-// updateSessionSchedules handles scheduling updates for sessions
-func (c *SessionConsumer) updateSessionSchedules(event models.DebeziumEvent) {
+// updateSessionSchedules handles scheduling updates for sessions and returns every scheduling error it hit
+func (c *SessionConsumer) updateSessionSchedules(event models.DebeziumEvent) error {
 	sessionID := ""
 	if event.Payload.After != nil {
 		sessionID = event.Payload.After.ID
@@ -101,81 +143,20 @@ func (c *SessionConsumer) updateSessionSchedules(event models.DebeziumEvent) {
 
 	if sessionID == "" {
 		log.Println("Could not determine session ID from Debezium event. Skipping.")
-		return
+		return nil
 	}
 
+	var errs []error
+
 	log.Printf("Processing operation '%s' for session ID: %s", event.Payload.Op, sessionID)
 
 	switch event.Payload.Op {
 	case "c": // A new session was created
 		log.Println("Handling create operation...")
-		after := event.Payload.After
-		// Schedule the on-sale job using standard scheduler
-		if after.SalesStartTime > 0 {
-			onSaleTime := eventbridge.MicrosecondsToTime(after.SalesStartTime)
-			err := c.SchedulerService.CreateOrUpdateSchedule(
-				after.ID,
-				onSaleTime,
-				"session-onsale-",
-				"ON_SALE",
-				"on-sale job",
-			)
-			if err != nil {
-				log.Printf("Error scheduling on-sale job for session %s: %v", after.ID, err)
-			}
-		}
-
-		// Schedule the session-closed job using standard scheduler
-		if after.EndTime > 0 {
-			closedTime := eventbridge.MicrosecondsToTime(after.EndTime)
-			err := c.SchedulerService.CreateOrUpdateSchedule(
-				after.ID,
-				closedTime,
-				"session-closed-",
-				"CLOSED",
-				"closed job",
-			)
-			if err != nil {
-				log.Printf("Error scheduling closed job for session %s: %v", after.ID, err)
-			}
-		}
-
-		// Schedule the session reminder email job (1 day before session starts)
-		if after.StartTime > 0 {
-			sessionStartTime := eventbridge.MicrosecondsToTime(after.StartTime)
-			// Calculate 1 day before session start time
-			reminderTime := sessionStartTime.AddDate(0, 0, -1) // Subtract 1 day
-
-			salesStartTime := eventbridge.MicrosecondsToTime(after.SalesStartTime)
-			reminderSalesStartTime := salesStartTime.Add(-30 * time.Minute)
-
-			log.Printf("Scheduling session reminder email for session %s at %s (1 day before session starts)", after.ID, reminderTime.Format("2006-01-02 15:04:05"))
-			log.Printf("Scheduling sales reminder email for session %s at %s (30 minutes before sales start)", after.ID, reminderSalesStartTime.Format("2006-01-02 15:04:05"))
-
-			// Use the specialized reminder scheduler method with simplified parameters
-			err := c.SchedulerService.CreateOrUpdateReminderSchedule(
-				after.ID,
-				reminderTime,
-				"session-start-reminder-",
-				"SESSION_START",
-				"session reminder email job",
-			)
-
-			err_sale := c.SchedulerService.CreateOrUpdateReminderSchedule(
-				after.ID,
-				reminderSalesStartTime,
-				"sale-start-reminder-",
-				"SALE_START",
-				"sale reminder email job",
-			)
-
-			if err != nil {
-				log.Printf("Error scheduling reminder email job for session %s: %v", after.ID, err)
-			}
-
-			if err_sale != nil {
-				log.Printf("Error scheduling sales reminder email job for session %s: %v", after.ID, err_sale)
-			}
+		// Schedule the on-sale and closed jobs plus one reminder per reminder rule
+		if err := c.SchedulerService.ScheduleSession(schedule.SessionFromDebezium(event.Payload.After)); err != nil {
+			log.Printf("Error scheduling jobs for session %s: %v", sessionID, err)
+			errs = append(errs, err)
 		}
 
 	case "u": // A session was updated
@@ -184,87 +165,42 @@ func (c *SessionConsumer) updateSessionSchedules(event models.DebeziumEvent) {
 
 		// Sanity check
 		if before == nil || after == nil {
-			return
+			return nil
 		}
 
 		// If status changed to CANCELLED, delete schedules
 		if after.Status == "CANCELLED" && before.Status != "CANCELLED" {
 			log.Printf("Session %s was cancelled. Deleting schedules.", after.ID)
-			c.SchedulerService.DeleteSchedule(after.ID, "session-onsale-")
-			c.SchedulerService.DeleteSchedule(after.ID, "session-closed-")
-			c.SchedulerService.DeleteSchedule(after.ID, "session-start-reminder-")
-			c.SchedulerService.DeleteSchedule(after.ID, "sale-start-reminder-")
-			log.Printf("Deleted all schedules (including reminder email) for cancelled session %s", after.ID)
-			return
+			if err := c.SchedulerService.DeleteSessionSchedules(schedule.SessionFromDebezium(after)); err != nil {
+				return err
+			}
+			log.Printf("Deleted all schedules (including reminder emails) for cancelled session %s", after.ID)
+			return nil
 		}
 
 		if after.Status == "CANCELLED" {
 			log.Printf("Session %s was cancelled. No further scheduling actions will be taken.", after.ID)
-			return
-		}
-
-		// Check if on-sale time changed
-		if after.SalesStartTime != before.SalesStartTime {
-			onSaleTime := eventbridge.MicrosecondsToTime(after.SalesStartTime)
-			log.Printf("Sales start time for session %s changed. Updating schedule.", after.ID)
-			err := c.SchedulerService.CreateOrUpdateSchedule(
-				after.ID,
-				onSaleTime,
-				"session-onsale-",
-				"ON_SALE",
-				"on-sale job",
-			)
-			if err != nil {
-				log.Printf("Error updating on-sale job for session %s: %v", after.ID, err)
-			}
-		}
-
-		// Check if start time changed
-		if after.StartTime != before.StartTime {
-			// Update the reminder email schedule (1 day before new start time)
-			sessionStartTime := eventbridge.MicrosecondsToTime(after.StartTime)
-			reminderTime := sessionStartTime.AddDate(0, 0, -1) // Subtract 1 day
-
-			log.Printf("Session start time changed. Updating reminder email schedule for session %s to %s", after.ID, reminderTime.Format("2006-01-02 15:04:05"))
-
-			// Use the specialized reminder scheduler method
-			err := c.SchedulerService.CreateOrUpdateReminderSchedule(
-				after.ID,
-				reminderTime,
-				"session-start-reminder-",
-				"SESSION_START",
-				"session reminder email job",
-			)
-			if err != nil {
-				log.Printf("Error updating reminder email job for session %s: %v", after.ID, err)
-			}
+			return nil
 		}
 
-		if after.EndTime != before.EndTime {
-			closedTime := eventbridge.MicrosecondsToTime(after.EndTime)
-			log.Printf("End time for session %s changed. Updating schedule.", after.ID)
-			err := c.SchedulerService.CreateOrUpdateSchedule(
-				after.ID,
-				closedTime,
-				"session-closed-",
-				"CLOSED",
-				"closed job",
-			)
-			if err != nil {
-				log.Printf("Error updating closed job for session %s: %v", after.ID, err)
-			}
+		// Recompute every derived schedule (on-sale, closed and reminders) so that a changed
+		// timestamp moves all schedules anchored to it
+		if err := c.SchedulerService.RescheduleSession(schedule.SessionFromDebezium(before), schedule.SessionFromDebezium(after)); err != nil {
+			log.Printf("Error updating schedules for session %s: %v", after.ID, err)
+			errs = append(errs, err)
 		}
 
 	case "d": // A session was deleted
 		log.Println("Handling delete operation...")
 		before := event.Payload.Before
 		if before == nil {
-			return
+			return nil
 		}
-		c.SchedulerService.DeleteSchedule(before.ID, "session-onsale-")
-		c.SchedulerService.DeleteSchedule(before.ID, "session-closed-")
-		c.SchedulerService.DeleteSchedule(before.ID, "session-start-reminder-")
-		c.SchedulerService.DeleteSchedule(before.ID, "sale-start-reminder-")
-		log.Printf("Deleted all schedules (including reminder email) for deleted session %s", before.ID)
+		if err := c.SchedulerService.DeleteSessionSchedules(schedule.SessionFromDebezium(before)); err != nil {
+			return err
+		}
+		log.Printf("Deleted all schedules (including reminder emails) for deleted session %s", before.ID)
 	}
+
+	return errors.Join(errs...)
 }
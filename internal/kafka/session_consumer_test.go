@@ -0,0 +1,220 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/schedule"
+)
+
+// fakeScheduler is an in-memory schedule.Scheduler
+type fakeScheduler struct {
+	jobs      map[string]schedule.Job
+	deleteErr error
+}
+
+func newFakeScheduler() *fakeScheduler {
+	return &fakeScheduler{jobs: make(map[string]schedule.Job)}
+}
+
+func (f *fakeScheduler) CreateOrUpdate(ctx context.Context, job schedule.Job) error {
+	f.jobs[job.Name] = job
+	return nil
+}
+
+func (f *fakeScheduler) Delete(ctx context.Context, name string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	if _, ok := f.jobs[name]; !ok {
+		return schedule.ErrNotFound
+	}
+	delete(f.jobs, name)
+	return nil
+}
+
+func (f *fakeScheduler) Get(ctx context.Context, name string) (*schedule.Job, error) {
+	job, ok := f.jobs[name]
+	if !ok {
+		return nil, schedule.ErrNotFound
+	}
+	return &job, nil
+}
+
+func (f *fakeScheduler) List(ctx context.Context, namePrefix string) ([]schedule.Job, error) {
+	var jobs []schedule.Job
+	for name, job := range f.jobs {
+		if strings.HasPrefix(name, namePrefix) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func newTestSessionConsumer(backend schedule.Scheduler) *SessionConsumer {
+	return &SessionConsumer{SchedulerService: schedule.NewService(backend)}
+}
+
+func sessionEvent(op string, before, after *models.EventSession) models.DebeziumEvent {
+	return models.DebeziumEvent{Payload: models.DebeziumPayload{Op: op, Before: before, After: after}}
+}
+
+func TestUpdateSessionSchedulesCreatesAllJobs(t *testing.T) {
+	backend := newFakeScheduler()
+	consumer := newTestSessionConsumer(backend)
+
+	start := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+	salesStart := time.Date(2030, 4, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Hour)
+
+	err := consumer.updateSessionSchedules(sessionEvent("c", nil, &models.EventSession{
+		ID:             "s1",
+		StartTime:      models.TimeToMicroTimestamp(start),
+		EndTime:        models.TimeToMicroTimestamp(end),
+		SalesStartTime: models.TimeToMicroTimestamp(salesStart),
+	}))
+	require.NoError(t, err)
+
+	require.Len(t, backend.jobs, 4)
+	assert.True(t, salesStart.Equal(backend.jobs["session-onsale-s1"].FireAt))
+	assert.True(t, end.Equal(backend.jobs["session-closed-s1"].FireAt))
+	assert.True(t, start.AddDate(0, 0, -1).Equal(backend.jobs["session-start-reminder-s1"].FireAt))
+	assert.True(t, salesStart.Add(-30*time.Minute).Equal(backend.jobs["sale-start-reminder-s1"].FireAt))
+
+	onSale := backend.jobs["session-onsale-s1"]
+	assert.Equal(t, schedule.TargetSessionScheduling, onSale.Target)
+	var body models.SQSMessageBody
+	require.NoError(t, json.Unmarshal([]byte(onSale.Payload), &body))
+	assert.Equal(t, models.SQSMessageBody{SessionID: "s1", Action: "ON_SALE"}, body)
+
+	reminder := backend.jobs["session-start-reminder-s1"]
+	assert.Equal(t, schedule.TargetSessionReminders, reminder.Target)
+	var reminderBody models.SQSReminderMessageBody
+	require.NoError(t, json.Unmarshal([]byte(reminder.Payload), &reminderBody))
+	assert.Equal(t, "SESSION_START", reminderBody.ReminderType)
+}
+
+func TestUpdateSessionSchedulesDeletesJobsOnCancel(t *testing.T) {
+	backend := newFakeScheduler()
+	backend.jobs["session-onsale-s1"] = schedule.Job{Name: "session-onsale-s1"}
+	backend.jobs["session-closed-s1"] = schedule.Job{Name: "session-closed-s1"}
+	consumer := newTestSessionConsumer(backend)
+
+	err := consumer.updateSessionSchedules(sessionEvent("u",
+		&models.EventSession{ID: "s1", Status: "SCHEDULED"},
+		&models.EventSession{ID: "s1", Status: "CANCELLED"},
+	))
+
+	// Missing reminder schedules are not an error
+	require.NoError(t, err)
+	assert.Empty(t, backend.jobs)
+}
+
+func TestUpdateSessionSchedulesReturnsDeleteErrors(t *testing.T) {
+	backend := newFakeScheduler()
+	backend.deleteErr = errors.New("throttled")
+	consumer := newTestSessionConsumer(backend)
+
+	err := consumer.updateSessionSchedules(sessionEvent("d", &models.EventSession{ID: "s1"}, nil))
+
+	assert.ErrorContains(t, err, "throttled")
+}
+
+func TestUpdateSessionSchedulesFollowsReminderRules(t *testing.T) {
+	backend := newFakeScheduler()
+	consumer := newTestSessionConsumer(backend)
+	consumer.SchedulerService.SetRuleSource(schedule.StaticRules{
+		{Name: "start-7d", Anchor: schedule.AnchorStart, Offset: -7 * 24 * time.Hour, ReminderType: "SESSION_START"},
+		{Name: "start-1d", Anchor: schedule.AnchorStart, Offset: -24 * time.Hour, ReminderType: "SESSION_START"},
+		{Name: "start-2h", Anchor: schedule.AnchorStart, Offset: -2 * time.Hour, ReminderType: "SESSION_START"},
+	})
+
+	start := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+	created := &models.EventSession{ID: "s1", StartTime: models.TimeToMicroTimestamp(start)}
+	require.NoError(t, consumer.updateSessionSchedules(sessionEvent("c", nil, created)))
+	require.Len(t, backend.jobs, 3)
+
+	moved := start.Add(24 * time.Hour)
+	updated := &models.EventSession{ID: "s1", StartTime: models.TimeToMicroTimestamp(moved)}
+	require.NoError(t, consumer.updateSessionSchedules(sessionEvent("u", created, updated)))
+
+	assert.True(t, moved.Add(-7*24*time.Hour).Equal(backend.jobs["start-7d-s1"].FireAt))
+	assert.True(t, moved.Add(-24*time.Hour).Equal(backend.jobs["start-1d-s1"].FireAt))
+	assert.True(t, moved.Add(-2*time.Hour).Equal(backend.jobs["start-2h-s1"].FireAt))
+
+	cancelled := &models.EventSession{ID: "s1", Status: "CANCELLED", StartTime: updated.StartTime}
+	require.NoError(t, consumer.updateSessionSchedules(sessionEvent("u", updated, cancelled)))
+	assert.Empty(t, backend.jobs)
+}
+
+func TestUpdateSessionSchedulesMovesSaleReminderWithSalesStart(t *testing.T) {
+	backend := newFakeScheduler()
+	consumer := newTestSessionConsumer(backend)
+
+	start := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+	salesStart := time.Date(2030, 4, 1, 9, 0, 0, 0, time.UTC)
+	before := &models.EventSession{
+		ID:             "s1",
+		StartTime:      models.TimeToMicroTimestamp(start),
+		EndTime:        models.TimeToMicroTimestamp(start.Add(3 * time.Hour)),
+		SalesStartTime: models.TimeToMicroTimestamp(salesStart),
+	}
+	require.NoError(t, consumer.updateSessionSchedules(sessionEvent("c", nil, before)))
+	startReminder := backend.jobs["session-start-reminder-s1"]
+
+	movedSalesStart := salesStart.Add(72 * time.Hour)
+	after := *before
+	after.SalesStartTime = models.TimeToMicroTimestamp(movedSalesStart)
+	require.NoError(t, consumer.updateSessionSchedules(sessionEvent("u", before, &after)))
+
+	assert.True(t, movedSalesStart.Equal(backend.jobs["session-onsale-s1"].FireAt))
+	assert.True(t, movedSalesStart.Add(-30*time.Minute).Equal(backend.jobs["sale-start-reminder-s1"].FireAt))
+	assert.Equal(t, startReminder, backend.jobs["session-start-reminder-s1"])
+}
+
+// fakeWaitlist records seat releases, ignoring repeated sources
+type fakeWaitlist struct {
+	seats map[string]int
+}
+
+func (f *fakeWaitlist) Release(ctx context.Context, source, sessionID, eventID string, seats int) (bool, error) {
+	if _, ok := f.seats[source]; ok {
+		return false, nil
+	}
+	f.seats[source] = seats
+	return true, nil
+}
+
+func TestReleaseWaitlistSeatsWhenSessionIsNoLongerSoldOut(t *testing.T) {
+	waitlist := &fakeWaitlist{seats: make(map[string]int)}
+	consumer := newTestSessionConsumer(newFakeScheduler())
+	consumer.SetWaitlist(waitlist, 10)
+
+	event := sessionEvent("u",
+		&models.EventSession{ID: "s1", EventID: "e1", Status: "SOLD_OUT"},
+		&models.EventSession{ID: "s1", EventID: "e1", Status: "ON_SALE"},
+	)
+	event.Payload.TsMs = 1700000000000
+	require.NoError(t, consumer.releaseWaitlistSeats(event))
+	require.NoError(t, consumer.releaseWaitlistSeats(event))
+	assert.Equal(t, map[string]int{"session-reopened:s1:1700000000000": 10}, waitlist.seats)
+
+	// Selling out, or cancelling a sold-out session, frees nothing
+	require.NoError(t, consumer.releaseWaitlistSeats(sessionEvent("u",
+		&models.EventSession{ID: "s2", Status: "ON_SALE"},
+		&models.EventSession{ID: "s2", Status: "SOLD_OUT"},
+	)))
+	require.NoError(t, consumer.releaseWaitlistSeats(sessionEvent("u",
+		&models.EventSession{ID: "s3", Status: "SOLD_OUT"},
+		&models.EventSession{ID: "s3", Status: "CANCELLED"},
+	)))
+	assert.Len(t, waitlist.seats, 1)
+}
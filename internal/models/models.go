@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -58,6 +59,15 @@ type DebeziumSource struct {
 	Xmin      *int64 `json:"xmin,omitempty"`
 }
 
+// NotificationKey identifies the source change of a row for notification dedupe.
+// The LSN and transaction ID stay the same across Kafka redeliveries and Debezium replays.
+func (s DebeziumSource) NotificationKey(op, rowID string) string {
+	if s.Lsn != 0 {
+		return fmt.Sprintf("%s.%s:%s:%s:lsn=%d:tx=%d", s.Schema, s.Table, rowID, op, s.Lsn, s.TxId)
+	}
+	return fmt.Sprintf("%s.%s:%s:%s:ts=%d", s.Schema, s.Table, rowID, op, s.TsMs)
+}
+
 // DebeziumTransaction represents transaction information in a Debezium event
 type DebeziumTransaction struct {
 	ID                  string `json:"id"`
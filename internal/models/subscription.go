@@ -13,6 +13,7 @@ const (
 	SubscriptionCategoryOrganization SubscriptionCategory = "organization"
 	SubscriptionCategoryEvent        SubscriptionCategory = "event"
 	SubscriptionCategorySession      SubscriptionCategory = "session"
+	SubscriptionCategoryWaitlist     SubscriptionCategory = "waitlist" // target is a sold-out session
 )
 
 // Scan implements the sql.Scanner interface for SubscriptionCategory
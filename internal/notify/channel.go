@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"strings"
+)
+
+// ChannelType identifies a delivery channel
+type ChannelType string
+
+// Channel types. Only EMAIL and WEBHOOK have an implementation; SMS, WEB_PUSH and IN_APP are
+// stubs without a provider, so notifications routed to them are skipped until a Channel is registered.
+const (
+	ChannelEmail   ChannelType = "EMAIL"
+	ChannelSMS     ChannelType = "SMS"
+	ChannelWebPush ChannelType = "WEB_PUSH"
+	ChannelWebhook ChannelType = "WEBHOOK"
+	ChannelInApp   ChannelType = "IN_APP"
+)
+
+// ParseChannelTypes converts names such as "email" or "WEB_PUSH" into channel types
+func ParseChannelTypes(names []string) []ChannelType {
+	channels := make([]ChannelType, 0, len(names))
+	for _, name := range names {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name != "" {
+			channels = append(channels, ChannelType(name))
+		}
+	}
+	return channels
+}
+
+// Recipient is who a notification is delivered to. Channels use the address they need
+// and skip recipients they cannot reach.
+type Recipient struct {
+	SubscriberID int    `json:"subscriberId,omitempty"`
+	UserID       string `json:"userId,omitempty"`
+	Email        string `json:"email,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+// Message is a rendered notification. Type is the email type string (e.g. "ORDER_CONFIRMED")
+// and is used to pick channels. Headers are passed on by channels that support them (email).
+type Message struct {
+	Type    string            `json:"type"`
+	Subject string            `json:"subject"`
+	HTML    string            `json:"html,omitempty"`
+	Text    string            `json:"text,omitempty"`
+	Headers map[string]string `json:"-"`
+}
+
+// Channel delivers notifications over one medium
+type Channel interface {
+	Type() ChannelType
+	CanDeliver(recipient Recipient) bool
+	Send(recipient Recipient, msg Message) error
+}
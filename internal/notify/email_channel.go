@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"ms-scheduling/internal/email"
+)
+
+// EmailChannel delivers notifications with an email.EmailSender, such as the outbox or SMTP sender
+type EmailChannel struct {
+	sender email.EmailSender
+}
+
+// NewEmailChannel creates an email channel backed by sender
+func NewEmailChannel(sender email.EmailSender) *EmailChannel {
+	return &EmailChannel{sender: sender}
+}
+
+// Type returns ChannelEmail
+func (c *EmailChannel) Type() ChannelType {
+	return ChannelEmail
+}
+
+// CanDeliver reports whether the recipient has an email address
+func (c *EmailChannel) CanDeliver(recipient Recipient) bool {
+	return recipient.Email != ""
+}
+
+// Send emails the HTML body, tagging it with the message type and headers when the sender supports them
+func (c *EmailChannel) Send(recipient Recipient, msg Message) error {
+	body := msg.HTML
+	if body == "" {
+		body = msg.Text
+	}
+
+	if headerSender, ok := c.sender.(email.HeaderEmailSender); ok && len(msg.Headers) > 0 {
+		return headerSender.SendEmailWithHeaders(recipient.Email, msg.Type, msg.Subject, body, msg.Headers)
+	}
+
+	if typed, ok := c.sender.(email.TypedEmailSender); ok && msg.Type != "" {
+		return typed.SendTypedEmail(recipient.Email, msg.Type, msg.Subject, body)
+	}
+	return c.sender.SendEmail(recipient.Email, msg.Subject, body)
+}
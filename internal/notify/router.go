@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Preferences returns the channels a recipient chose for a notification type.
+// ok is false when the recipient has no preference, in which case the router defaults apply.
+type Preferences interface {
+	ChannelsFor(recipient Recipient, notificationType string) (channels []ChannelType, ok bool, err error)
+}
+
+// Router delivers each notification over the channels selected for the recipient and type
+type Router struct {
+	channels     map[ChannelType]Channel
+	preferences  Preferences
+	defaults     []ChannelType
+	typeDefaults map[string][]ChannelType
+}
+
+// NewRouter creates a router that uses defaults when neither a preference nor a type default applies
+func NewRouter(defaults ...ChannelType) *Router {
+	if len(defaults) == 0 {
+		defaults = []ChannelType{ChannelEmail}
+	}
+	return &Router{
+		channels:     make(map[ChannelType]Channel),
+		defaults:     defaults,
+		typeDefaults: make(map[string][]ChannelType),
+	}
+}
+
+// Register adds a channel, replacing any channel of the same type
+func (r *Router) Register(channel Channel) {
+	r.channels[channel.Type()] = channel
+}
+
+// SetPreferences sets where per-subscriber channel preferences are read from
+func (r *Router) SetPreferences(preferences Preferences) {
+	r.preferences = preferences
+}
+
+// SetTypeDefaults sets the channels used for a notification type when the recipient has no preference
+func (r *Router) SetTypeDefaults(notificationType string, channels ...ChannelType) {
+	r.typeDefaults[notificationType] = channels
+}
+
+// ChannelsFor returns the channels a notification of the given type is sent over
+func (r *Router) ChannelsFor(recipient Recipient, notificationType string) []ChannelType {
+	if r.preferences != nil {
+		channels, ok, err := r.preferences.ChannelsFor(recipient, notificationType)
+		if err != nil {
+			// Fall back to the defaults rather than dropping the notification
+			log.Printf("[Notify] Error loading preferences for %s, using defaults: %v", recipient.Email, err)
+		} else if ok {
+			return channels
+		}
+	}
+
+	if channels, ok := r.typeDefaults[notificationType]; ok {
+		return channels
+	}
+	return r.defaults
+}
+
+// Route sends the message over every selected channel that is registered and can reach the recipient.
+// A failing channel does not stop the others. Once one channel delivered the message the failures
+// are only logged, so a retry does not deliver it again over the channels that succeeded; the
+// joined errors are returned only when no channel delivered it.
+func (r *Router) Route(recipient Recipient, msg Message) error {
+	channels := r.ChannelsFor(recipient, msg.Type)
+	if len(channels) == 0 {
+		log.Printf("[Notify] No channels selected for %s notification to %s, skipping", msg.Type, recipient.Email)
+		return nil
+	}
+
+	var errs []error
+	delivered := 0
+	for _, channelType := range channels {
+		channel, ok := r.channels[channelType]
+		if !ok {
+			log.Printf("[Notify] Channel %s is not configured, skipping %s notification", channelType, msg.Type)
+			continue
+		}
+		if !channel.CanDeliver(recipient) {
+			log.Printf("[Notify] Channel %s cannot reach recipient %d, skipping %s notification", channelType, recipient.SubscriberID, msg.Type)
+			continue
+		}
+
+		if err := channel.Send(recipient, msg); err != nil {
+			log.Printf("[Notify] Failed to send %s notification over %s: %v", msg.Type, channelType, err)
+			errs = append(errs, fmt.Errorf("error sending %s notification over %s: %w", msg.Type, channelType, err))
+			continue
+		}
+		delivered++
+	}
+
+	if delivered > 0 && len(errs) > 0 {
+		log.Printf("[Notify] Delivered %s notification over %d channels, not retrying the %d that failed", msg.Type, delivered, len(errs))
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// SendEmail routes an untyped email, so the router can be used as an email.EmailSender
+func (r *Router) SendEmail(to, subject, body string) error {
+	return r.SendTypedEmail(to, "", subject, body)
+}
+
+// SendTypedEmail routes an email for the recipient address, so the router can be used as an email.TypedEmailSender
+func (r *Router) SendTypedEmail(to, emailType, subject, body string) error {
+	return r.SendEmailWithHeaders(to, emailType, subject, body, nil)
+}
+
+// SendEmailWithHeaders routes an email with extra headers, so the router can be used as an email.HeaderEmailSender
+func (r *Router) SendEmailWithHeaders(to, emailType, subject, body string, headers map[string]string) error {
+	return r.Route(Recipient{Email: to}, Message{
+		Type:    emailType,
+		Subject: subject,
+		HTML:    body,
+		Headers: headers,
+	})
+}
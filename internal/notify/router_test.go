@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChannel struct {
+	channelType ChannelType
+	needsPhone  bool
+	err         error
+	sent        []Message
+}
+
+func (c *fakeChannel) Type() ChannelType { return c.channelType }
+
+func (c *fakeChannel) CanDeliver(recipient Recipient) bool {
+	return !c.needsPhone || recipient.Phone != ""
+}
+
+func (c *fakeChannel) Send(recipient Recipient, msg Message) error {
+	c.sent = append(c.sent, msg)
+	return c.err
+}
+
+type fakePreferences struct {
+	channels []ChannelType
+	ok       bool
+	err      error
+}
+
+func (p *fakePreferences) ChannelsFor(recipient Recipient, notificationType string) ([]ChannelType, bool, error) {
+	return p.channels, p.ok, p.err
+}
+
+func TestRouterUsesDefaultsWithoutPreferences(t *testing.T) {
+	emailChannel := &fakeChannel{channelType: ChannelEmail}
+	webhookChannel := &fakeChannel{channelType: ChannelWebhook}
+
+	router := NewRouter()
+	router.Register(emailChannel)
+	router.Register(webhookChannel)
+
+	err := router.Route(Recipient{Email: "user@example.com"}, Message{Type: "ORDER_CONFIRMED"})
+
+	assert.NoError(t, err)
+	assert.Len(t, emailChannel.sent, 1)
+	assert.Empty(t, webhookChannel.sent)
+}
+
+func TestRouterUsesTypeDefaults(t *testing.T) {
+	emailChannel := &fakeChannel{channelType: ChannelEmail}
+	webhookChannel := &fakeChannel{channelType: ChannelWebhook}
+
+	router := NewRouter()
+	router.Register(emailChannel)
+	router.Register(webhookChannel)
+	router.SetTypeDefaults("ORDER_CONFIRMED", ChannelEmail, ChannelWebhook)
+
+	err := router.Route(Recipient{Email: "user@example.com"}, Message{Type: "ORDER_CONFIRMED"})
+
+	assert.NoError(t, err)
+	assert.Len(t, emailChannel.sent, 1)
+	assert.Len(t, webhookChannel.sent, 1)
+}
+
+func TestRouterPrefersSubscriberPreferences(t *testing.T) {
+	emailChannel := &fakeChannel{channelType: ChannelEmail}
+	smsChannel := &fakeChannel{channelType: ChannelSMS, needsPhone: true}
+
+	router := NewRouter()
+	router.Register(emailChannel)
+	router.Register(smsChannel)
+	router.SetPreferences(&fakePreferences{channels: []ChannelType{ChannelSMS}, ok: true})
+
+	err := router.Route(Recipient{Email: "user@example.com", Phone: "+94770000000"}, Message{Type: "SESSION_REMINDER"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, emailChannel.sent)
+	assert.Len(t, smsChannel.sent, 1)
+}
+
+func TestRouterFallsBackToDefaultsWhenPreferencesFail(t *testing.T) {
+	emailChannel := &fakeChannel{channelType: ChannelEmail}
+
+	router := NewRouter()
+	router.Register(emailChannel)
+	router.SetPreferences(&fakePreferences{err: errors.New("database unavailable")})
+
+	err := router.SendTypedEmail("user@example.com", "ORDER_CONFIRMED", "Subject", "<p>Body</p>")
+
+	assert.NoError(t, err)
+	assert.Len(t, emailChannel.sent, 1)
+	assert.Equal(t, "<p>Body</p>", emailChannel.sent[0].HTML)
+}
+
+func TestRouterSkipsUnreachableAndUnconfiguredChannels(t *testing.T) {
+	smsChannel := &fakeChannel{channelType: ChannelSMS, needsPhone: true}
+
+	router := NewRouter(ChannelSMS, ChannelInApp)
+	router.Register(smsChannel)
+
+	err := router.Route(Recipient{Email: "user@example.com"}, Message{Type: "ORDER_CONFIRMED"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, smsChannel.sent)
+}
+
+func TestRouterIgnoresChannelErrorsOnceAChannelDelivered(t *testing.T) {
+	emailChannel := &fakeChannel{channelType: ChannelEmail}
+	webhookChannel := &fakeChannel{channelType: ChannelWebhook, err: errors.New("webhook down")}
+
+	router := NewRouter(ChannelEmail, ChannelWebhook)
+	router.Register(emailChannel)
+	router.Register(webhookChannel)
+
+	err := router.Route(Recipient{Email: "user@example.com"}, Message{Type: "ORDER_CONFIRMED"})
+
+	assert.NoError(t, err)
+	assert.Len(t, emailChannel.sent, 1)
+}
+
+func TestRouterJoinsChannelErrorsWhenNoChannelDelivered(t *testing.T) {
+	emailChannel := &fakeChannel{channelType: ChannelEmail, err: errors.New("smtp down")}
+	webhookChannel := &fakeChannel{channelType: ChannelWebhook, err: errors.New("webhook down")}
+
+	router := NewRouter(ChannelEmail, ChannelWebhook)
+	router.Register(emailChannel)
+	router.Register(webhookChannel)
+
+	err := router.Route(Recipient{Email: "user@example.com"}, Message{Type: "ORDER_CONFIRMED"})
+
+	assert.ErrorContains(t, err, "smtp down")
+	assert.ErrorContains(t, err, "webhook down")
+	assert.Len(t, webhookChannel.sent, 1)
+}
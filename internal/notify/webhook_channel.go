@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookChannel posts notifications as JSON to a fixed URL, e.g. for chat or CRM integrations
+type WebhookChannel struct {
+	url        string
+	httpClient *http.Client
+}
+
+// webhookPayload is the JSON body posted by WebhookChannel
+type webhookPayload struct {
+	Recipient Recipient `json:"recipient"`
+	Message   Message   `json:"message"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// NewWebhookChannel creates a webhook channel posting to url
+func NewWebhookChannel(url string, httpClient *http.Client) *WebhookChannel {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookChannel{
+		url:        url,
+		httpClient: httpClient,
+	}
+}
+
+// Type returns ChannelWebhook
+func (c *WebhookChannel) Type() ChannelType {
+	return ChannelWebhook
+}
+
+// CanDeliver always returns true; the webhook does not need a recipient address
+func (c *WebhookChannel) CanDeliver(recipient Recipient) bool {
+	return true
+}
+
+// Send posts the notification to the webhook URL
+func (c *WebhookChannel) Send(recipient Recipient, msg Message) error {
+	payload, err := json.Marshal(webhookPayload{
+		Recipient: recipient,
+		Message:   msg,
+		SentAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error calling notification webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("notification webhook returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
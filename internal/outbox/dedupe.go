@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DedupeStore remembers notification fingerprints so the same notification is only sent once
+type DedupeStore struct {
+	DB *sql.DB
+}
+
+// NewDedupeStore creates a dedupe store backed by the notification_dedupe table
+func NewDedupeStore(db *sql.DB) *DedupeStore {
+	return &DedupeStore{DB: db}
+}
+
+// Claim records the fingerprint and reports whether it was new.
+// A false result means the notification was already sent.
+func (d *DedupeStore) Claim(fingerprint, sourceKey, recipient, emailType string) (bool, error) {
+	query := `
+		INSERT INTO notification_dedupe (fingerprint, source_key, recipient, email_type)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (fingerprint) DO NOTHING
+	`
+
+	result, err := d.DB.Exec(query, fingerprint, sourceKey, recipient, emailType)
+	if err != nil {
+		return false, fmt.Errorf("error claiming notification fingerprint: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking notification fingerprint: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// Release forgets a fingerprint so the notification can be retried after a failed send
+func (d *DedupeStore) Release(fingerprint string) error {
+	if _, err := d.DB.Exec(`DELETE FROM notification_dedupe WHERE fingerprint = $1`, fingerprint); err != nil {
+		return fmt.Errorf("error releasing notification fingerprint: %w", err)
+	}
+	return nil
+}
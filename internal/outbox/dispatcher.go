@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ms-scheduling/internal/email"
+)
+
+// Dispatcher drains the outbox and delivers messages through the real email sender.
+// Failed deliveries are retried with exponential backoff until max_attempts is reached,
+// after which the message is marked dead.
+type Dispatcher struct {
+	store        claimStore
+	sender       email.EmailSender
+	batchSize    int
+	pollInterval time.Duration
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+}
+
+// claimStore is the part of the Store the dispatcher delivers from
+type claimStore interface {
+	ClaimDue(limit int, lease time.Duration) ([]Message, error)
+	MarkSent(id int64, claimToken string) error
+	MarkRetry(id int64, claimToken string, sendErr error, delay time.Duration) error
+	MarkDead(id int64, claimToken string, sendErr error) error
+}
+
+// NewDispatcher creates a dispatcher that delivers outbox messages with sender
+func NewDispatcher(store *Store, sender email.EmailSender, batchSize int, pollInterval, baseBackoff, maxBackoff time.Duration) *Dispatcher {
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	if baseBackoff <= 0 {
+		baseBackoff = 30 * time.Second
+	}
+	if maxBackoff < baseBackoff {
+		maxBackoff = baseBackoff
+	}
+	return &Dispatcher{
+		store:        store,
+		sender:       sender,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		baseBackoff:  baseBackoff,
+		maxBackoff:   maxBackoff,
+		lease:        5 * time.Minute,
+	}
+}
+
+// Run polls the outbox until the context is cancelled
+func (d *Dispatcher) Run(ctx context.Context) error {
+	log.Printf("[Outbox] Starting dispatcher (batch size %d, poll interval %s)", d.batchSize, d.pollInterval)
+
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back, then wait for the next tick
+		for {
+			processed, err := d.DispatchOnce()
+			if err != nil {
+				log.Printf("[Outbox] Error dispatching emails: %v", err)
+				break
+			}
+			if processed < d.batchSize {
+				break
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Outbox] Context cancelled, stopping dispatcher")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce delivers one batch of due messages and returns how many were processed
+func (d *Dispatcher) DispatchOnce() (int, error) {
+	messages, err := d.store.ClaimDue(d.batchSize, d.lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, msg := range messages {
+		d.deliver(msg)
+	}
+	return len(messages), nil
+}
+
+// deliver sends a single message and records the outcome
+func (d *Dispatcher) deliver(msg Message) {
+	var sendErr error
+	if headerSender, ok := d.sender.(email.HeaderEmailSender); ok && len(msg.Headers) > 0 {
+		sendErr = headerSender.SendEmailWithHeaders(msg.Recipient, msg.EmailType, msg.Subject, msg.Body, msg.Headers)
+	} else {
+		sendErr = d.sender.SendEmail(msg.Recipient, msg.Subject, msg.Body)
+	}
+	if sendErr == nil {
+		if err := d.store.MarkSent(msg.ID, msg.ClaimToken); err != nil {
+			log.Printf("[Outbox] %v", err)
+		}
+		return
+	}
+
+	attempt := msg.Attempts + 1
+	if attempt >= msg.MaxAttempts {
+		log.Printf("[Outbox] Giving up on %s email %d to %s after %d attempts: %v",
+			msg.EmailType, msg.ID, msg.Recipient, attempt, sendErr)
+		if err := d.store.MarkDead(msg.ID, msg.ClaimToken, sendErr); err != nil {
+			log.Printf("[Outbox] %v", err)
+		}
+		return
+	}
+
+	delay := d.backoff(attempt)
+	log.Printf("[Outbox] Attempt %d/%d for %s email %d to %s failed, retrying in %s: %v",
+		attempt, msg.MaxAttempts, msg.EmailType, msg.ID, msg.Recipient, delay, sendErr)
+	if err := d.store.MarkRetry(msg.ID, msg.ClaimToken, fmt.Errorf("attempt %d: %w", attempt, sendErr), delay); err != nil {
+		log.Printf("[Outbox] %v", err)
+	}
+}
+
+// backoff returns the delay before the next attempt: baseBackoff * 2^(attempt-1), capped at maxBackoff
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.baseBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= d.maxBackoff {
+			return d.maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,154 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory claimStore following the claim rules of the email_outbox queries
+type memoryStore struct {
+	now      time.Time
+	messages map[int64]*Message
+	claims   int
+	retries  []time.Duration
+}
+
+func newMemoryStore(messages ...Message) *memoryStore {
+	s := &memoryStore{now: time.Now(), messages: make(map[int64]*Message)}
+	for i := range messages {
+		msg := messages[i]
+		msg.Status = StatusPending
+		msg.NextAttemptAt = s.now
+		s.messages[msg.ID] = &msg
+	}
+	return s
+}
+
+func (s *memoryStore) ClaimDue(limit int, lease time.Duration) ([]Message, error) {
+	var claimed []Message
+	for id := int64(1); id <= int64(len(s.messages)) && len(claimed) < limit; id++ {
+		msg := s.messages[id]
+		if (msg.Status != StatusPending && msg.Status != StatusSending) || msg.NextAttemptAt.After(s.now) {
+			continue
+		}
+		if msg.Status == StatusSending {
+			msg.Attempts++
+			if msg.Attempts >= msg.MaxAttempts {
+				msg.Status = StatusDead
+				continue
+			}
+		}
+		s.claims++
+		msg.Status = StatusSending
+		msg.NextAttemptAt = s.now.Add(lease)
+		msg.ClaimToken = fmt.Sprintf("claim-%d", s.claims)
+		claimed = append(claimed, *msg)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) mark(id int64, claimToken string, status Status) error {
+	msg := s.messages[id]
+	if msg.ClaimToken != claimToken {
+		return fmt.Errorf("%w: message %d was reclaimed", ErrClaimLost, id)
+	}
+	msg.Status = status
+	msg.Attempts++
+	msg.ClaimToken = ""
+	return nil
+}
+
+func (s *memoryStore) MarkSent(id int64, claimToken string) error {
+	return s.mark(id, claimToken, StatusSent)
+}
+
+func (s *memoryStore) MarkRetry(id int64, claimToken string, sendErr error, delay time.Duration) error {
+	if err := s.mark(id, claimToken, StatusPending); err != nil {
+		return err
+	}
+	s.retries = append(s.retries, delay)
+	s.messages[id].NextAttemptAt = s.now.Add(delay)
+	return nil
+}
+
+func (s *memoryStore) MarkDead(id int64, claimToken string, sendErr error) error {
+	return s.mark(id, claimToken, StatusDead)
+}
+
+// funcSender sends emails through a function
+type funcSender func(to, subject, body string) error
+
+func (f funcSender) SendEmail(to, subject, body string) error {
+	return f(to, subject, body)
+}
+
+func newTestDispatcher(store claimStore, sender funcSender) *Dispatcher {
+	d := NewDispatcher(nil, sender, 10, time.Second, 30*time.Second, 10*time.Minute)
+	d.store = store
+	return d
+}
+
+func TestDispatcherBackoff(t *testing.T) {
+	d := NewDispatcher(nil, nil, 10, time.Second, 30*time.Second, 10*time.Minute)
+
+	assert.Equal(t, 30*time.Second, d.backoff(1))
+	assert.Equal(t, time.Minute, d.backoff(2))
+	assert.Equal(t, 2*time.Minute, d.backoff(3))
+	assert.Equal(t, 8*time.Minute, d.backoff(5))
+	assert.Equal(t, 10*time.Minute, d.backoff(6))
+	assert.Equal(t, 10*time.Minute, d.backoff(50))
+}
+
+func TestDispatcherCannotRecordOutcomeOfReclaimedMessage(t *testing.T) {
+	store := newMemoryStore(Message{ID: 1, Recipient: "a@example.com", MaxAttempts: 5})
+	var reclaimed []Message
+
+	// The send outlasts the lease, so another dispatcher claims the message meanwhile
+	slow := newTestDispatcher(store, func(to, subject, body string) error {
+		store.now = store.now.Add(6 * time.Minute)
+		var err error
+		reclaimed, err = store.ClaimDue(10, 5*time.Minute)
+		return err
+	})
+
+	processed, err := slow.DispatchOnce()
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	require.Len(t, reclaimed, 1)
+
+	msg := store.messages[1]
+	assert.Equal(t, StatusSending, msg.Status)
+	assert.Equal(t, reclaimed[0].ClaimToken, msg.ClaimToken)
+	assert.Equal(t, 1, msg.Attempts, "the expired claim counts as an attempt")
+
+	assert.ErrorIs(t, store.MarkSent(1, "claim-1"), ErrClaimLost)
+	require.NoError(t, store.MarkSent(1, reclaimed[0].ClaimToken))
+	assert.Equal(t, StatusSent, msg.Status)
+}
+
+func TestDispatcherRetriesFailedSendsAfterBackoff(t *testing.T) {
+	store := newMemoryStore(Message{ID: 1, Recipient: "a@example.com", MaxAttempts: 2})
+	d := newTestDispatcher(store, func(to, subject, body string) error {
+		return errors.New("smtp unavailable")
+	})
+
+	_, err := d.DispatchOnce()
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, store.messages[1].Status)
+	assert.Equal(t, []time.Duration{30 * time.Second}, store.retries)
+
+	processed, err := d.DispatchOnce()
+	require.NoError(t, err)
+	assert.Zero(t, processed, "the message is not due before its backoff passed")
+
+	store.now = store.now.Add(30 * time.Second)
+	_, err = d.DispatchOnce()
+	require.NoError(t, err)
+	assert.Equal(t, StatusDead, store.messages[1].Status)
+	assert.Equal(t, 2, store.messages[1].Attempts)
+}
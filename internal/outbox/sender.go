@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"log"
+)
+
+// GenericEmailType is recorded for emails sent without an explicit type
+const GenericEmailType = "GENERIC"
+
+// Sender records emails in the outbox instead of delivering them inline.
+// It satisfies email.EmailSender so it can be handed to EmailManager and SubscriberService.
+type Sender struct {
+	store *Store
+}
+
+// NewSender creates a sender backed by the given outbox store
+func NewSender(store *Store) *Sender {
+	return &Sender{store: store}
+}
+
+// SendEmail enqueues an untyped email
+func (s *Sender) SendEmail(to, subject, body string) error {
+	return s.SendTypedEmail(to, GenericEmailType, subject, body)
+}
+
+// SendTypedEmail enqueues an email tagged with its notification type
+func (s *Sender) SendTypedEmail(to, emailType, subject, body string) error {
+	return s.SendEmailWithHeaders(to, emailType, subject, body, nil)
+}
+
+// SendEmailWithHeaders enqueues a typed email with extra headers, e.g. List-Unsubscribe
+func (s *Sender) SendEmailWithHeaders(to, emailType, subject, body string, headers map[string]string) error {
+	id, err := s.store.Enqueue(to, emailType, subject, body, headers)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Outbox] Queued %s email %d for %s", emailType, id, to)
+	return nil
+}
@@ -0,0 +1,266 @@
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Status represents the delivery state of an outbox message
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSending Status = "sending"
+	StatusSent    Status = "sent"
+	StatusDead    Status = "dead"
+)
+
+// Message is a single outgoing email recorded in the email_outbox table
+type Message struct {
+	ID            int64             `json:"id"`
+	SubscriberID  *int              `json:"subscriber_id,omitempty"`
+	Recipient     string            `json:"recipient"`
+	EmailType     string            `json:"email_type"`
+	Subject       string            `json:"subject"`
+	Body          string            `json:"-"`
+	Headers       map[string]string `json:"-"`
+	Status        Status            `json:"status"`
+	Attempts      int               `json:"attempts"`
+	MaxAttempts   int               `json:"max_attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	LastError     *string           `json:"last_error,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	SentAt        *time.Time        `json:"sent_at,omitempty"`
+	ClaimToken    string            `json:"-"` // set by ClaimDue, required to record the outcome
+}
+
+// ErrClaimLost is returned when recording the outcome of a message whose claim expired and was
+// taken over by another dispatcher, which now owns the message
+var ErrClaimLost = errors.New("outbox message claim lost")
+
+// Store persists outgoing emails so they can be delivered and audited
+type Store struct {
+	DB          *sql.DB
+	maxAttempts int
+}
+
+// NewStore creates an outbox store; maxAttempts is recorded on every new message
+func NewStore(db *sql.DB, maxAttempts int) *Store {
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	return &Store{
+		DB:          db,
+		maxAttempts: maxAttempts,
+	}
+}
+
+const messageColumns = `id, subscriber_id, recipient, email_type, subject, body, headers, status,
+	attempts, max_attempts, next_attempt_at, last_error, created_at, sent_at, COALESCE(claim_token, '')`
+
+// Enqueue records an email for delivery and returns its outbox ID. headers are extra email
+// headers such as List-Unsubscribe and may be nil.
+// The subscriber is resolved from the recipient address so history can be queried per subscriber.
+func (s *Store) Enqueue(recipient, emailType, subject, body string, headers map[string]string) (int64, error) {
+	query := `
+		INSERT INTO email_outbox (subscriber_id, recipient, email_type, subject, body, headers, max_attempts)
+		VALUES ((SELECT subscriber_id FROM subscribers WHERE subscriber_mail = $1 LIMIT 1), $1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var headersJSON []byte
+	if len(headers) > 0 {
+		var err error
+		if headersJSON, err = json.Marshal(headers); err != nil {
+			return 0, fmt.Errorf("error marshalling headers for %s email: %w", emailType, err)
+		}
+	}
+
+	var id int64
+	err := s.DB.QueryRow(query, recipient, emailType, subject, body, headersJSON, s.maxAttempts).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error enqueuing %s email for %s: %w", emailType, recipient, err)
+	}
+	return id, nil
+}
+
+// ClaimDue locks up to limit messages that are ready to be sent and marks them as sending with a
+// new claim token. The claim expires after lease so messages held by a crashed dispatcher are
+// picked up again, and the old dispatcher can no longer record an outcome for them.
+// Reclaiming a message counts as a failed attempt, so a message that keeps crashing the
+// dispatcher ends up dead instead of being retried forever; dead messages are not returned.
+func (s *Store) ClaimDue(limit int, lease time.Duration) ([]Message, error) {
+	query := `
+		UPDATE email_outbox
+		SET status = CASE WHEN status = 'sending' AND attempts + 1 >= max_attempts THEN 'dead' ELSE 'sending' END,
+		    attempts = attempts + CASE WHEN status = 'sending' THEN 1 ELSE 0 END,
+		    last_error = CASE WHEN status = 'sending' THEN 'delivery lease expired before the attempt finished' ELSE last_error END,
+		    next_attempt_at = NOW() + $2 * INTERVAL '1 second',
+		    claim_token = md5(random()::text || clock_timestamp()::text)
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status IN ('pending', 'sending') AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + messageColumns
+
+	rows, err := s.DB.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error claiming outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	claimed, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := claimed[:0]
+	for _, msg := range claimed {
+		if msg.Status == StatusDead {
+			log.Printf("[Outbox] Giving up on %s email %d to %s after %d attempts: delivery lease expired", msg.EmailType, msg.ID, msg.Recipient, msg.Attempts)
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// MarkSent records a successful delivery
+func (s *Store) MarkSent(id int64, claimToken string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'sent', attempts = attempts + 1, sent_at = NOW(), last_error = NULL, claim_token = NULL
+		WHERE id = $1 AND claim_token = $2
+	`
+	result, err := s.DB.Exec(query, id, claimToken)
+	if err != nil {
+		return fmt.Errorf("error marking outbox message %d as sent: %w", id, err)
+	}
+	return claimHeld(result, id)
+}
+
+// MarkRetry records a failed attempt and makes the message due again after delay
+func (s *Store) MarkRetry(id int64, claimToken string, sendErr error, delay time.Duration) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'pending', attempts = attempts + 1, last_error = $3,
+		    next_attempt_at = NOW() + $4 * INTERVAL '1 second', claim_token = NULL
+		WHERE id = $1 AND claim_token = $2
+	`
+	result, err := s.DB.Exec(query, id, claimToken, sendErr.Error(), delay.Seconds())
+	if err != nil {
+		return fmt.Errorf("error scheduling retry for outbox message %d: %w", id, err)
+	}
+	return claimHeld(result, id)
+}
+
+// MarkDead records a final failed attempt; the message will not be retried
+func (s *Store) MarkDead(id int64, claimToken string, sendErr error) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'dead', attempts = attempts + 1, last_error = $3, claim_token = NULL
+		WHERE id = $1 AND claim_token = $2
+	`
+	result, err := s.DB.Exec(query, id, claimToken, sendErr.Error())
+	if err != nil {
+		return fmt.Errorf("error marking outbox message %d as dead: %w", id, err)
+	}
+	return claimHeld(result, id)
+}
+
+// claimHeld returns ErrClaimLost when a mark matched no message because its claim token changed
+func claimHeld(result sql.Result, id int64) error {
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking update of outbox message %d: %w", id, err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: message %d was reclaimed", ErrClaimLost, id)
+	}
+	return nil
+}
+
+// GetSubscriberHistory returns the most recent emails recorded for a subscriber
+func (s *Store) GetSubscriberHistory(subscriberID int, limit int) ([]Message, error) {
+	query := `SELECT ` + messageColumns + `
+		FROM email_outbox
+		WHERE subscriber_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := s.DB.Query(query, subscriberID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying email history for subscriber %d: %w", subscriberID, err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// GetTypeHistory returns the most recent emails recorded for a notification type
+func (s *Store) GetTypeHistory(emailType string, limit int) ([]Message, error) {
+	query := `SELECT ` + messageColumns + `
+		FROM email_outbox
+		WHERE email_type = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := s.DB.Query(query, emailType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying email history for type %s: %w", emailType, err)
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+func scanMessages(rows *sql.Rows) ([]Message, error) {
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		var status string
+		var headers []byte
+		err := rows.Scan(
+			&msg.ID,
+			&msg.SubscriberID,
+			&msg.Recipient,
+			&msg.EmailType,
+			&msg.Subject,
+			&msg.Body,
+			&headers,
+			&status,
+			&msg.Attempts,
+			&msg.MaxAttempts,
+			&msg.NextAttemptAt,
+			&msg.LastError,
+			&msg.CreatedAt,
+			&msg.SentAt,
+			&msg.ClaimToken,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning outbox message: %w", err)
+		}
+		msg.Status = Status(status)
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &msg.Headers); err != nil {
+				return nil, fmt.Errorf("error decoding headers of outbox message %d: %w", msg.ID, err)
+			}
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,198 @@
+package preferences
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/notify"
+)
+
+// AllTypes is the email_type used for a preference that covers a whole category
+const AllTypes = "*"
+
+// Preference is a subscriber's choice for one notification category or email type
+type Preference struct {
+	SubscriberID int                  `json:"subscriberId"`
+	Category     email.EmailCategory  `json:"category"`
+	EmailType    string               `json:"emailType"`
+	Enabled      bool                 `json:"enabled"`
+	Channels     []notify.ChannelType `json:"channels"`
+	UpdatedAt    time.Time            `json:"updatedAt"`
+}
+
+// CategoryOf returns the category of an email type string, e.g. SESSION for SESSION_START_REMINDER
+func CategoryOf(emailType string) email.EmailCategory {
+	category, _, _ := strings.Cut(emailType, "_")
+	return email.EmailCategory(category)
+}
+
+// Validate normalizes a preference and checks that its category, email type and channels are known
+func (p *Preference) Validate() error {
+	p.Category = email.EmailCategory(strings.ToUpper(string(p.Category)))
+	p.EmailType = strings.ToUpper(strings.TrimSpace(p.EmailType))
+	if p.EmailType == "" {
+		p.EmailType = AllTypes
+	}
+
+	switch p.Category {
+	case email.CategorySession, email.CategoryEvent, email.CategoryOrganization, email.CategoryPayment, email.CategoryOrder, email.CategoryWaitlist:
+	default:
+		return fmt.Errorf("unknown category %q", p.Category)
+	}
+
+	if p.EmailType != AllTypes && CategoryOf(p.EmailType) != p.Category {
+		return fmt.Errorf("email type %q does not belong to category %s", p.EmailType, p.Category)
+	}
+
+	if p.Enabled && len(p.Channels) == 0 {
+		p.Channels = []notify.ChannelType{notify.ChannelEmail}
+	}
+	for i, channel := range p.Channels {
+		channel = notify.ChannelType(strings.ToUpper(string(channel)))
+		switch channel {
+		case notify.ChannelEmail, notify.ChannelSMS, notify.ChannelWebPush, notify.ChannelWebhook, notify.ChannelInApp:
+			p.Channels[i] = channel
+		default:
+			return fmt.Errorf("unknown channel %q", channel)
+		}
+	}
+
+	return nil
+}
+
+// Store persists notification preferences in the notification_preferences table
+type Store struct {
+	DB *sql.DB
+}
+
+// NewStore creates a preference store
+func NewStore(db *sql.DB) *Store {
+	return &Store{DB: db}
+}
+
+// List returns all preferences of a subscriber
+func (s *Store) List(subscriberID int) ([]Preference, error) {
+	query := `
+		SELECT subscriber_id, category, email_type, enabled, channels, updated_at
+		FROM notification_preferences
+		WHERE subscriber_id = $1
+		ORDER BY category, email_type
+	`
+
+	rows, err := s.DB.Query(query, subscriberID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying preferences for subscriber %d: %w", subscriberID, err)
+	}
+	defer rows.Close()
+
+	preferences := []Preference{}
+	for rows.Next() {
+		p, err := scanPreference(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning preference: %w", err)
+		}
+		preferences = append(preferences, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating preferences: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// Save creates or replaces a preference
+func (s *Store) Save(p Preference) (Preference, error) {
+	query := `
+		INSERT INTO notification_preferences (subscriber_id, category, email_type, enabled, channels)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subscriber_id, category, email_type)
+		DO UPDATE SET enabled = EXCLUDED.enabled, channels = EXCLUDED.channels, updated_at = NOW()
+		RETURNING subscriber_id, category, email_type, enabled, channels, updated_at
+	`
+
+	saved, err := scanPreference(s.DB.QueryRow(query, p.SubscriberID, string(p.Category), p.EmailType, p.Enabled, channelArray(p.Channels)))
+	if err != nil {
+		return Preference{}, fmt.Errorf("error saving preference for subscriber %d: %w", p.SubscriberID, err)
+	}
+	return saved, nil
+}
+
+// Delete removes a preference so the defaults apply again
+func (s *Store) Delete(subscriberID int, category email.EmailCategory, emailType string) (bool, error) {
+	query := `DELETE FROM notification_preferences WHERE subscriber_id = $1 AND category = $2 AND email_type = $3`
+
+	result, err := s.DB.Exec(query, subscriberID, string(category), emailType)
+	if err != nil {
+		return false, fmt.Errorf("error deleting preference for subscriber %d: %w", subscriberID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking deleted preference: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// ChannelsFor implements notify.Preferences. A preference for the exact email type wins over one
+// for its whole category; a disabled preference returns no channels.
+func (s *Store) ChannelsFor(recipient notify.Recipient, notificationType string) ([]notify.ChannelType, bool, error) {
+	if notificationType == "" {
+		return nil, false, nil
+	}
+
+	query := `
+		SELECT p.subscriber_id, p.category, p.email_type, p.enabled, p.channels, p.updated_at
+		FROM notification_preferences p
+		JOIN subscribers s ON s.subscriber_id = p.subscriber_id
+		WHERE (s.subscriber_id = $1 OR s.subscriber_mail = $2)
+		AND p.category = $3
+		AND p.email_type IN ($4, '*')
+		ORDER BY p.email_type = '*'
+		LIMIT 1
+	`
+
+	p, err := scanPreference(s.DB.QueryRow(query, recipient.SubscriberID, recipient.Email,
+		string(CategoryOf(notificationType)), notificationType))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error loading %s preference for %s: %w", notificationType, recipient.Email, err)
+	}
+
+	if !p.Enabled {
+		return []notify.ChannelType{}, true, nil
+	}
+	return p.Channels, true, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPreference(row scanner) (Preference, error) {
+	var p Preference
+	var category string
+	var channels []string
+	if err := row.Scan(&p.SubscriberID, &category, &p.EmailType, &p.Enabled, pq.Array(&channels), &p.UpdatedAt); err != nil {
+		return Preference{}, err
+	}
+	p.Category = email.EmailCategory(category)
+	p.Channels = notify.ParseChannelTypes(channels)
+	return p, nil
+}
+
+func channelArray(channels []notify.ChannelType) interface{} {
+	values := make([]string, len(channels))
+	for i, channel := range channels {
+		values[i] = string(channel)
+	}
+	return pq.Array(values)
+}
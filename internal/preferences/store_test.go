@@ -0,0 +1,44 @@
+package preferences
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/notify"
+)
+
+func TestCategoryOf(t *testing.T) {
+	assert.Equal(t, email.CategorySession, CategoryOf("SESSION_START_REMINDER"))
+	assert.Equal(t, email.CategoryOrder, CategoryOf("ORDER_CONFIRMED"))
+	assert.Equal(t, email.EmailCategory("GENERIC"), CategoryOf("GENERIC"))
+}
+
+func TestValidateNormalizesPreference(t *testing.T) {
+	p := Preference{Category: "session", EmailType: "session_sales_reminder", Enabled: true, Channels: []notify.ChannelType{"email", "web_push"}}
+
+	assert.NoError(t, p.Validate())
+	assert.Equal(t, email.CategorySession, p.Category)
+	assert.Equal(t, "SESSION_SALES_REMINDER", p.EmailType)
+	assert.Equal(t, []notify.ChannelType{notify.ChannelEmail, notify.ChannelWebPush}, p.Channels)
+}
+
+func TestValidateDefaultsToWholeCategoryAndEmail(t *testing.T) {
+	p := Preference{Category: email.CategoryEvent, Enabled: true}
+
+	assert.NoError(t, p.Validate())
+	assert.Equal(t, AllTypes, p.EmailType)
+	assert.Equal(t, []notify.ChannelType{notify.ChannelEmail}, p.Channels)
+}
+
+func TestValidateRejectsInvalidPreferences(t *testing.T) {
+	unknownCategory := Preference{Category: "NEWSLETTER"}
+	assert.Error(t, unknownCategory.Validate())
+
+	mismatchedType := Preference{Category: email.CategoryEvent, EmailType: "SESSION_UPDATE"}
+	assert.Error(t, mismatchedType.Validate())
+
+	unknownChannel := Preference{Category: email.CategoryOrder, Enabled: true, Channels: []notify.ChannelType{"FAX"}}
+	assert.Error(t, unknownChannel.Validate())
+}
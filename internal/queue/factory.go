@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Queue backends selected with QUEUE_BACKEND
+const (
+	BackendSQS      = "sqs"
+	BackendPostgres = "postgres"
+	BackendMemory   = "memory"
+)
+
+// Factory creates the queues of the configured backend. Postgres and in-memory queues are
+// identified by name; SQS queues by their URL.
+type Factory struct {
+	backend      string
+	sqsClient    SQSClient
+	db           *sql.DB
+	pollInterval time.Duration
+
+	mu     sync.Mutex
+	memory map[string]*MemoryQueue
+}
+
+// NewFactory creates a factory for backend, which is sqs, postgres or memory
+func NewFactory(backend string, sqsClient SQSClient, db *sql.DB, pollInterval time.Duration) (*Factory, error) {
+	switch backend {
+	case BackendSQS, BackendPostgres, BackendMemory:
+	default:
+		return nil, fmt.Errorf("unknown queue backend %q, expected sqs, postgres or memory", backend)
+	}
+
+	return &Factory{
+		backend:      backend,
+		sqsClient:    sqsClient,
+		db:           db,
+		pollInterval: pollInterval,
+		memory:       make(map[string]*MemoryQueue),
+	}, nil
+}
+
+// Backend returns the configured backend
+func (f *Factory) Backend() string {
+	return f.backend
+}
+
+// Queue returns the queue with the given name, or the SQS queue at queueURL. It returns nil
+// on the SQS backend when queueURL is empty, as the queue is not configured.
+// Every call for the same name returns the same in-memory queue, so producers and consumers meet.
+func (f *Factory) Queue(name, queueURL string) Queue {
+	switch f.backend {
+	case BackendSQS:
+		if queueURL == "" {
+			return nil
+		}
+		return NewSQSQueue(f.sqsClient, queueURL)
+	case BackendPostgres:
+		return NewPostgresQueue(f.db, name, f.pollInterval)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if q, ok := f.memory[name]; ok {
+		return q
+	}
+	q := NewMemoryQueue(name)
+	f.memory[name] = q
+	return q
+}
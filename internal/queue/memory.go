@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryPollInterval is how often an empty in-memory queue is checked while a receive waits
+const memoryPollInterval = 20 * time.Millisecond
+
+// MemoryQueue is a Queue held in process memory, for tests and local development.
+// Its messages are lost when the process stops.
+type MemoryQueue struct {
+	name     string
+	mu       sync.Mutex
+	messages []*memoryMessage
+	nextID   int
+}
+
+type memoryMessage struct {
+	Message
+	visibleAt time.Time
+}
+
+// NewMemoryQueue creates an empty in-memory queue
+func NewMemoryQueue(name string) *MemoryQueue {
+	return &MemoryQueue{name: name}
+}
+
+// Name returns the name of the queue
+func (q *MemoryQueue) Name() string {
+	return q.name
+}
+
+// Receive returns the oldest visible messages, polling until WaitTime has passed if there are none.
+// Without a visibility timeout the messages are only listed and stay as they are.
+func (q *MemoryQueue) Receive(ctx context.Context, opts ReceiveOptions) ([]Message, error) {
+	deadline := time.Now().Add(opts.WaitTime)
+	for {
+		if messages := q.receiveVisible(opts); len(messages) > 0 || !time.Now().Before(deadline) {
+			return messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(memoryPollInterval):
+		}
+	}
+}
+
+func (q *MemoryQueue) receiveVisible(opts ReceiveOptions) []Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var received []Message
+	for _, message := range q.messages {
+		if len(received) == batchSize(opts) {
+			break
+		}
+		if message.visibleAt.After(now) {
+			continue
+		}
+		if opts.VisibilityTimeout <= 0 {
+			// Only listing, so the message stays as it is
+			received = append(received, message.Message)
+			continue
+		}
+
+		message.ReceiveCount++
+		message.ReceiptHandle = fmt.Sprintf("%s-%d", message.ID, message.ReceiveCount)
+		message.visibleAt = now.Add(opts.VisibilityTimeout)
+		received = append(received, message.Message)
+	}
+	return received
+}
+
+// Ack deletes the messages; a message received again since is kept
+func (q *MemoryQueue) Ack(ctx context.Context, messages ...Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	acked := make(map[string]bool, len(messages))
+	for _, message := range messages {
+		if message.ReceiptHandle != "" {
+			acked[message.ReceiptHandle] = true
+		}
+	}
+
+	kept := q.messages[:0]
+	for _, message := range q.messages {
+		if !acked[message.ReceiptHandle] {
+			kept = append(kept, message)
+		}
+	}
+	q.messages = kept
+	return nil
+}
+
+// ChangeVisibility makes a received message visible again after timeout
+func (q *MemoryQueue) ChangeVisibility(ctx context.Context, message Message, timeout time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, queued := range q.messages {
+		if queued.ReceiptHandle == message.ReceiptHandle {
+			queued.visibleAt = time.Now().Add(timeout)
+			return nil
+		}
+	}
+	return fmt.Errorf("message %s is not in flight on %s", message.ID, q.name)
+}
+
+// Send appends a message to the queue
+func (q *MemoryQueue) Send(ctx context.Context, body string, attributes map[string]string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	q.messages = append(q.messages, &memoryMessage{
+		Message: Message{
+			ID:         strconv.Itoa(q.nextID),
+			Body:       body,
+			Attributes: attributes,
+		},
+	})
+	return nil
+}
+
+// Len returns the number of messages in the queue, visible or not
+func (q *MemoryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages)
+}
@@ -0,0 +1,80 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryQueueHidesReceivedMessagesUntilTheirVisibilityTimeout(t *testing.T) {
+	q := NewMemoryQueue("test")
+	require.NoError(t, q.Send(context.Background(), `{"n":1}`, map[string]string{"source": "test"}))
+
+	messages, err := q.Receive(context.Background(), ReceiveOptions{VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, `{"n":1}`, messages[0].Body)
+	assert.Equal(t, "test", messages[0].Attributes["source"])
+	assert.Equal(t, 1, messages[0].ReceiveCount)
+
+	hidden, err := q.Receive(context.Background(), ReceiveOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, hidden)
+
+	require.NoError(t, q.ChangeVisibility(context.Background(), messages[0], 0))
+	again, err := q.Receive(context.Background(), ReceiveOptions{VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, 2, again[0].ReceiveCount)
+
+	// The first receipt is stale, so acking it keeps the message
+	require.NoError(t, q.Ack(context.Background(), messages[0]))
+	assert.Equal(t, 1, q.Len())
+	require.NoError(t, q.Ack(context.Background(), again[0]))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestMemoryQueueReceiveWithoutVisibilityTimeoutOnlyListsMessages(t *testing.T) {
+	q := NewMemoryQueue("test")
+	require.NoError(t, q.Send(context.Background(), `{"n":1}`, nil))
+
+	listed, err := q.Receive(context.Background(), ReceiveOptions{})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, 0, listed[0].ReceiveCount)
+
+	messages, err := q.Receive(context.Background(), ReceiveOptions{VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, 1, messages[0].ReceiveCount)
+}
+
+func TestMemoryQueueReceiveWaitsForMessages(t *testing.T) {
+	q := NewMemoryQueue("test")
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		q.Send(context.Background(), "late", nil)
+	}()
+
+	messages, err := q.Receive(context.Background(), ReceiveOptions{WaitTime: time.Second, VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "late", messages[0].Body)
+}
+
+func TestFactoryReturnsConfiguredQueues(t *testing.T) {
+	_, err := NewFactory("kafka", nil, nil, 0)
+	assert.Error(t, err)
+
+	sqsFactory, err := NewFactory(BackendSQS, nil, nil, 0)
+	require.NoError(t, err)
+	assert.Nil(t, sqsFactory.Queue("trending", ""))
+	assert.Equal(t, "http://localhost:4566/queue/trending", sqsFactory.Queue("trending", "http://localhost:4566/queue/trending").Name())
+
+	memoryFactory, err := NewFactory(BackendMemory, nil, nil, 0)
+	require.NoError(t, err)
+	assert.Same(t, memoryFactory.Queue("trending", ""), memoryFactory.Queue("trending", ""))
+}
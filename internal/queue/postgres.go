@@ -0,0 +1,169 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PostgresQueue is a Queue stored in the queue_messages table. Receives claim rows with
+// FOR UPDATE SKIP LOCKED, so several instances can share a queue.
+type PostgresQueue struct {
+	DB           *sql.DB
+	name         string
+	pollInterval time.Duration
+}
+
+// NewPostgresQueue creates the queue with the given name; pollInterval is how often an empty
+// queue is checked while a receive waits
+func NewPostgresQueue(db *sql.DB, name string, pollInterval time.Duration) *PostgresQueue {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &PostgresQueue{
+		DB:           db,
+		name:         name,
+		pollInterval: pollInterval,
+	}
+}
+
+// Name returns the name of the queue
+func (q *PostgresQueue) Name() string {
+	return q.name
+}
+
+// Receive claims the oldest visible messages, polling until WaitTime has passed if there are none.
+// Without a visibility timeout the messages are only listed and stay as they are.
+func (q *PostgresQueue) Receive(ctx context.Context, opts ReceiveOptions) ([]Message, error) {
+	receive := q.claim
+	if opts.VisibilityTimeout <= 0 {
+		receive = q.peek
+	}
+
+	deadline := time.Now().Add(opts.WaitTime)
+	for {
+		messages, err := receive(ctx, opts)
+		if err != nil || len(messages) > 0 || !time.Now().Before(deadline) {
+			return messages, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+// claim hides up to MaxMessages visible messages for the visibility timeout and gives each a new receipt handle.
+// visible_at is computed by the database, so instances with skewed clocks agree on it.
+func (q *PostgresQueue) claim(ctx context.Context, opts ReceiveOptions) ([]Message, error) {
+	query := `
+		UPDATE queue_messages
+		SET receive_count = receive_count + 1,
+			visible_at = NOW() + $3 * INTERVAL '1 second',
+			receipt_handle = md5(random()::text || clock_timestamp()::text)
+		WHERE id IN (
+			SELECT id
+			FROM queue_messages
+			WHERE queue = $1 AND visible_at <= NOW()
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, body, attributes, receive_count, receipt_handle
+	`
+	rows, err := q.DB.QueryContext(ctx, query, q.name, batchSize(opts), opts.VisibilityTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("error receiving messages from queue %s: %w", q.name, err)
+	}
+	defer rows.Close()
+
+	return q.scanMessages(rows)
+}
+
+// peek lists up to MaxMessages visible messages without changing their receive count or receipt handle
+func (q *PostgresQueue) peek(ctx context.Context, opts ReceiveOptions) ([]Message, error) {
+	query := `
+		SELECT id, body, attributes, receive_count, COALESCE(receipt_handle, '')
+		FROM queue_messages
+		WHERE queue = $1 AND visible_at <= NOW()
+		ORDER BY id
+		LIMIT $2
+	`
+	rows, err := q.DB.QueryContext(ctx, query, q.name, batchSize(opts))
+	if err != nil {
+		return nil, fmt.Errorf("error listing messages of queue %s: %w", q.name, err)
+	}
+	defer rows.Close()
+
+	return q.scanMessages(rows)
+}
+
+func (q *PostgresQueue) scanMessages(rows *sql.Rows) ([]Message, error) {
+	var messages []Message
+	for rows.Next() {
+		var message Message
+		var id int64
+		var attributes []byte
+		if err := rows.Scan(&id, &message.Body, &attributes, &message.ReceiveCount, &message.ReceiptHandle); err != nil {
+			return nil, fmt.Errorf("error scanning message of queue %s: %w", q.name, err)
+		}
+		message.ID = strconv.FormatInt(id, 10)
+		if len(attributes) > 0 {
+			if err := json.Unmarshal(attributes, &message.Attributes); err != nil {
+				return nil, fmt.Errorf("error decoding attributes of message %s: %w", message.ID, err)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// Ack deletes the messages; a message received again since is kept
+func (q *PostgresQueue) Ack(ctx context.Context, messages ...Message) error {
+	for _, message := range messages {
+		_, err := q.DB.ExecContext(ctx,
+			`DELETE FROM queue_messages WHERE id = $1 AND receipt_handle = $2`,
+			message.ID, message.ReceiptHandle)
+		if err != nil {
+			return fmt.Errorf("error deleting message %s from queue %s: %w", message.ID, q.name, err)
+		}
+	}
+	return nil
+}
+
+// ChangeVisibility makes a received message visible again after timeout
+func (q *PostgresQueue) ChangeVisibility(ctx context.Context, message Message, timeout time.Duration) error {
+	_, err := q.DB.ExecContext(ctx,
+		`UPDATE queue_messages SET visible_at = NOW() + $3 * INTERVAL '1 second' WHERE id = $1 AND receipt_handle = $2`,
+		message.ID, message.ReceiptHandle, timeout.Seconds())
+	if err != nil {
+		return fmt.Errorf("error changing visibility of message %s on queue %s: %w", message.ID, q.name, err)
+	}
+	return nil
+}
+
+// Send inserts a message that is visible right away
+func (q *PostgresQueue) Send(ctx context.Context, body string, attributes map[string]string) error {
+	// lib/pq sends a []byte as bytea, so the JSON goes as text
+	var encoded interface{}
+	if len(attributes) > 0 {
+		attributesJSON, err := json.Marshal(attributes)
+		if err != nil {
+			return fmt.Errorf("error encoding message attributes: %w", err)
+		}
+		encoded = string(attributesJSON)
+	}
+
+	_, err := q.DB.ExecContext(ctx,
+		`INSERT INTO queue_messages (queue, body, attributes) VALUES ($1, $2, $3)`,
+		q.name, body, encoded)
+	if err != nil {
+		return fmt.Errorf("error sending message to queue %s: %w", q.name, err)
+	}
+	return nil
+}
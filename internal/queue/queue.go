@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// Message is one delivery of a queued message
+type Message struct {
+	ID         string
+	Body       string
+	Attributes map[string]string
+	// ReceiveCount is how often the message was delivered, counting this delivery
+	ReceiveCount int
+	// ReceiptHandle identifies this delivery to Ack and ChangeVisibility; it changes on every receive
+	ReceiptHandle string
+}
+
+// ReceiveOptions controls a single Receive
+type ReceiveOptions struct {
+	// MaxMessages is the most messages returned, at most 10
+	MaxMessages int
+	// WaitTime is how long to wait for a message when the queue is empty
+	WaitTime time.Duration
+	// VisibilityTimeout hides the received messages from other receivers until they are acknowledged
+	// or the timeout expires. Zero leaves them visible, for inspecting a queue; the Postgres and
+	// in-memory queues then only list them, while SQS still counts it as a receive.
+	VisibilityTimeout time.Duration
+}
+
+// Queue is a message queue with SQS semantics: received messages are hidden for a visibility
+// timeout and delivered again unless they are acknowledged before it expires
+type Queue interface {
+	// Name identifies the queue in logs, e.g. its URL
+	Name() string
+	// Receive returns up to MaxMessages visible messages, waiting up to WaitTime for the first one
+	Receive(ctx context.Context, opts ReceiveOptions) ([]Message, error)
+	// Ack deletes handled messages from the queue
+	Ack(ctx context.Context, messages ...Message) error
+	// ChangeVisibility makes a received message visible again after timeout, e.g. to renew or shorten it
+	ChangeVisibility(ctx context.Context, message Message, timeout time.Duration) error
+	// Send adds a message to the queue
+	Send(ctx context.Context, body string, attributes map[string]string) error
+}
+
+// maxReceiveBatch is the most messages returned by a receive, as on SQS
+const maxReceiveBatch = 10
+
+// batchSize returns the number of messages to receive for opts
+func batchSize(opts ReceiveOptions) int {
+	if opts.MaxMessages < 1 || opts.MaxMessages > maxReceiveBatch {
+		return maxReceiveBatch
+	}
+	return opts.MaxMessages
+}
@@ -0,0 +1,165 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// SQSClient is the part of the SQS API used by SQSQueue, implemented by *sqs.Client
+type SQSClient interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
+// SQSQueue is a Queue backed by an AWS SQS queue
+type SQSQueue struct {
+	client   SQSClient
+	queueURL string
+}
+
+// NewSQSQueue creates a queue for the SQS queue at queueURL
+func NewSQSQueue(client SQSClient, queueURL string) *SQSQueue {
+	return &SQSQueue{
+		client:   client,
+		queueURL: queueURL,
+	}
+}
+
+// Name returns the URL of the queue
+func (q *SQSQueue) Name() string {
+	return q.queueURL
+}
+
+// Receive long-polls the queue, requesting the receive count and message attributes of every message
+func (q *SQSQueue) Receive(ctx context.Context, opts ReceiveOptions) ([]Message, error) {
+	input := &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(q.queueURL),
+		MaxNumberOfMessages: int32(batchSize(opts)),
+		WaitTimeSeconds:     int32(opts.WaitTime / time.Second),
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameApproximateReceiveCount,
+		},
+		MessageAttributeNames: []string{"All"},
+	}
+	if opts.VisibilityTimeout > 0 {
+		input.VisibilityTimeout = int32(opts.VisibilityTimeout / time.Second)
+	}
+
+	result, err := q.client.ReceiveMessage(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive messages from %s: %w", q.queueURL, err)
+	}
+
+	messages := make([]Message, 0, len(result.Messages))
+	for _, raw := range result.Messages {
+		message := fromSQSMessage(raw)
+		if opts.VisibilityTimeout <= 0 {
+			// SQS has no zero timeout on receive, so the messages are made visible again right away
+			if err := q.ChangeVisibility(ctx, message, 0); err != nil {
+				log.Printf("Error making message %s visible again on %s: %v", message.ID, q.queueURL, err)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// Ack deletes the messages in batches of ten
+func (q *SQSQueue) Ack(ctx context.Context, messages ...Message) error {
+	failed := 0
+	for start := 0; start < len(messages); start += maxReceiveBatch {
+		end := start + maxReceiveBatch
+		if end > len(messages) {
+			end = len(messages)
+		}
+
+		entries := make([]types.DeleteMessageBatchRequestEntry, 0, end-start)
+		for i, message := range messages[start:end] {
+			entries = append(entries, types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: aws.String(message.ReceiptHandle),
+			})
+		}
+
+		result, err := q.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: aws.String(q.queueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			return fmt.Errorf("batch delete on %s failed: %w", q.queueURL, err)
+		}
+
+		for _, failure := range result.Failed {
+			log.Printf("Delete failure on %s - ID: %s, Code: %s, Message: %s",
+				q.queueURL, aws.ToString(failure.Id), aws.ToString(failure.Code), aws.ToString(failure.Message))
+		}
+		failed += len(result.Failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d messages could not be deleted from %s", failed, q.queueURL)
+	}
+	return nil
+}
+
+// ChangeVisibility sets the remaining visibility timeout of a received message
+func (q *SQSQueue) ChangeVisibility(ctx context.Context, message Message, timeout time.Duration) error {
+	_, err := q.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(q.queueURL),
+		ReceiptHandle:     aws.String(message.ReceiptHandle),
+		VisibilityTimeout: int32(timeout / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("error changing visibility of message %s on %s: %w", message.ID, q.queueURL, err)
+	}
+	return nil
+}
+
+// Send sends a message, with the attributes as string message attributes
+func (q *SQSQueue) Send(ctx context.Context, body string, attributes map[string]string) error {
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.queueURL),
+		MessageBody: aws.String(body),
+	}
+	if len(attributes) > 0 {
+		input.MessageAttributes = make(map[string]types.MessageAttributeValue, len(attributes))
+		for name, value := range attributes {
+			input.MessageAttributes[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+		}
+	}
+
+	if _, err := q.client.SendMessage(ctx, input); err != nil {
+		return fmt.Errorf("error sending message to %s: %w", q.queueURL, err)
+	}
+	return nil
+}
+
+func fromSQSMessage(raw types.Message) Message {
+	message := Message{
+		ID:            aws.ToString(raw.MessageId),
+		Body:          aws.ToString(raw.Body),
+		ReceiptHandle: aws.ToString(raw.ReceiptHandle),
+		ReceiveCount:  1,
+	}
+
+	if count, err := strconv.Atoi(raw.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)]); err == nil && count > 0 {
+		message.ReceiveCount = count
+	}
+
+	if len(raw.MessageAttributes) > 0 {
+		message.Attributes = make(map[string]string, len(raw.MessageAttributes))
+		for name, value := range raw.MessageAttributes {
+			message.Attributes[name] = aws.ToString(value.StringValue)
+		}
+	}
+	return message
+}
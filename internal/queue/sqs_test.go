@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSQSClient struct {
+	receive    *sqs.ReceiveMessageInput
+	sent       []*sqs.SendMessageInput
+	deleted    [][]types.DeleteMessageBatchRequestEntry
+	visibility []*sqs.ChangeMessageVisibilityInput
+	messages   []types.Message
+}
+
+func (f *fakeSQSClient) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	f.receive = params
+	return &sqs.ReceiveMessageOutput{Messages: f.messages}, nil
+}
+
+func (f *fakeSQSClient) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func (f *fakeSQSClient) DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	f.deleted = append(f.deleted, params.Entries)
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func (f *fakeSQSClient) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	f.visibility = append(f.visibility, params)
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func TestSQSQueueReceiveConvertsMessages(t *testing.T) {
+	client := &fakeSQSClient{messages: []types.Message{{
+		MessageId:         aws.String("m1"),
+		Body:              aws.String(`{"n":1}`),
+		ReceiptHandle:     aws.String("r1"),
+		Attributes:        map[string]string{"ApproximateReceiveCount": "3"},
+		MessageAttributes: map[string]types.MessageAttributeValue{"dlq.error": {DataType: aws.String("String"), StringValue: aws.String("boom")}},
+	}}}
+	q := NewSQSQueue(client, "http://localhost:4566/queue/test")
+
+	messages, err := q.Receive(context.Background(), ReceiveOptions{MaxMessages: 5, WaitTime: 20 * time.Second, VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(5), client.receive.MaxNumberOfMessages)
+	assert.Equal(t, int32(20), client.receive.WaitTimeSeconds)
+	assert.Equal(t, int32(60), client.receive.VisibilityTimeout)
+	assert.Empty(t, client.visibility)
+	require.Len(t, messages, 1)
+	assert.Equal(t, Message{ID: "m1", Body: `{"n":1}`, ReceiptHandle: "r1", ReceiveCount: 3, Attributes: map[string]string{"dlq.error": "boom"}}, messages[0])
+}
+
+func TestSQSQueueReceiveWithoutVisibilityTimeoutLeavesMessagesVisible(t *testing.T) {
+	client := &fakeSQSClient{messages: []types.Message{{MessageId: aws.String("m1"), ReceiptHandle: aws.String("r1")}}}
+	q := NewSQSQueue(client, "http://localhost:4566/queue/test")
+
+	_, err := q.Receive(context.Background(), ReceiveOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, client.visibility, 1)
+	assert.Equal(t, "r1", aws.ToString(client.visibility[0].ReceiptHandle))
+	assert.Equal(t, int32(0), client.visibility[0].VisibilityTimeout)
+}
+
+func TestSQSQueueAckDeletesInBatchesOfTen(t *testing.T) {
+	client := &fakeSQSClient{}
+	q := NewSQSQueue(client, "http://localhost:4566/queue/test")
+
+	messages := make([]Message, 12)
+	for i := range messages {
+		messages[i] = Message{ReceiptHandle: "r"}
+	}
+	require.NoError(t, q.Ack(context.Background(), messages...))
+
+	require.Len(t, client.deleted, 2)
+	assert.Len(t, client.deleted[0], 10)
+	assert.Len(t, client.deleted[1], 2)
+}
@@ -9,116 +9,56 @@ import (
 	"log"
 	"ms-scheduling/internal/config"
 	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/queue"
+	"ms-scheduling/internal/schedule"
 	"ms-scheduling/internal/services"
-	"ms-scheduling/internal/sqsutil"
+	"ms-scheduling/internal/sqsworker"
 	"net/http"
-	"time"
-
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 // Processor handles processing of reminder messages from SQS
 type Processor struct {
-	sqsClient         *sqs.Client
+	queue             queue.Queue
 	httpClient        *http.Client
 	cfg               config.Config
-	queueURL          string
 	subscriberService *services.SubscriberService
 }
 
 var errResourceNotFound = errors.New("resource not found")
 
 // NewProcessor creates a new reminder processor
-func NewProcessor(sqsClient *sqs.Client, httpClient *http.Client, cfg config.Config, subscriberService *services.SubscriberService) *Processor {
+func NewProcessor(q queue.Queue, httpClient *http.Client, cfg config.Config, subscriberService *services.SubscriberService) *Processor {
 	return &Processor{
-		sqsClient:         sqsClient,
+		queue:             q,
 		httpClient:        httpClient,
 		cfg:               cfg,
-		queueURL:          cfg.SQSSessionRemindersQueueURL,
 		subscriberService: subscriberService,
 	}
 }
 
-// ProcessMessages processes messages from the reminder queue
+// ProcessMessages processes messages from the reminder queue until the context is cancelled
 func (p *Processor) ProcessMessages(ctx context.Context) error {
-	if p.queueURL == "" {
-		log.Println("Reminder queue URL not configured, skipping reminder processor")
-		return fmt.Errorf("reminder queue URL not configured")
-	}
-
-	log.Printf("Starting to process reminder messages from %s", p.queueURL)
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, stopping reminder processor")
-			return ctx.Err()
-		default:
-			// Continue processing
-		}
+	worker := sqsworker.New(p.queue, sqsworker.Options{
+		Name:              "reminder",
+		Workers:           p.cfg.SQSRemindersWorkers,
+		VisibilityTimeout: p.cfg.SQSVisibilityTimeout,
+	}, p.handleMessage)
 
-		// Receive messages from reminder queue
-		rawMessages, err := sqsutil.ReceiveMessage(p.sqsClient, p.queueURL)
-		if err != nil {
-			log.Printf("Error receiving messages from reminder SQS queue: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		if len(rawMessages) == 0 {
-			log.Println("No messages received from reminder queue, continuing loop.")
-			continue // No need to sleep, long polling already waited
-		}
-
-		log.Printf("Received %d messages from reminder queue.", len(rawMessages))
-		var messagesToDelete []types.DeleteMessageBatchRequestEntry
-
-		// Process each message in the batch
-		for _, rawMessage := range rawMessages {
-			// Unmarshal and process each message individually
-			var messageBody models.SQSReminderMessageBody
-			if err := json.Unmarshal([]byte(*rawMessage.Body), &messageBody); err != nil {
-				log.Printf("Error unmarshalling reminder message body, will delete malformed message: %v", err)
-				// Add malformed message to the delete batch
-				messagesToDelete = append(messagesToDelete, types.DeleteMessageBatchRequestEntry{
-					Id:            rawMessage.MessageId,
-					ReceiptHandle: rawMessage.ReceiptHandle,
-				})
-				continue
-			}
+	return worker.Run(ctx)
+}
 
-			log.Printf("Processing SQS message from reminder queue: %+v", messageBody)
-
-			// Process the reminder message
-			err = p.processReminderMessage(&messageBody)
-			if err != nil {
-				log.Printf("Error processing reminder for session %s, it will be retried: %v",
-					messageBody.SessionID, err)
-				// If processing fails, DO NOT add it to the delete batch.
-				// It will become visible again on the queue for another attempt.
-			} else {
-				log.Printf("Successfully processed reminder message for session %s, adding to delete batch.", messageBody.SessionID)
-				// On success, add the message to our list of messages to delete.
-				messagesToDelete = append(messagesToDelete, types.DeleteMessageBatchRequestEntry{
-					Id:            rawMessage.MessageId,
-					ReceiptHandle: rawMessage.ReceiptHandle,
-				})
-			}
-		}
+// handleMessage processes a reminder message received from the queue
+func (p *Processor) handleMessage(ctx context.Context, messageBody models.SQSReminderMessageBody) error {
+	log.Printf("Processing SQS message from reminder queue: %+v", messageBody)
 
-		// After processing the whole batch, delete the successful ones in a single API call
-		if len(messagesToDelete) > 0 {
-			err := sqsutil.DeleteMessageBatch(p.queueURL, p.sqsClient, messagesToDelete)
-			if err != nil {
-				log.Printf("Error batch deleting reminder messages: %v", err)
-			}
-		}
+	if err := p.processReminderMessage(ctx, &messageBody); err != nil {
+		return fmt.Errorf("error processing reminder for session %s: %w", messageBody.SessionID, err)
 	}
+	return nil
 }
 
 // processReminderMessage handles sending emails for session reminders
-func (p *Processor) processReminderMessage(msg *models.SQSReminderMessageBody) error {
+func (p *Processor) processReminderMessage(ctx context.Context, msg *models.SQSReminderMessageBody) error {
 	// Validate message basics
 	if msg.SessionID == "" {
 		log.Printf("Reminder message has empty SessionID, skipping: %+v", msg)
@@ -128,16 +68,23 @@ func (p *Processor) processReminderMessage(msg *models.SQSReminderMessageBody) e
 	log.Printf("Processing reminder email for session %s (type: %s, template: %s, notification ID: %s)",
 		msg.SessionID, msg.ReminderType, msg.TemplateID, msg.NotificationID)
 
-	// Handle based solely on ReminderType
+	// The reminder type decides who is emailed, the template which email they get
 	switch msg.ReminderType {
 	case "SESSION_START":
-		return p.handleReminder(msg.SessionID, func(subscribers []models.Subscriber, info *services.SessionReminderInfo) error {
-			return p.subscriberService.SendSessionStartReminderEmails(subscribers, info)
-		})
+		return p.handleReminder(ctx, msg.SessionID, true, p.sendTemplate(msg, schedule.StartReminderTemplate))
 
 	case "SALE_START":
-		return p.handleReminder(msg.SessionID, func(subscribers []models.Subscriber, info *services.SessionReminderInfo) error {
-			return p.subscriberService.SendSessionSalesReminderEmails(subscribers, info)
+		return p.handleReminder(ctx, msg.SessionID, true, p.sendTemplate(msg, schedule.SaleReminderTemplate))
+
+	case "SESSION_ENDED":
+		// Only ticket holders of the session are asked for feedback, not everyone following the event
+		send := p.sendTemplate(msg, schedule.FollowUpTemplate)
+		return p.handleReminder(ctx, msg.SessionID, false, func(subscribers []models.Subscriber, info *services.SessionReminderInfo) error {
+			if info.Status == "CANCELLED" {
+				log.Printf("Session %s was cancelled, skipping follow-up emails", msg.SessionID)
+				return nil
+			}
+			return send(subscribers, info)
 		})
 	default:
 		// For unknown reminder types, log and delete from queue (return nil)
@@ -146,8 +93,49 @@ func (p *Processor) processReminderMessage(msg *models.SQSReminderMessageBody) e
 	}
 }
 
-func (p *Processor) handleReminder(sessionID string, send func([]models.Subscriber, *services.SessionReminderInfo) error) error {
-	subscribers, sessionInfo, err := p.prepareSessionReminderData(sessionID)
+// sendTemplate returns the sender of the email the message's template selects
+func (p *Processor) sendTemplate(msg *models.SQSReminderMessageBody, typeTemplate string) func([]models.Subscriber, *services.SessionReminderInfo) error {
+	templateID := reminderTemplate(msg, typeTemplate)
+	return func(subscribers []models.Subscriber, info *services.SessionReminderInfo) error {
+		source := reminderSource(msg, info)
+		switch templateID {
+		case schedule.SaleReminderTemplate:
+			return p.subscriberService.SendSessionSalesReminderEmails(subscribers, info, source)
+		case schedule.FollowUpTemplate:
+			return p.subscriberService.SendSessionFollowUpEmails(subscribers, info, source)
+		default:
+			return p.subscriberService.SendSessionStartReminderEmails(subscribers, info, source)
+		}
+	}
+}
+
+// reminderTemplate returns the template ID of the message. Messages without one, with the default
+// template or with a template this version does not know get typeTemplate, the email of their
+// reminder type.
+func reminderTemplate(msg *models.SQSReminderMessageBody, typeTemplate string) string {
+	switch msg.TemplateID {
+	case "", schedule.DefaultReminderTemplate:
+		return typeTemplate
+	case schedule.StartReminderTemplate, schedule.SaleReminderTemplate, schedule.FollowUpTemplate:
+		return msg.TemplateID
+	}
+	log.Printf("Unknown template %s for %s reminder of session %s, sending %s", msg.TemplateID, msg.ReminderType, msg.SessionID, typeTemplate)
+	return typeTemplate
+}
+
+// reminderSource identifies a reminder for dedupe. It includes the start and sales start time so the
+// reminder of a rescheduled session is sent again.
+func reminderSource(msg *models.SQSReminderMessageBody, info *services.SessionReminderInfo) string {
+	if msg.NotificationID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d-%d", msg.NotificationID, info.StartTime, info.SalesStartTime)
+}
+
+// handleReminder loads the session and its subscribers and hands them to send. Subscribers of the
+// session's event are included when includeEventSubscribers is set.
+func (p *Processor) handleReminder(ctx context.Context, sessionID string, includeEventSubscribers bool, send func([]models.Subscriber, *services.SessionReminderInfo) error) error {
+	subscribers, sessionInfo, err := p.prepareSessionReminderData(ctx, sessionID, includeEventSubscribers)
 	if err != nil {
 		if errors.Is(err, errResourceNotFound) {
 			log.Printf("Session %s not found. Consuming reminder message without sending emails.", sessionID)
@@ -168,8 +156,8 @@ func (p *Processor) handleReminder(sessionID string, send func([]models.Subscrib
 	return nil
 }
 
-func (p *Processor) prepareSessionReminderData(sessionID string) ([]models.Subscriber, *services.SessionReminderInfo, error) {
-	sessionDetails, err := p.fetchSessionExtendedInfo(sessionID)
+func (p *Processor) prepareSessionReminderData(ctx context.Context, sessionID string, includeEventSubscribers bool) ([]models.Subscriber, *services.SessionReminderInfo, error) {
+	sessionDetails, err := p.fetchSessionExtendedInfo(ctx, sessionID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -194,7 +182,7 @@ func (p *Processor) prepareSessionReminderData(sessionID string) ([]models.Subsc
 
 	// Fetch event details from event-query service
 	if sessionInfo.EventID != "" {
-		eventDetails, err := p.fetchEventBasicInfo(sessionInfo.EventID)
+		eventDetails, err := p.fetchEventBasicInfo(ctx, sessionInfo.EventID)
 		if err == nil {
 			sessionInfo.EventTitle = eventDetails.Title
 			sessionInfo.EventDescription = eventDetails.Description
@@ -220,7 +208,7 @@ func (p *Processor) prepareSessionReminderData(sessionID string) ([]models.Subsc
 	}
 
 	var eventSubscribers []models.Subscriber
-	if sessionInfo.EventID != "" {
+	if includeEventSubscribers && sessionInfo.EventID != "" {
 		eventSubscribers, err = p.subscriberService.GetEventSubscribers(sessionInfo.EventID)
 		if err != nil {
 			log.Printf("Warning: Could not get event subscribers for event %s: %v", sessionInfo.EventID, err)
@@ -232,7 +220,7 @@ func (p *Processor) prepareSessionReminderData(sessionID string) ([]models.Subsc
 	return allSubscribers, sessionInfo, nil
 }
 
-func (p *Processor) fetchSessionExtendedInfo(sessionID string) (*models.SessionExtendedInfo, error) {
+func (p *Processor) fetchSessionExtendedInfo(ctx context.Context, sessionID string) (*models.SessionExtendedInfo, error) {
 	if p.cfg.EventQueryServiceURL == "" {
 		return nil, fmt.Errorf("event query service URL not configured")
 	}
@@ -240,7 +228,12 @@ func (p *Processor) fetchSessionExtendedInfo(sessionID string) (*models.SessionE
 	apiURL := fmt.Sprintf("%s/v1/events/sessions/%s/extended-info", p.cfg.EventQueryServiceURL, sessionID)
 	log.Printf("Fetching session details from: %s", apiURL)
 
-	resp, err := p.httpClient.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating session info request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch session info: %w", err)
 	}
@@ -267,7 +260,7 @@ func (p *Processor) fetchSessionExtendedInfo(sessionID string) (*models.SessionE
 	return &sessionInfo, nil
 }
 
-func (p *Processor) fetchEventBasicInfo(eventID string) (*models.EventBasicInfo, error) {
+func (p *Processor) fetchEventBasicInfo(ctx context.Context, eventID string) (*models.EventBasicInfo, error) {
 	if p.cfg.EventQueryServiceURL == "" {
 		return nil, fmt.Errorf("event query service URL not configured")
 	}
@@ -275,7 +268,12 @@ func (p *Processor) fetchEventBasicInfo(eventID string) (*models.EventBasicInfo,
 	apiURL := fmt.Sprintf("%s/v1/events/%s/basic-info", p.cfg.EventQueryServiceURL, eventID)
 	log.Printf("Fetching event details from: %s", apiURL)
 
-	resp, err := p.httpClient.Get(apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating event info request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch event info: %w", err)
 	}
@@ -0,0 +1,23 @@
+package reminder
+
+import (
+	"testing"
+
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/schedule"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminderTemplateSelectsTheTemplateOfTheRule(t *testing.T) {
+	for templateID, want := range map[string]string{
+		"":                               schedule.StartReminderTemplate,
+		schedule.DefaultReminderTemplate: schedule.StartReminderTemplate,
+		schedule.SaleReminderTemplate:    schedule.SaleReminderTemplate,
+		schedule.FollowUpTemplate:        schedule.FollowUpTemplate,
+		"template-of-a-newer-version":    schedule.StartReminderTemplate,
+	} {
+		msg := &models.SQSReminderMessageBody{SessionID: "s1", ReminderType: "SESSION_START", TemplateID: templateID}
+		assert.Equal(t, want, reminderTemplate(msg, schedule.StartReminderTemplate), templateID)
+	}
+}
@@ -0,0 +1,120 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+)
+
+// PastDuePolicy decides what happens to a job whose fire time has already passed when it is
+// scheduled, e.g. a sale reminder for a session whose sales start in ten minutes
+type PastDuePolicy string
+
+const (
+	// PastDueSkip drops the job, and deletes any earlier schedule of it
+	PastDueSkip PastDuePolicy = "skip"
+	// PastDueFire sends the payload straight to the target queue
+	PastDueFire PastDuePolicy = "fire"
+	// PastDueClamp schedules the job a short delay from now
+	PastDueClamp PastDuePolicy = "clamp"
+)
+
+// pastDueJobs counts past-due decisions by "<target>.<policy>", served with the other expvars
+var pastDueJobs = expvar.NewMap("scheduler_past_due_jobs")
+
+// ParsePastDuePolicy parses skip, fire or clamp
+func ParsePastDuePolicy(value string) (PastDuePolicy, error) {
+	switch policy := PastDuePolicy(value); policy {
+	case PastDueSkip, PastDueFire, PastDueClamp:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown past-due policy %q, expected skip, fire or clamp", value)
+}
+
+// SetPastDuePolicy sets how past-due jobs of target are handled
+func (s *Service) SetPastDuePolicy(target Target, policy PastDuePolicy) {
+	s.pastDuePolicies[target] = policy
+}
+
+// SetPastDueClampDelay sets how far from now clamped jobs are scheduled
+func (s *Service) SetPastDueClampDelay(delay time.Duration) {
+	s.clampDelay = delay
+}
+
+// schedulePastDue applies the past-due policy of the job's target instead of creating a schedule
+// that is already in the past
+func (s *Service) schedulePastDue(session Session, job PlannedJob) error {
+	name := job.Name(session.ID)
+	policy := s.pastDuePolicies[job.Target]
+	if policy == "" {
+		policy = PastDueSkip
+	}
+	if policy == PastDueFire && (s.queues == nil || !s.queues.Handles(job.Target)) {
+		log.Printf("[Scheduler] No queue configured to fire '%s' directly, clamping it instead", name)
+		policy = PastDueClamp
+	}
+
+	log.Printf("[Scheduler] Schedule '%s' for %s is past due (fire time %s), policy %s",
+		name, job.LogContext, job.FireAt.Format(time.RFC3339), policy)
+	pastDueJobs.Add(string(job.Target)+"."+string(policy), 1)
+
+	switch policy {
+	case PastDueClamp:
+		return s.createOrUpdateScheduleWithPayload(session.ID, session.EventID, time.Now().Add(s.clampDelay), job.Prefix, job.Target, job.Payload, job.LogContext)
+	case PastDueFire:
+		return s.fireImmediately(session, job)
+	default:
+		// An earlier schedule of the job would fire at a time that no longer applies
+		return s.DeleteSchedule(session.ID, job.Prefix)
+	}
+}
+
+// fireImmediately sends a planned job to its queue and removes any earlier schedule of it.
+// Redelivered and replayed session changes plan the same past-due jobs again, so a job the
+// registry shows as fired is not sent a second time.
+func (s *Service) fireImmediately(session Session, planned PlannedJob) error {
+	name := planned.Name(session.ID)
+
+	// Putting a session on sale after it ended would reopen it once the closed job has run
+	if planned.Prefix == "session-onsale-" && !session.EndTime.IsZero() && !session.EndTime.After(time.Now()) {
+		log.Printf("[Scheduler] Not firing '%s', session %s has already ended", name, session.ID)
+		return s.DeleteSchedule(session.ID, planned.Prefix)
+	}
+
+	if s.registry != nil {
+		record, err := s.registry.Get(context.TODO(), name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("error checking whether %s for session %s was fired: %w", planned.LogContext, session.ID, err)
+		}
+		if record != nil && record.Status == JobStatusFired {
+			log.Printf("[Scheduler] Not firing '%s' again, it was fired at %s", name, record.UpdatedAt.Format(time.RFC3339))
+			return nil
+		}
+	}
+
+	payload, err := json.Marshal(planned.Payload)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload of %s: %w", planned.LogContext, err)
+	}
+	job := Job{Name: name, Target: planned.Target, FireAt: time.Now(), Payload: string(payload)}
+
+	if err := s.queues.Send(context.TODO(), job); err != nil {
+		s.recordScheduled(job, planned.Prefix, session.ID, session.EventID, err)
+		log.Printf("Failed to fire %s for session %s: %v", planned.LogContext, session.ID, err)
+		return fmt.Errorf("error firing %s for session %s: %w", planned.LogContext, session.ID, err)
+	}
+	s.recordScheduled(job, planned.Prefix, session.ID, session.EventID, nil)
+	s.recordStatus(job.Name, JobStatusFired, nil)
+
+	if err := s.Backend.Delete(context.TODO(), job.Name); err != nil && !errors.Is(err, ErrNotFound) {
+		// The payload is already on the queue; only the stale schedule is left behind
+		log.Printf("Fired '%s' but could not delete its earlier schedule: %v", job.Name, err)
+	}
+
+	log.Printf("Fired %s for session %s immediately.", planned.LogContext, session.ID)
+	return nil
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ms-scheduling/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// soonSession starts in two days with sales starting in ten minutes, so of the default
+// reminders only the sale reminder (30 minutes before sales start) is past due
+func soonSession() Session {
+	now := time.Now()
+	return Session{ID: "s1", StartTime: now.Add(48 * time.Hour), SalesStartTime: now.Add(10 * time.Minute)}
+}
+
+func TestPastDueSkipDeletesEarlierSchedule(t *testing.T) {
+	backend := newMemoryBackend()
+	backend.jobs["sale-start-reminder-s1"] = Job{Name: "sale-start-reminder-s1", FireAt: time.Now().Add(time.Hour)}
+	service := NewService(backend)
+
+	require.NoError(t, service.ScheduleSession(soonSession()))
+
+	assert.NotContains(t, backend.jobs, "sale-start-reminder-s1")
+	assert.Contains(t, backend.jobs, "session-onsale-s1")
+}
+
+func TestPastDueClampSchedulesShortlyFromNow(t *testing.T) {
+	backend := newMemoryBackend()
+	service := NewService(backend)
+	service.SetPastDuePolicy(TargetSessionReminders, PastDueClamp)
+	service.SetPastDueClampDelay(2 * time.Minute)
+
+	require.NoError(t, service.ScheduleSession(soonSession()))
+
+	reminder, ok := backend.jobs["sale-start-reminder-s1"]
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), reminder.FireAt, 5*time.Second)
+}
+
+func TestPastDueFireSendsPayloadToQueue(t *testing.T) {
+	backend := newMemoryBackend()
+	reminders := queue.NewMemoryQueue("session-reminders")
+	service := NewService(backend)
+	service.SetQueueSender(NewQueueSender(map[Target]queue.Queue{TargetSessionReminders: reminders}))
+	service.SetPastDuePolicy(TargetSessionReminders, PastDueFire)
+
+	require.NoError(t, service.ScheduleSession(soonSession()))
+
+	messages, err := reminders.Receive(context.Background(), queue.ReceiveOptions{VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Body, `"reminder_type":"SALE_START"`)
+	assert.NotContains(t, backend.jobs, "sale-start-reminder-s1")
+}
+
+func TestPastDueFireWithoutQueueClamps(t *testing.T) {
+	backend := newMemoryBackend()
+	service := NewService(backend)
+	session := soonSession()
+	session.SalesStartTime = time.Now().Add(-time.Minute)
+
+	require.NoError(t, service.ScheduleSession(session))
+
+	// The on-sale job defaults to fire, but no queue sender is set
+	assert.WithinDuration(t, time.Now().Add(time.Minute), backend.jobs["session-onsale-s1"].FireAt, 5*time.Second)
+}
+
+func TestPastDueFireSkipsOnSaleOfEndedSession(t *testing.T) {
+	backend := newMemoryBackend()
+	backend.jobs["session-onsale-s1"] = Job{Name: "session-onsale-s1", FireAt: time.Now().Add(time.Hour)}
+	scheduling := queue.NewMemoryQueue("session-scheduling")
+	service := NewService(backend)
+	service.SetQueueSender(NewQueueSender(map[Target]queue.Queue{TargetSessionScheduling: scheduling}))
+	service.SetPastDuePolicy(TargetSessionScheduling, PastDueFire)
+	now := time.Now()
+	session := Session{ID: "s1", SalesStartTime: now.Add(-3 * time.Hour), StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)}
+
+	require.NoError(t, service.ScheduleSession(session))
+
+	messages, err := scheduling.Receive(context.Background(), queue.ReceiveOptions{MaxMessages: 10, VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Body, `"action":"CLOSED"`)
+	assert.NotContains(t, backend.jobs, "session-onsale-s1")
+}
+
+func TestParsePastDuePolicy(t *testing.T) {
+	policy, err := ParsePastDuePolicy("clamp")
+	require.NoError(t, err)
+	assert.Equal(t, PastDueClamp, policy)
+
+	_, err = ParsePastDuePolicy("later")
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"ms-scheduling/internal/models"
+)
+
+// Session holds the session fields its schedules are derived from
+type Session struct {
+	ID             string    `json:"sessionId"`
+	EventID        string    `json:"eventId"`
+	OrganizationID string    `json:"organizationId,omitempty"`
+	Status         string    `json:"status"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+	SalesStartTime time.Time `json:"salesStartTime"`
+}
+
+// SessionFromDebezium converts a Debezium event_sessions row, whose times are in microseconds
+func SessionFromDebezium(row *models.EventSession) Session {
+	session := Session{
+		ID:      row.ID,
+		EventID: row.EventID,
+		Status:  row.Status,
+	}
+	if row.StartTime > 0 {
+		session.StartTime = MicrosecondsToTime(row.StartTime)
+	}
+	if row.EndTime > 0 {
+		session.EndTime = MicrosecondsToTime(row.EndTime)
+	}
+	if row.SalesStartTime > 0 {
+		session.SalesStartTime = MicrosecondsToTime(row.SalesStartTime)
+	}
+	return session
+}
+
+// PlannedJob is a schedule a session is expected to have
+type PlannedJob struct {
+	Prefix     string
+	Target     Target
+	FireAt     time.Time
+	Payload    interface{}
+	LogContext string
+}
+
+// Name returns the schedule name of the job for sessionID
+func (j PlannedJob) Name(sessionID string) string {
+	return j.Prefix + sessionID
+}
+
+// FixedPrefixes are the name prefixes of the session status jobs every session gets.
+// Reminders use the prefixes of their rules.
+var FixedPrefixes = []string{"session-onsale-", "session-closed-"}
+
+// SessionPrefixes returns the name prefixes of every schedule a session can have under rules
+func SessionPrefixes(rules []ReminderRule) []string {
+	prefixes := append([]string(nil), FixedPrefixes...)
+	for _, rule := range rules {
+		prefixes = append(prefixes, rule.Prefix())
+	}
+	return prefixes
+}
+
+// SessionJobs returns the schedules a session is expected to have: the on-sale and closed jobs
+// plus one reminder per rule. Cancelled sessions have none, and a job is left out when the time
+// it is anchored to is not set.
+func SessionJobs(session Session, rules []ReminderRule) []PlannedJob {
+	if session.Status == "CANCELLED" {
+		return nil
+	}
+
+	var jobs []PlannedJob
+	if !session.SalesStartTime.IsZero() {
+		jobs = append(jobs, PlannedJob{
+			Prefix:     "session-onsale-",
+			Target:     TargetSessionScheduling,
+			FireAt:     session.SalesStartTime,
+			Payload:    models.SQSMessageBody{SessionID: session.ID, Action: "ON_SALE"},
+			LogContext: "on-sale job",
+		})
+	}
+	if !session.EndTime.IsZero() {
+		jobs = append(jobs, PlannedJob{
+			Prefix:     "session-closed-",
+			Target:     TargetSessionScheduling,
+			FireAt:     session.EndTime,
+			Payload:    models.SQSMessageBody{SessionID: session.ID, Action: "CLOSED"},
+			LogContext: "closed job",
+		})
+	}
+	for _, rule := range rules {
+		anchor := rule.anchorTime(session)
+		if anchor.IsZero() {
+			continue
+		}
+		jobs = append(jobs, PlannedJob{
+			Prefix:     rule.Prefix(),
+			Target:     TargetSessionReminders,
+			FireAt:     anchor.Add(rule.Offset),
+			Payload:    reminderPayload(session.ID, rule),
+			LogContext: fmt.Sprintf("%s reminder email job", rule.Name),
+		})
+	}
+	return jobs
+}
+
+// reminderPayload builds the message the reminder processor expects for a reminder rule
+func reminderPayload(sessionID string, rule ReminderRule) models.SQSReminderMessageBody {
+	templateID := rule.TemplateID
+	if templateID == "" {
+		templateID = DefaultReminderTemplate
+	}
+	return models.SQSReminderMessageBody{
+		SessionID:      sessionID,
+		ReminderType:   rule.ReminderType,
+		TemplateID:     templateID,
+		NotificationID: fmt.Sprintf("reminder-%s-%s", rule.Name, sessionID),
+	}
+}
@@ -0,0 +1,276 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// claimLease is how long a claimed schedule is hidden from other tickers. A ticker that crashes
+// between sending and deleting a schedule leaves it to be fired again once the lease expires.
+const claimLease = 5 * time.Minute
+
+// PostgresScheduler stores schedules in the local_schedules table and fires due ones from an
+// in-process ticker, sending the payload to the same SQS queues EventBridge would.
+// Like EventBridge one-off schedules, a job is deleted once it has fired.
+type PostgresScheduler struct {
+	DB           *sql.DB
+	queues       *QueueSender
+	pollInterval time.Duration
+	batchSize    int
+
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// NewPostgresScheduler creates a Postgres scheduler backend that fires due schedules through queues.
+// A failed send is retried after 30s, doubling up to 30m, for at most 10 attempts.
+func NewPostgresScheduler(db *sql.DB, queues *QueueSender, pollInterval time.Duration, batchSize int) *PostgresScheduler {
+	if pollInterval <= 0 {
+		pollInterval = 10 * time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	return &PostgresScheduler{
+		DB:             db,
+		queues:         queues,
+		pollInterval:   pollInterval,
+		batchSize:      batchSize,
+		maxAttempts:    10,
+		initialBackoff: 30 * time.Second,
+		maxBackoff:     30 * time.Minute,
+	}
+}
+
+// SetRetryPolicy sets how often a schedule whose send fails is attempted and the backoff between attempts
+func (p *PostgresScheduler) SetRetryPolicy(maxAttempts int, initialBackoff, maxBackoff time.Duration) {
+	if maxAttempts > 0 {
+		p.maxAttempts = maxAttempts
+	}
+	if initialBackoff > 0 {
+		p.initialBackoff = initialBackoff
+	}
+	if maxBackoff >= p.initialBackoff {
+		p.maxBackoff = maxBackoff
+	}
+}
+
+// backoff returns the delay after the given failed attempt: initialBackoff * 2^(attempt-1), capped at maxBackoff
+func (p *PostgresScheduler) backoff(attempt int) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.maxBackoff {
+			return p.maxBackoff
+		}
+	}
+	return delay
+}
+
+// CreateOrUpdate inserts the schedule, or replaces the time, target and payload of an existing one
+func (p *PostgresScheduler) CreateOrUpdate(ctx context.Context, job Job) error {
+	if !p.queues.Handles(job.Target) {
+		return fmt.Errorf("unknown schedule target %q", job.Target)
+	}
+
+	query := `
+		INSERT INTO local_schedules (name, target, fire_at, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name) DO UPDATE
+		SET target = EXCLUDED.target,
+			fire_at = EXCLUDED.fire_at,
+			payload = EXCLUDED.payload,
+			attempts = 0,
+			last_error = NULL,
+			next_attempt_at = NOW(),
+			claim_token = NULL,
+			updated_at = NOW()
+	`
+	_, err := p.DB.ExecContext(ctx, query, job.Name, string(job.Target), job.FireAt.UTC(), job.Payload)
+	if err != nil {
+		return fmt.Errorf("error saving schedule %s: %w", job.Name, err)
+	}
+	return nil
+}
+
+// Delete removes a schedule
+func (p *PostgresScheduler) Delete(ctx context.Context, name string) error {
+	result, err := p.DB.ExecContext(ctx, `DELETE FROM local_schedules WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("error deleting schedule %s: %w", name, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get returns a single schedule by name
+func (p *PostgresScheduler) Get(ctx context.Context, name string) (*Job, error) {
+	var job Job
+	err := p.DB.QueryRowContext(ctx, `SELECT name, target, fire_at, payload FROM local_schedules WHERE name = $1`, name).
+		Scan(&job.Name, &job.Target, &job.FireAt, &job.Payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting schedule %s: %w", name, err)
+	}
+	return &job, nil
+}
+
+// List returns the schedules whose name starts with namePrefix, ordered by fire time
+func (p *PostgresScheduler) List(ctx context.Context, namePrefix string) ([]Job, error) {
+	query := `
+		SELECT name, target, fire_at, payload
+		FROM local_schedules
+		WHERE starts_with(name, $1)
+		ORDER BY fire_at, name
+	`
+	rows, err := p.DB.QueryContext(ctx, query, namePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing schedules with prefix %s: %w", namePrefix, err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		var job Job
+		if err := rows.Scan(&job.Name, &job.Target, &job.FireAt, &job.Payload); err != nil {
+			return nil, fmt.Errorf("error scanning schedule: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// Run fires due schedules on every tick until the context is cancelled
+func (p *PostgresScheduler) Run(ctx context.Context) error {
+	log.Printf("[Scheduler] Starting Postgres scheduler (batch size %d, poll interval %s)", p.batchSize, p.pollInterval)
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep firing while full batches come back, then wait for the next tick
+		for {
+			fired, err := p.FireDue(ctx)
+			if err != nil {
+				log.Printf("[Scheduler] Error firing due schedules: %v", err)
+				break
+			}
+			if fired < p.batchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Scheduler] Context cancelled, stopping Postgres scheduler")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// dueSchedule is a schedule claimed by FireDue
+type dueSchedule struct {
+	Job
+	attempts   int
+	claimToken string
+}
+
+// FireDue sends one batch of due schedules to their queues and returns how many were sent.
+// The batch is claimed first in its own statement, with SKIP LOCKED so several instances can run
+// the ticker side by side, and each schedule is deleted on its own once it was sent, so a database
+// error cannot undo the deletes of schedules already sent. A schedule whose send fails is retried
+// after a backoff until it reached the maximum attempts, after which it is kept with its last error.
+func (p *PostgresScheduler) FireDue(ctx context.Context) (int, error) {
+	due, err := p.claimDue(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	var errs []error
+	for _, job := range due {
+		if sendErr := p.queues.Send(ctx, job.Job); sendErr != nil {
+			if err := p.recordFailure(ctx, job, sendErr); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		// A schedule updated while it was being sent has a new claim token and is kept
+		if _, err := p.DB.ExecContext(ctx, `DELETE FROM local_schedules WHERE name = $1 AND claim_token = $2`, job.Name, job.claimToken); err != nil {
+			errs = append(errs, fmt.Errorf("error deleting fired schedule %s: %w", job.Name, err))
+		}
+		log.Printf("[Scheduler] Fired schedule '%s' (due %s)", job.Name, job.FireAt.Format(time.RFC3339))
+		fired++
+	}
+	return fired, errors.Join(errs...)
+}
+
+// claimDue claims a batch of due schedules: every claim counts as an attempt and hides the
+// schedule for claimLease, so a schedule that crashes the ticker also reaches the attempt limit
+func (p *PostgresScheduler) claimDue(ctx context.Context) ([]dueSchedule, error) {
+	query := `
+		UPDATE local_schedules
+		SET attempts = attempts + 1,
+			next_attempt_at = NOW() + $3 * INTERVAL '1 second',
+			claim_token = md5(random()::text || clock_timestamp()::text),
+			updated_at = NOW()
+		WHERE name IN (
+			SELECT name
+			FROM local_schedules
+			WHERE fire_at <= NOW() AND next_attempt_at <= NOW() AND attempts < $2
+			ORDER BY attempts, fire_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING name, target, fire_at, payload, attempts, claim_token
+	`
+	rows, err := p.DB.QueryContext(ctx, query, p.batchSize, p.maxAttempts, int(claimLease/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("error claiming due schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var due []dueSchedule
+	for rows.Next() {
+		var job dueSchedule
+		if err := rows.Scan(&job.Name, &job.Target, &job.FireAt, &job.Payload, &job.attempts, &job.claimToken); err != nil {
+			return nil, fmt.Errorf("error scanning due schedule: %w", err)
+		}
+		due = append(due, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading due schedules: %w", err)
+	}
+	return due, nil
+}
+
+// recordFailure records the send error and makes the schedule due again after the backoff
+func (p *PostgresScheduler) recordFailure(ctx context.Context, job dueSchedule, sendErr error) error {
+	if job.attempts >= p.maxAttempts {
+		log.Printf("[Scheduler] Giving up on schedule '%s' after %d attempts: %v", job.Name, job.attempts, sendErr)
+	} else {
+		log.Printf("[Scheduler] Failed to fire schedule '%s' (attempt %d/%d), retrying in %s: %v",
+			job.Name, job.attempts, p.maxAttempts, p.backoff(job.attempts), sendErr)
+	}
+
+	_, err := p.DB.ExecContext(ctx, `
+		UPDATE local_schedules
+		SET last_error = $3, next_attempt_at = NOW() + $4 * INTERVAL '1 second', claim_token = NULL, updated_at = NOW()
+		WHERE name = $1 AND claim_token = $2
+	`, job.Name, job.claimToken, sendErr.Error(), int(p.backoff(job.attempts)/time.Second))
+	if err != nil {
+		return fmt.Errorf("error recording failure of schedule %s: %w", job.Name, err)
+	}
+	return nil
+}
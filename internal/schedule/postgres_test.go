@@ -0,0 +1,19 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresSchedulerBacksOffExponentiallyUpToTheMaximum(t *testing.T) {
+	p := NewPostgresScheduler(nil, nil, 0, 0)
+	p.SetRetryPolicy(5, time.Second, 5*time.Second)
+
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 4*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(4))
+	assert.Equal(t, 5, p.maxAttempts)
+}
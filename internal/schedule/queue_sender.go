@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+
+	"ms-scheduling/internal/queue"
+)
+
+// QueueSender sends schedule payloads straight to the queue of their target,
+// the same message a backend delivers when the schedule fires
+type QueueSender struct {
+	queues map[Target]queue.Queue
+}
+
+// NewQueueSender creates a sender; queues maps each target to its queue, nil when not configured
+func NewQueueSender(queues map[Target]queue.Queue) *QueueSender {
+	return &QueueSender{queues: queues}
+}
+
+// Handles reports whether a queue is configured for target
+func (q *QueueSender) Handles(target Target) bool {
+	return q.queues[target] != nil
+}
+
+// Send delivers the job payload to the queue of its target
+func (q *QueueSender) Send(ctx context.Context, job Job) error {
+	target := q.queues[job.Target]
+	if target == nil {
+		return fmt.Errorf("no queue configured for target %s", job.Target)
+	}
+
+	if err := target.Send(ctx, job.Payload, nil); err != nil {
+		return fmt.Errorf("error sending schedule %s to %s: %w", job.Name, target.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ms-scheduling/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueSenderSendsPayloadToTargetQueue(t *testing.T) {
+	scheduling := queue.NewMemoryQueue("session-scheduling")
+	reminders := queue.NewMemoryQueue("session-reminders")
+	queues := NewQueueSender(map[Target]queue.Queue{
+		TargetSessionScheduling: scheduling,
+		TargetSessionReminders:  reminders,
+	})
+
+	err := queues.Send(context.Background(), Job{Name: "sale-start-reminder-s1", Target: TargetSessionReminders, Payload: `{"session_id":"s1"}`})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, scheduling.Len())
+	messages, err := reminders.Receive(context.Background(), queue.ReceiveOptions{VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, `{"session_id":"s1"}`, messages[0].Body)
+}
+
+func TestQueueSenderRejectsUnconfiguredTarget(t *testing.T) {
+	queues := NewQueueSender(map[Target]queue.Queue{TargetSessionScheduling: nil})
+
+	assert.False(t, queues.Handles(TargetSessionScheduling))
+	assert.Error(t, queues.Send(context.Background(), Job{Name: "session-onsale-s1", Target: TargetSessionScheduling}))
+
+	p := NewPostgresScheduler(nil, queues, 0, 0)
+	assert.Error(t, p.CreateOrUpdate(context.Background(), Job{Name: "x", Target: "UNKNOWN"}))
+}
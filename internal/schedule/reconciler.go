@@ -0,0 +1,289 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrReconcileRunning is returned when a reconciliation is requested while one is in progress
+var ErrReconcileRunning = errors.New("schedule reconciliation already running")
+
+// maxReportChanges caps the changes listed in a report; the counters always cover every change
+const maxReportChanges = 1000
+
+// ChangeAction is what the reconciler does to bring a schedule in line with its session
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+// Change is a single difference between the expected and actual schedules
+type Change struct {
+	Action         ChangeAction `json:"action"`
+	Name           string       `json:"name"`
+	SessionID      string       `json:"sessionId"`
+	CurrentFireAt  *time.Time   `json:"currentFireAt,omitempty"`
+	ExpectedFireAt *time.Time   `json:"expectedFireAt,omitempty"`
+	Applied        bool         `json:"applied"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// ReconcileReport summarises one reconciliation run
+type ReconcileReport struct {
+	DryRun          bool      `json:"dryRun"`
+	StartedAt       time.Time `json:"startedAt"`
+	FinishedAt      time.Time `json:"finishedAt"`
+	SessionsChecked int       `json:"sessionsChecked"`
+	InSync          int       `json:"inSync"`
+	Created         int       `json:"created"`
+	Updated         int       `json:"updated"`
+	Deleted         int       `json:"deleted"`
+	Failed          int       `json:"failed"`
+	Changes         []Change  `json:"changes"`
+	Truncated       bool      `json:"truncated"`
+	Errors          []string  `json:"errors,omitempty"`
+}
+
+// addChange counts a change and lists it while the report has room
+func (r *ReconcileReport) addChange(change Change) {
+	switch {
+	case change.Error != "":
+		r.Failed++
+	case change.Action == ChangeCreate:
+		r.Created++
+	case change.Action == ChangeUpdate:
+		r.Updated++
+	case change.Action == ChangeDelete:
+		r.Deleted++
+	}
+
+	if len(r.Changes) < maxReportChanges {
+		r.Changes = append(r.Changes, change)
+	} else {
+		r.Truncated = true
+	}
+}
+
+// Reconciler repairs drift between sessions and their schedules, e.g. after a missed Debezium
+// event or a failed scheduling call. It pages through all sessions, computes the schedules each
+// should have under its reminder rules and creates, updates or deletes schedules until the
+// backend matches. This also applies changed reminder rules to existing sessions.
+type Reconciler struct {
+	service  *Service
+	sessions SessionSource
+	pageSize int
+	interval time.Duration
+	dryRun   bool
+
+	running sync.Mutex
+	mu      sync.Mutex
+	last    *ReconcileReport
+}
+
+// NewReconciler creates a reconciler. Periodic runs started by Run apply changes unless dryRun is set.
+func NewReconciler(service *Service, sessions SessionSource, pageSize int, interval time.Duration, dryRun bool) *Reconciler {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Reconciler{
+		service:  service,
+		sessions: sessions,
+		pageSize: pageSize,
+		interval: interval,
+		dryRun:   dryRun,
+	}
+}
+
+// Run reconciles on every interval until the context is cancelled
+func (r *Reconciler) Run(ctx context.Context) error {
+	log.Printf("[Reconciler] Starting schedule reconciler (interval %s, dry run %t)", r.interval, r.dryRun)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[Reconciler] Context cancelled, stopping schedule reconciler")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if _, err := r.Reconcile(ctx, r.dryRun); err != nil {
+			log.Printf("[Reconciler] Error reconciling schedules: %v", err)
+		}
+	}
+}
+
+// LastReport returns the report of the most recent run, or nil before the first run
+func (r *Reconciler) LastReport() *ReconcileReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// Reconcile runs one reconciliation. With dryRun the differences are reported but not applied.
+func (r *Reconciler) Reconcile(ctx context.Context, dryRun bool) (*ReconcileReport, error) {
+	if !r.running.TryLock() {
+		return nil, ErrReconcileRunning
+	}
+	defer r.running.Unlock()
+
+	report := &ReconcileReport{DryRun: dryRun, StartedAt: time.Now().UTC()}
+	log.Printf("[Reconciler] Reconciling schedules (dry run %t)", dryRun)
+
+	var err error
+	for page := 0; ; page++ {
+		var sessions []Session
+		var last bool
+		sessions, last, err = r.sessions.ListSessions(ctx, page, r.pageSize)
+		if err != nil {
+			err = fmt.Errorf("error listing sessions page %d: %w", page, err)
+			report.Errors = append(report.Errors, err.Error())
+			break
+		}
+
+		for _, session := range sessions {
+			r.reconcileSession(ctx, session, dryRun, report)
+		}
+		if last || ctx.Err() != nil {
+			break
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	log.Printf("[Reconciler] Checked %d sessions: %d in sync, %d created, %d updated, %d deleted, %d failed (dry run %t)",
+		report.SessionsChecked, report.InSync, report.Created, report.Updated, report.Deleted, report.Failed, dryRun)
+
+	r.mu.Lock()
+	r.last = report
+	r.mu.Unlock()
+
+	return report, err
+}
+
+// reconcileSession compares the schedules of one session with the expected ones and repairs them
+func (r *Reconciler) reconcileSession(ctx context.Context, session Session, dryRun bool, report *ReconcileReport) {
+	report.SessionsChecked++
+
+	rules, err := r.service.RulesFor(ctx, session)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		report.Failed++
+		return
+	}
+
+	now := time.Now()
+	expected := make(map[string]PlannedJob)
+	pastDue := make(map[string]bool)
+	for _, job := range SessionJobs(session, rules) {
+		if job.FireAt.After(now) {
+			expected[job.Prefix] = job
+		} else {
+			// The backend delivers or already delivered it; leave it alone
+			pastDue[job.Prefix] = true
+		}
+	}
+	if len(expected) == 0 && !session.EndTime.IsZero() && session.EndTime.Before(now) {
+		// Every schedule of an ended session has already fired
+		report.InSync++
+		return
+	}
+
+	settled, err := r.service.settledSchedules(ctx, session.ID)
+	if err != nil {
+		// Without the registry a cancelled or fired schedule could be recreated, so leave the session alone
+		report.Errors = append(report.Errors, fmt.Sprintf("error loading registered schedules of session %s: %v", session.ID, err))
+		report.Failed++
+		return
+	}
+
+	inSync := true
+	for _, prefix := range r.service.sessionPrefixes(ctx, session, rules) {
+		if pastDue[prefix] {
+			continue
+		}
+
+		name := prefix + session.ID
+		if status, ok := settled[name]; ok {
+			log.Printf("[Reconciler] Leaving schedule '%s' alone, it was %s", name, status)
+			continue
+		}
+		actual, err := r.service.Backend.Get(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			actual, err = nil, nil
+		}
+		if err != nil {
+			inSync = false
+			report.Errors = append(report.Errors, err.Error())
+			report.Failed++
+			continue
+		}
+
+		planned, want := expected[prefix]
+		var change *Change
+		switch {
+		case want && actual == nil:
+			change = &Change{Action: ChangeCreate, ExpectedFireAt: &planned.FireAt}
+		case want && !matchesPlan(*actual, planned):
+			change = &Change{Action: ChangeUpdate, CurrentFireAt: &actual.FireAt, ExpectedFireAt: &planned.FireAt}
+		case !want && actual != nil:
+			change = &Change{Action: ChangeDelete, CurrentFireAt: &actual.FireAt}
+		}
+		if change == nil {
+			continue
+		}
+
+		inSync = false
+		change.Name = name
+		change.SessionID = session.ID
+		if !dryRun {
+			if change.Action == ChangeDelete {
+				err = r.service.DeleteSchedule(session.ID, prefix)
+			} else {
+				err = r.service.SchedulePlanned(session, planned)
+			}
+			if err != nil {
+				change.Error = err.Error()
+			} else {
+				change.Applied = true
+			}
+		}
+		log.Printf("[Reconciler] %s schedule '%s' (applied %t)", change.Action, name, change.Applied)
+		report.addChange(*change)
+	}
+
+	if inSync {
+		report.InSync++
+	}
+}
+
+// matchesPlan reports whether a backend schedule already matches the planned job.
+// Schedule expressions have second precision, and payloads are compared as JSON values.
+func matchesPlan(actual Job, planned PlannedJob) bool {
+	if actual.Target != planned.Target || !actual.FireAt.Truncate(time.Second).Equal(planned.FireAt.Truncate(time.Second)) {
+		return false
+	}
+
+	plannedJSON, err := json.Marshal(planned.Payload)
+	if err != nil {
+		return false
+	}
+	var actualValue, plannedValue interface{}
+	if json.Unmarshal([]byte(actual.Payload), &actualValue) != nil || json.Unmarshal(plannedJSON, &plannedValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(actualValue, plannedValue)
+}
@@ -0,0 +1,106 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSessions serves fixed pages of sessions
+type staticSessions struct {
+	pages [][]Session
+}
+
+func (s *staticSessions) ListSessions(ctx context.Context, page, size int) ([]Session, bool, error) {
+	if page >= len(s.pages) {
+		return nil, true, nil
+	}
+	return s.pages[page], page == len(s.pages)-1, nil
+}
+
+func futureSession(id string) Session {
+	start := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	return Session{
+		ID:             id,
+		EventID:        "e-" + id,
+		Status:         "SCHEDULED",
+		StartTime:      start,
+		EndTime:        start.Add(3 * time.Hour),
+		SalesStartTime: start.Add(-7 * 24 * time.Hour),
+	}
+}
+
+func plannedJob(t *testing.T, session Session, prefix string) Job {
+	for _, job := range SessionJobs(session, DefaultReminderRules()) {
+		if job.Prefix == prefix {
+			payload, err := json.Marshal(job.Payload)
+			require.NoError(t, err)
+			return Job{Name: job.Name(session.ID), Target: job.Target, FireAt: job.FireAt, Payload: string(payload)}
+		}
+	}
+	t.Fatalf("no planned job with prefix %s", prefix)
+	return Job{}
+}
+
+func TestReconcileRepairsDrift(t *testing.T) {
+	session := futureSession("s1")
+	backend := newMemoryBackend()
+
+	// on-sale is correct, closed is missing, start reminder is at the wrong time
+	backend.jobs["session-onsale-s1"] = plannedJob(t, session, "session-onsale-")
+	staleReminder := plannedJob(t, session, "session-start-reminder-")
+	staleReminder.FireAt = staleReminder.FireAt.Add(-time.Hour)
+	backend.jobs["session-start-reminder-s1"] = staleReminder
+	backend.jobs["sale-start-reminder-s1"] = plannedJob(t, session, "sale-start-reminder-")
+
+	reconciler := NewReconciler(NewService(backend), &staticSessions{pages: [][]Session{{session}}}, 10, time.Hour, false)
+	report, err := reconciler.Reconcile(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.SessionsChecked)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 0, report.Deleted)
+	assert.Len(t, backend.jobs, 4)
+	assert.True(t, session.StartTime.AddDate(0, 0, -1).Equal(backend.jobs["session-start-reminder-s1"].FireAt))
+	assert.Same(t, report, reconciler.LastReport())
+}
+
+func TestReconcileDryRunOnlyReports(t *testing.T) {
+	cancelled := futureSession("s2")
+	cancelled.Status = "CANCELLED"
+	backend := newMemoryBackend()
+	backend.jobs["session-onsale-s2"] = plannedJob(t, futureSession("s2"), "session-onsale-")
+
+	reconciler := NewReconciler(NewService(backend), &staticSessions{pages: [][]Session{{futureSession("s1")}, {cancelled}}}, 1, time.Hour, false)
+	report, err := reconciler.Reconcile(context.Background(), true)
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.SessionsChecked)
+	assert.Equal(t, 4, report.Created)
+	assert.Equal(t, 1, report.Deleted)
+	for _, change := range report.Changes {
+		assert.False(t, change.Applied)
+	}
+	assert.Len(t, backend.jobs, 1)
+}
+
+func TestReconcileLeavesPastDueJobsAlone(t *testing.T) {
+	session := futureSession("s3")
+	session.SalesStartTime = time.Now().Add(-time.Hour)
+	backend := newMemoryBackend()
+	backend.jobs["session-onsale-s3"] = Job{Name: "session-onsale-s3", Target: TargetSessionScheduling, FireAt: session.SalesStartTime, Payload: "{}"}
+
+	reconciler := NewReconciler(NewService(backend), &staticSessions{pages: [][]Session{{session}}}, 10, time.Hour, false)
+	report, err := reconciler.Reconcile(context.Background(), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Created)
+	assert.Contains(t, backend.jobs, "session-onsale-s3")
+	assert.NotContains(t, backend.jobs, "sale-start-reminder-s3")
+}
@@ -0,0 +1,205 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JobStatus is the state of a schedule recorded in the registry
+type JobStatus string
+
+const (
+	// JobStatusScheduled means the backend holds the schedule; it fires at fire_at
+	JobStatusScheduled JobStatus = "scheduled"
+	// JobStatusFailed means the last create/update or delete call to the backend failed
+	JobStatusFailed JobStatus = "failed"
+	// JobStatusDeleted means the schedule was removed because its session changed
+	JobStatusDeleted JobStatus = "deleted"
+	// JobStatusCancelled means an administrator cancelled the schedule
+	JobStatusCancelled JobStatus = "cancelled"
+	// JobStatusFired means the payload was sent to its queue by this service (force-fire)
+	JobStatusFired JobStatus = "fired"
+)
+
+// JobRecord is a schedule recorded in the scheduled_jobs table
+type JobRecord struct {
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	SessionID string    `json:"sessionId"`
+	EventID   string    `json:"eventId,omitempty"`
+	Target    Target    `json:"target"`
+	TargetARN string    `json:"targetArn,omitempty"`
+	Payload   string    `json:"payload"`
+	FireAt    time.Time `json:"fireAt"`
+	Status    JobStatus `json:"status"`
+	LastError *string   `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Job returns the backend schedule of the record
+func (r JobRecord) Job() Job {
+	return Job{Name: r.Name, Target: r.Target, FireAt: r.FireAt, Payload: r.Payload}
+}
+
+// JobFilter selects registry records; empty fields match everything.
+// From and To bound the fire time (inclusive).
+type JobFilter struct {
+	SessionID string
+	EventID   string
+	Status    JobStatus
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+}
+
+// Registry mirrors every schedule created or deleted through the Service in the scheduled_jobs
+// table, so schedules can be looked up without asking the backend
+type Registry struct {
+	DB         *sql.DB
+	targetARNs map[Target]string
+}
+
+// NewRegistry creates a registry; targetARNs are recorded with each job for reference
+func NewRegistry(db *sql.DB, targetARNs map[Target]string) *Registry {
+	return &Registry{
+		DB:         db,
+		targetARNs: targetARNs,
+	}
+}
+
+const jobRecordColumns = `name, prefix, session_id, event_id, target, target_arn, payload, fire_at, status,
+	last_error, created_at, updated_at`
+
+// RecordScheduled upserts a schedule after a create/update call. callErr is the backend error, if any.
+func (r *Registry) RecordScheduled(ctx context.Context, record JobRecord, callErr error) error {
+	status, lastError := JobStatusScheduled, sql.NullString{}
+	if callErr != nil {
+		status, lastError = JobStatusFailed, sql.NullString{String: callErr.Error(), Valid: true}
+	}
+
+	query := `
+		INSERT INTO scheduled_jobs (name, prefix, session_id, event_id, target, target_arn, payload, fire_at, status, last_error)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), $7, $8, $9, $10)
+		ON CONFLICT (name) DO UPDATE
+		SET prefix = EXCLUDED.prefix,
+			session_id = EXCLUDED.session_id,
+			event_id = COALESCE(EXCLUDED.event_id, scheduled_jobs.event_id),
+			target = EXCLUDED.target,
+			target_arn = EXCLUDED.target_arn,
+			payload = EXCLUDED.payload,
+			fire_at = EXCLUDED.fire_at,
+			status = EXCLUDED.status,
+			last_error = EXCLUDED.last_error,
+			updated_at = NOW()
+	`
+	_, err := r.DB.ExecContext(ctx, query,
+		record.Name, record.Prefix, record.SessionID, record.EventID, string(record.Target),
+		r.targetARNs[record.Target], record.Payload, record.FireAt.UTC(), string(status), lastError)
+	if err != nil {
+		return fmt.Errorf("error recording schedule %s: %w", record.Name, err)
+	}
+	return nil
+}
+
+// RecordStatus sets the status of a recorded schedule. A failed call keeps the status and only
+// records callErr, since the backend schedule is unchanged.
+func (r *Registry) RecordStatus(ctx context.Context, name string, status JobStatus, callErr error) error {
+	var err error
+	if callErr != nil {
+		_, err = r.DB.ExecContext(ctx,
+			`UPDATE scheduled_jobs SET last_error = $2, updated_at = NOW() WHERE name = $1`,
+			name, callErr.Error())
+	} else {
+		_, err = r.DB.ExecContext(ctx,
+			`UPDATE scheduled_jobs SET status = $2, last_error = NULL, updated_at = NOW() WHERE name = $1`,
+			name, string(status))
+	}
+	if err != nil {
+		return fmt.Errorf("error recording status of schedule %s: %w", name, err)
+	}
+	return nil
+}
+
+// Get returns a recorded schedule by name, or ErrNotFound
+func (r *Registry) Get(ctx context.Context, name string) (*JobRecord, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+jobRecordColumns+` FROM scheduled_jobs WHERE name = $1`, name)
+	record, err := scanJobRecord(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting schedule %s: %w", name, err)
+	}
+	return record, nil
+}
+
+// List returns the recorded schedules matching filter, ordered by fire time
+func (r *Registry) List(ctx context.Context, filter JobFilter) ([]JobRecord, error) {
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.SessionID != "" {
+		addCondition("session_id = $%d", filter.SessionID)
+	}
+	if filter.EventID != "" {
+		addCondition("event_id = $%d", filter.EventID)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", string(filter.Status))
+	}
+	if filter.From != nil {
+		addCondition("fire_at >= $%d", filter.From.UTC())
+	}
+	if filter.To != nil {
+		addCondition("fire_at <= $%d", filter.To.UTC())
+	}
+
+	query := `SELECT ` + jobRecordColumns + ` FROM scheduled_jobs`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY fire_at, name`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing scheduled jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var records []JobRecord
+	for rows.Next() {
+		record, err := scanJobRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning scheduled job: %w", err)
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// scanJobRecord reads one row selected with jobRecordColumns
+func scanJobRecord(row interface{ Scan(...interface{}) error }) (*JobRecord, error) {
+	var record JobRecord
+	var eventID, targetARN sql.NullString
+	err := row.Scan(&record.Name, &record.Prefix, &record.SessionID, &eventID, &record.Target, &targetARN,
+		&record.Payload, &record.FireAt, &record.Status, &record.LastError, &record.CreatedAt, &record.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	record.EventID = eventID.String
+	record.TargetARN = targetARN.String
+	return &record, nil
+}
@@ -0,0 +1,136 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OrganizationResolver finds the organization an event belongs to
+type OrganizationResolver interface {
+	OrganizationID(ctx context.Context, eventID string) (string, error)
+}
+
+// RuleStore keeps per-organization reminder rules in Postgres. Sessions of organizations
+// without rules of their own get the default rules.
+type RuleStore struct {
+	db            *sql.DB
+	defaults      []ReminderRule
+	organizations OrganizationResolver
+}
+
+// NewRuleStore creates a rule store. organizations resolves the organization of sessions
+// that do not carry one; it may be nil, in which case those sessions get the defaults.
+func NewRuleStore(db *sql.DB, defaults []ReminderRule, organizations OrganizationResolver) *RuleStore {
+	return &RuleStore{
+		db:            db,
+		defaults:      defaults,
+		organizations: organizations,
+	}
+}
+
+// Defaults returns the rules of organizations without rules of their own
+func (s *RuleStore) Defaults() []ReminderRule {
+	return s.defaults
+}
+
+// RulesFor returns the rules of the session's organization, or the defaults when it has none
+func (s *RuleStore) RulesFor(ctx context.Context, session Session) ([]ReminderRule, error) {
+	// Skip resolving the organization while no organization has rules of its own
+	var anyRules bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM reminder_rules)`).Scan(&anyRules); err != nil {
+		return nil, fmt.Errorf("error checking reminder rules: %w", err)
+	}
+	if !anyRules {
+		return s.defaults, nil
+	}
+
+	organizationID := session.OrganizationID
+	if organizationID == "" && s.organizations != nil && session.EventID != "" {
+		var err error
+		organizationID, err = s.organizations.OrganizationID(ctx, session.EventID)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving organization of event %s: %w", session.EventID, err)
+		}
+	}
+	if organizationID == "" {
+		return s.defaults, nil
+	}
+
+	rules, err := s.List(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return s.defaults, nil
+	}
+	return rules, nil
+}
+
+// List returns the rules an organization has set, empty when it uses the defaults
+func (s *RuleStore) List(ctx context.Context, organizationID string) ([]ReminderRule, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT name, anchor, offset_seconds, reminder_type, template_id
+		FROM reminder_rules
+		WHERE organization_id = $1
+		ORDER BY name`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reminder rules of organization %s: %w", organizationID, err)
+	}
+	defer rows.Close()
+
+	rules := []ReminderRule{}
+	for rows.Next() {
+		var rule ReminderRule
+		var offsetSeconds int64
+		if err := rows.Scan(&rule.Name, &rule.Anchor, &offsetSeconds, &rule.ReminderType, &rule.TemplateID); err != nil {
+			return nil, fmt.Errorf("error scanning reminder rule: %w", err)
+		}
+		rule.Offset = time.Duration(offsetSeconds) * time.Second
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminder rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Replace validates rules and replaces every rule of the organization with them
+func (s *RuleStore) Replace(ctx context.Context, organizationID string, rules []ReminderRule) error {
+	if err := ValidateRules(rules); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reminder_rules WHERE organization_id = $1`, organizationID); err != nil {
+		return fmt.Errorf("error deleting reminder rules of organization %s: %w", organizationID, err)
+	}
+	for _, rule := range rules {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO reminder_rules (organization_id, name, anchor, offset_seconds, reminder_type, template_id)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			organizationID, rule.Name, rule.Anchor, int64(rule.Offset/time.Second), rule.ReminderType, rule.TemplateID)
+		if err != nil {
+			return fmt.Errorf("error inserting reminder rule %s: %w", rule.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing reminder rules: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the organization's rules so its sessions fall back to the defaults
+func (s *RuleStore) Delete(ctx context.Context, organizationID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM reminder_rules WHERE organization_id = $1`, organizationID); err != nil {
+		return fmt.Errorf("error deleting reminder rules of organization %s: %w", organizationID, err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Anchor is the session time a reminder rule is relative to
+type Anchor string
+
+const (
+	AnchorStart      Anchor = "start"
+	AnchorSalesStart Anchor = "sales-start"
+	AnchorEnd        Anchor = "end"
+)
+
+// Template IDs select the email a reminder sends. The reminder type decides who gets it.
+const (
+	// DefaultReminderTemplate sends the email of the rule's reminder type, used when a rule names no template
+	DefaultReminderTemplate = "session-reminder-template"
+	// StartReminderTemplate is the session start reminder email
+	StartReminderTemplate = "session-start-reminder-template"
+	// SaleReminderTemplate is the ticket sales start reminder email
+	SaleReminderTemplate = "sale-start-reminder-template"
+	// FollowUpTemplate is the post-session follow-up email
+	FollowUpTemplate = "session-follow-up-template"
+)
+
+// ReminderTemplates are the template IDs the reminder processor knows how to render
+var ReminderTemplates = []string{DefaultReminderTemplate, StartReminderTemplate, SaleReminderTemplate, FollowUpTemplate}
+
+// ReminderTypes are the reminder types the reminder processor knows how to send
+var ReminderTypes = []string{"SESSION_START", "SALE_START", "SESSION_ENDED"}
+
+// ruleNamePattern keeps rule names usable as schedule name prefixes. EventBridge schedule names
+// are at most 64 characters, so with the dash and the 36 character session UUID a rule name has 27.
+var ruleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,26}$`)
+
+// ReminderRule schedules one reminder per session at Offset from the Anchor time.
+// A negative offset fires before the anchor. Each rule gets its own schedule, named
+// "<rule name>-<session id>".
+type ReminderRule struct {
+	Name         string
+	Anchor       Anchor
+	Offset       time.Duration
+	ReminderType string
+	TemplateID   string
+}
+
+// reminderRuleJSON is the API form of a rule, with the offset as a Go duration string (e.g. "-168h")
+type reminderRuleJSON struct {
+	Name         string `json:"name"`
+	Anchor       Anchor `json:"anchor"`
+	Offset       string `json:"offset"`
+	ReminderType string `json:"reminderType"`
+	TemplateID   string `json:"templateId,omitempty"`
+}
+
+// MarshalJSON writes the offset as a duration string
+func (r ReminderRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(reminderRuleJSON{
+		Name:         r.Name,
+		Anchor:       r.Anchor,
+		Offset:       r.Offset.String(),
+		ReminderType: r.ReminderType,
+		TemplateID:   r.TemplateID,
+	})
+}
+
+// UnmarshalJSON reads the offset as a duration string
+func (r *ReminderRule) UnmarshalJSON(data []byte) error {
+	var raw reminderRuleJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	offset, err := time.ParseDuration(raw.Offset)
+	if err != nil {
+		return fmt.Errorf("invalid offset %q: %w", raw.Offset, err)
+	}
+	*r = ReminderRule{
+		Name:         raw.Name,
+		Anchor:       raw.Anchor,
+		Offset:       offset,
+		ReminderType: raw.ReminderType,
+		TemplateID:   raw.TemplateID,
+	}
+	return nil
+}
+
+// Prefix returns the schedule name prefix of the rule
+func (r ReminderRule) Prefix() string {
+	return r.Name + "-"
+}
+
+// Validate normalizes the rule and checks that it can be scheduled
+func (r *ReminderRule) Validate() error {
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	r.Anchor = Anchor(strings.ToLower(strings.TrimSpace(string(r.Anchor))))
+	r.ReminderType = strings.ToUpper(strings.TrimSpace(r.ReminderType))
+	r.TemplateID = strings.TrimSpace(r.TemplateID)
+
+	if !ruleNamePattern.MatchString(r.Name) {
+		return fmt.Errorf("invalid rule name %q: use up to 27 lowercase letters, digits and dashes", r.Name)
+	}
+	for _, prefix := range FixedPrefixes {
+		if r.Prefix() == prefix {
+			return fmt.Errorf("rule name %q is reserved", r.Name)
+		}
+	}
+	switch r.Anchor {
+	case AnchorStart, AnchorSalesStart, AnchorEnd:
+	default:
+		return fmt.Errorf("invalid anchor %q for rule %s: use start, sales-start or end", r.Anchor, r.Name)
+	}
+	if !slices.Contains(ReminderTypes, r.ReminderType) {
+		return fmt.Errorf("unknown reminder type %q for rule %s: use one of %s", r.ReminderType, r.Name, strings.Join(ReminderTypes, ", "))
+	}
+	if r.TemplateID == "" {
+		r.TemplateID = DefaultReminderTemplate
+	}
+	if !slices.Contains(ReminderTemplates, r.TemplateID) {
+		return fmt.Errorf("unknown template %q for rule %s: use one of %s", r.TemplateID, r.Name, strings.Join(ReminderTemplates, ", "))
+	}
+	return nil
+}
+
+// anchorTime returns the session time the rule is relative to, zero when it is not set
+func (r ReminderRule) anchorTime(session Session) time.Time {
+	switch r.Anchor {
+	case AnchorStart:
+		return session.StartTime
+	case AnchorSalesStart:
+		return session.SalesStartTime
+	case AnchorEnd:
+		return session.EndTime
+	}
+	return time.Time{}
+}
+
+// DefaultReminderRules are the reminders sessions had before rules were configurable:
+// one day before the session starts and 30 minutes before ticket sales start
+func DefaultReminderRules() []ReminderRule {
+	return []ReminderRule{
+		{Name: "session-start-reminder", Anchor: AnchorStart, Offset: -24 * time.Hour, ReminderType: "SESSION_START", TemplateID: DefaultReminderTemplate},
+		{Name: "sale-start-reminder", Anchor: AnchorSalesStart, Offset: -30 * time.Minute, ReminderType: "SALE_START", TemplateID: DefaultReminderTemplate},
+	}
+}
+
+// FollowUpRule sends the follow-up email to ticket holders delay after the session ends
+func FollowUpRule(delay time.Duration) ReminderRule {
+	return ReminderRule{Name: "session-follow-up", Anchor: AnchorEnd, Offset: delay, ReminderType: "SESSION_ENDED", TemplateID: FollowUpTemplate}
+}
+
+// WithFollowUp adds the follow-up rule to rules unless they already send a SESSION_ENDED reminder
+// or delay is not positive
+func WithFollowUp(rules []ReminderRule, delay time.Duration) []ReminderRule {
+	if delay <= 0 {
+		return rules
+	}
+	for _, rule := range rules {
+		if rule.ReminderType == "SESSION_ENDED" {
+			return rules
+		}
+	}
+	return append(slices.Clone(rules), FollowUpRule(delay))
+}
+
+// ParseReminderRules parses rules written as "name:anchor:offset:TYPE[:template]",
+// e.g. "start-7d:start:-168h:SESSION_START"
+func ParseReminderRules(specs []string) ([]ReminderRule, error) {
+	rules := make([]ReminderRule, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) < 4 || len(parts) > 5 {
+			return nil, fmt.Errorf("invalid reminder rule %q: expected name:anchor:offset:type[:template]", spec)
+		}
+
+		offset, err := time.ParseDuration(strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset in reminder rule %q: %w", spec, err)
+		}
+
+		rule := ReminderRule{Name: parts[0], Anchor: Anchor(parts[1]), Offset: offset, ReminderType: parts[3]}
+		if len(parts) == 5 {
+			rule.TemplateID = parts[4]
+		}
+		rules = append(rules, rule)
+	}
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateRules validates and normalizes every rule and checks that names are unique
+func ValidateRules(rules []ReminderRule) error {
+	seen := make(map[string]bool)
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+		if seen[rules[i].Name] {
+			return fmt.Errorf("duplicate reminder rule %s", rules[i].Name)
+		}
+		seen[rules[i].Name] = true
+	}
+	return nil
+}
+
+// RuleSource returns the reminder rules that apply to a session
+type RuleSource interface {
+	RulesFor(ctx context.Context, session Session) ([]ReminderRule, error)
+}
+
+// StaticRules applies the same rules to every session
+type StaticRules []ReminderRule
+
+// RulesFor returns the rules
+func (r StaticRules) RulesFor(ctx context.Context, session Session) ([]ReminderRule, error) {
+	return r, nil
+}
@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ms-scheduling/internal/models"
+)
+
+func TestParseReminderRules(t *testing.T) {
+	rules, err := ParseReminderRules([]string{
+		"start-7d:start:-168h:SESSION_START",
+		"sale-2h:Sales-Start:-2h:sale_start:sale-start-reminder-template",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []ReminderRule{
+		{Name: "start-7d", Anchor: AnchorStart, Offset: -168 * time.Hour, ReminderType: "SESSION_START", TemplateID: DefaultReminderTemplate},
+		{Name: "sale-2h", Anchor: AnchorSalesStart, Offset: -2 * time.Hour, ReminderType: "SALE_START", TemplateID: SaleReminderTemplate},
+	}, rules)
+}
+
+func TestParseReminderRulesRejectsInvalidRules(t *testing.T) {
+	for _, spec := range [][]string{
+		{"start-7d:start:-168h"},
+		{"start-7d:start:a week:SESSION_START"},
+		{"start-7d:doors-open:-1h:SESSION_START"},
+		{"start-7d:start:-1h:SESSION_END"},
+		{"Start 7d:start:-1h:SESSION_START"},
+		{"reminder-one-week-before-the-show:start:-168h:SESSION_START"},
+		{"session-onsale:start:-1h:SESSION_START"},
+		{"start-7d:start:-168h:SESSION_START:newsletter-template"},
+		{"start-7d:start:-168h:SESSION_START", "start-7d:start:-24h:SESSION_START"},
+	} {
+		_, err := ParseReminderRules(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestReminderRuleJSONUsesDurationStrings(t *testing.T) {
+	rule := ReminderRule{Name: "start-2h", Anchor: AnchorStart, Offset: -2 * time.Hour, ReminderType: "SESSION_START", TemplateID: DefaultReminderTemplate}
+
+	data, err := json.Marshal(rule)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"start-2h","anchor":"start","offset":"-2h0m0s","reminderType":"SESSION_START","templateId":"session-reminder-template"}`, string(data))
+
+	var decoded ReminderRule
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, rule, decoded)
+}
+
+func TestSessionJobsSchedulesOneReminderPerRule(t *testing.T) {
+	start := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+	session := Session{ID: "s1", StartTime: start, EndTime: start.Add(3 * time.Hour)}
+	rules := []ReminderRule{
+		{Name: "start-7d", Anchor: AnchorStart, Offset: -7 * 24 * time.Hour, ReminderType: "SESSION_START"},
+		{Name: "start-2h", Anchor: AnchorStart, Offset: -2 * time.Hour, ReminderType: "SESSION_START"},
+		{Name: "sale-1h", Anchor: AnchorSalesStart, Offset: -time.Hour, ReminderType: "SALE_START"},
+	}
+
+	jobs := make(map[string]PlannedJob)
+	for _, job := range SessionJobs(session, rules) {
+		jobs[job.Name(session.ID)] = job
+	}
+
+	// No sales start time: no on-sale job and no sale reminder
+	assert.Len(t, jobs, 3)
+	assert.True(t, start.Add(-7*24*time.Hour).Equal(jobs["start-7d-s1"].FireAt))
+	assert.True(t, start.Add(-2*time.Hour).Equal(jobs["start-2h-s1"].FireAt))
+	assert.Equal(t, models.SQSReminderMessageBody{
+		SessionID:      "s1",
+		ReminderType:   "SESSION_START",
+		TemplateID:     DefaultReminderTemplate,
+		NotificationID: "reminder-start-2h-s1",
+	}, jobs["start-2h-s1"].Payload)
+	assert.Contains(t, jobs, "session-closed-s1")
+}
+
+func TestWithFollowUpSchedulesAfterSessionEnd(t *testing.T) {
+	start := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+	session := Session{ID: "s1", StartTime: start, EndTime: start.Add(3 * time.Hour)}
+
+	rules := WithFollowUp(DefaultReminderRules(), 2*time.Hour)
+	require.Len(t, rules, 3)
+
+	jobs := make(map[string]PlannedJob)
+	for _, job := range SessionJobs(session, rules) {
+		jobs[job.Name(session.ID)] = job
+	}
+	followUp, ok := jobs["session-follow-up-s1"]
+	require.True(t, ok)
+	assert.True(t, session.EndTime.Add(2*time.Hour).Equal(followUp.FireAt))
+	assert.Equal(t, "SESSION_ENDED", followUp.Payload.(models.SQSReminderMessageBody).ReminderType)
+
+	// Disabled, or already sent by a configured rule
+	assert.Len(t, WithFollowUp(DefaultReminderRules(), 0), 2)
+	assert.Len(t, WithFollowUp(rules, time.Hour), 3)
+}
@@ -0,0 +1,41 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Scheduler backends when a schedule does not exist
+var ErrNotFound = errors.New("schedule not found")
+
+// Target identifies the queue a schedule delivers its payload to. Backends map it to
+// the queue ARN (EventBridge) or queue URL (Postgres) from the configuration.
+type Target string
+
+const (
+	TargetSessionScheduling Target = "SESSION_SCHEDULING"
+	TargetSessionReminders  Target = "SESSION_REMINDERS"
+)
+
+// Job is a one-off schedule that delivers Payload to the Target queue at FireAt
+type Job struct {
+	Name    string    `json:"name"`
+	Target  Target    `json:"target"`
+	FireAt  time.Time `json:"fireAt"`
+	Payload string    `json:"payload"`
+}
+
+// Scheduler is a backend that stores one-off schedules and fires them onto SQS.
+// CreateOrUpdate is idempotent by job name. Delete and Get return ErrNotFound for unknown names.
+type Scheduler interface {
+	CreateOrUpdate(ctx context.Context, job Job) error
+	Delete(ctx context.Context, name string) error
+	Get(ctx context.Context, name string) (*Job, error)
+	List(ctx context.Context, namePrefix string) ([]Job, error)
+}
+
+// MicrosecondsToTime converts a Debezium microsecond timestamp to a Go time.Time object.
+func MicrosecondsToTime(microseconds int64) time.Time {
+	return time.Unix(0, microseconds*1000)
+}
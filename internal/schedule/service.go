@@ -0,0 +1,349 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Service builds the session schedules (on-sale, closed, reminders) on top of a Scheduler backend
+type Service struct {
+	Backend  Scheduler
+	registry *Registry
+	queues   *QueueSender
+	rules    RuleSource
+
+	pastDuePolicies map[Target]PastDuePolicy
+	clampDelay      time.Duration
+}
+
+// NewService creates a new scheduling service backed by backend, using the default reminder rules.
+// Past-due session status jobs are fired right away and past-due reminders are skipped.
+func NewService(backend Scheduler) *Service {
+	return &Service{
+		Backend: backend,
+		rules:   StaticRules(DefaultReminderRules()),
+		pastDuePolicies: map[Target]PastDuePolicy{
+			TargetSessionScheduling: PastDueFire,
+			TargetSessionReminders:  PastDueSkip,
+		},
+		clampDelay: time.Minute,
+	}
+}
+
+// SetRuleSource sets where the reminder rules of a session come from
+func (s *Service) SetRuleSource(rules RuleSource) {
+	s.rules = rules
+}
+
+// RulesFor returns the reminder rules that apply to a session
+func (s *Service) RulesFor(ctx context.Context, session Session) ([]ReminderRule, error) {
+	rules, err := s.rules.RulesFor(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("error loading reminder rules for session %s: %w", session.ID, err)
+	}
+	return rules, nil
+}
+
+// SetRegistry sets the registry every create, update and delete call is recorded in
+func (s *Service) SetRegistry(registry *Registry) {
+	s.registry = registry
+}
+
+// SetQueueSender sets the sender used to fire schedules immediately
+func (s *Service) SetQueueSender(queues *QueueSender) {
+	s.queues = queues
+}
+
+// SchedulePlanned creates or updates a planned job of the session. A job whose fire time has
+// passed is handled by the past-due policy of its target instead.
+func (s *Service) SchedulePlanned(session Session, job PlannedJob) error {
+	if !job.FireAt.After(time.Now()) {
+		return s.schedulePastDue(session, job)
+	}
+	return s.createOrUpdateScheduleWithPayload(session.ID, session.EventID, job.FireAt, job.Prefix, job.Target, job.Payload, job.LogContext)
+}
+
+// ScheduleSession creates or updates every planned job of a session, continuing past failures
+func (s *Service) ScheduleSession(session Session) error {
+	rules, err := s.RulesFor(context.TODO(), session)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, job := range SessionJobs(session, rules) {
+		if err := s.SchedulePlanned(session, job); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RescheduleSession brings the schedules of an updated session in line with its new times.
+// Both versions are planned from the same list of session-anchored jobs; jobs whose fire time or
+// payload changed are created or updated, and jobs that are no longer planned are deleted.
+func (s *Service) RescheduleSession(before, after Session) error {
+	rules, err := s.RulesFor(context.TODO(), after)
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]PlannedJob)
+	for _, job := range SessionJobs(before, rules) {
+		previous[job.Prefix] = job
+	}
+
+	var errs []error
+	for _, job := range SessionJobs(after, rules) {
+		old, existed := previous[job.Prefix]
+		delete(previous, job.Prefix)
+		if existed && old.FireAt.Equal(job.FireAt) && reflect.DeepEqual(old.Payload, job.Payload) {
+			continue
+		}
+
+		log.Printf("Schedule '%s' of session %s changed. Updating it to %s.", job.Name(after.ID), after.ID, job.FireAt.Format("2006-01-02 15:04:05"))
+		if err := s.SchedulePlanned(after, job); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Whatever is left was planned before but not anymore, e.g. a cleared sales start time
+	for prefix := range previous {
+		if err := s.DeleteSchedule(after.ID, prefix); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DeleteSessionSchedules removes every schedule of a session: the fixed jobs, the reminders of its
+// current rules and any other schedule the registry knows for it, e.g. from a rule since removed.
+// When the rules cannot be loaded the default rule prefixes are tried and the error is returned.
+func (s *Service) DeleteSessionSchedules(session Session) error {
+	var errs []error
+	rules, err := s.RulesFor(context.TODO(), session)
+	if err != nil {
+		errs = append(errs, err)
+		rules = DefaultReminderRules()
+	}
+	for _, prefix := range s.sessionPrefixes(context.TODO(), session, rules) {
+		if err := s.DeleteSchedule(session.ID, prefix); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sessionPrefixes returns the prefixes of every schedule the session may have under rules,
+// plus those of any schedule recorded for it in the registry
+func (s *Service) sessionPrefixes(ctx context.Context, session Session, rules []ReminderRule) []string {
+	prefixes := SessionPrefixes(rules)
+	if s.registry == nil {
+		return prefixes
+	}
+
+	records, err := s.registry.List(ctx, JobFilter{SessionID: session.ID})
+	if err != nil {
+		log.Printf("[Scheduler] Error listing registered schedules of session %s: %v", session.ID, err)
+	}
+	for _, record := range records {
+		if !slices.Contains(prefixes, record.Prefix) {
+			prefixes = append(prefixes, record.Prefix)
+		}
+	}
+	return prefixes
+}
+
+// settledSchedules returns the names of the session's schedules an administrator cancelled or
+// force-fired. They must not be recreated, or they would fire after all or fire a second time.
+func (s *Service) settledSchedules(ctx context.Context, sessionID string) (map[string]JobStatus, error) {
+	if s.registry == nil {
+		return nil, nil
+	}
+
+	records, err := s.registry.List(ctx, JobFilter{SessionID: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	settled := make(map[string]JobStatus)
+	for _, record := range records {
+		if record.Status == JobStatusCancelled || record.Status == JobStatusFired {
+			settled[record.Name] = record.Status
+		}
+	}
+	return settled, nil
+}
+
+// createOrUpdateScheduleWithPayload is a generic method that handles the scheduling logic with any payload
+func (s *Service) createOrUpdateScheduleWithPayload(sessionID, eventID string, scheduleTime time.Time, namePrefix string, target Target, payload interface{}, logContext string) error {
+	scheduleName := namePrefix + sessionID
+	log.Printf("Creating/updating schedule '%s' at time: %s", scheduleName, scheduleTime)
+
+	// Marshal the payload to JSON
+	inputJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling message body to JSON: %v", err)
+		return err
+	}
+
+	job := Job{
+		Name:    scheduleName,
+		Target:  target,
+		FireAt:  scheduleTime,
+		Payload: string(inputJSON),
+	}
+	err = s.Backend.CreateOrUpdate(context.TODO(), job)
+	s.recordScheduled(job, namePrefix, sessionID, eventID, err)
+	if err != nil {
+		log.Printf("Failed to create/update schedule for %s: %v", logContext, err)
+		return fmt.Errorf("error scheduling %s for session %s: %w", logContext, sessionID, err)
+	}
+
+	log.Printf("Successfully created/updated schedule for %s.", logContext)
+	return nil
+}
+
+// DeleteSchedule removes a session schedule. A schedule that no longer exists is not an error,
+// it might have already run and deleted itself.
+func (s *Service) DeleteSchedule(sessionID, namePrefix string) error {
+	scheduleName := namePrefix + sessionID
+	log.Printf("Deleting schedule '%s'", scheduleName)
+
+	err := s.Backend.Delete(context.TODO(), scheduleName)
+	if errors.Is(err, ErrNotFound) {
+		log.Printf("Schedule '%s' not found for deletion, it may have already completed.", scheduleName)
+		err = nil
+	}
+	s.recordStatus(scheduleName, JobStatusDeleted, err)
+	if err != nil {
+		log.Printf("Error deleting schedule '%s': %v", scheduleName, err)
+		return fmt.Errorf("error deleting schedule %s: %w", scheduleName, err)
+	}
+
+	log.Printf("Successfully deleted schedule '%s'", scheduleName)
+	return nil
+}
+
+// ErrNotFireable is returned by FireNow for a schedule that is not waiting to fire
+var ErrNotFireable = errors.New("schedule cannot be fired")
+
+// Cancel removes a schedule by name and records it as cancelled. It returns ErrNotFound for a
+// schedule that is neither registered nor held by the backend.
+func (s *Service) Cancel(ctx context.Context, name string) error {
+	registered := false
+	if s.registry != nil {
+		_, err := s.registry.Get(ctx, name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		registered = err == nil
+	}
+
+	err := s.Backend.Delete(ctx, name)
+	if errors.Is(err, ErrNotFound) {
+		if !registered {
+			return ErrNotFound
+		}
+		// Registered but already gone from the backend, e.g. it fired
+		err = nil
+	}
+	s.recordStatus(name, JobStatusCancelled, err)
+	if err != nil {
+		return fmt.Errorf("error cancelling schedule %s: %w", name, err)
+	}
+
+	log.Printf("Cancelled schedule '%s'", name)
+	return nil
+}
+
+// FireNow sends the payload of a recorded schedule to its queue right away and removes the
+// schedule from the backend, so it is not delivered a second time. Only schedules that are
+// scheduled or failed and have not been delivered by the backend can be fired; others return
+// ErrNotFireable.
+func (s *Service) FireNow(ctx context.Context, name string) (*JobRecord, error) {
+	if s.registry == nil || s.queues == nil {
+		return nil, errors.New("firing schedules requires the schedule registry and queue URLs")
+	}
+
+	record, err := s.registry.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != JobStatusScheduled && record.Status != JobStatusFailed {
+		return nil, fmt.Errorf("%w: it is %s", ErrNotFireable, record.Status)
+	}
+
+	if err := s.fire(ctx, record.Job()); err != nil {
+		if !errors.Is(err, ErrNotFireable) {
+			s.recordStatus(name, JobStatusFired, err)
+		}
+		return nil, err
+	}
+	s.recordStatus(name, JobStatusFired, nil)
+
+	log.Printf("Fired schedule '%s' on request", name)
+	record.Status = JobStatusFired
+	return record, nil
+}
+
+// fire takes the job from the backend and sends its payload to the queue. The backend does not
+// tell the registry when it delivers a job, it only deletes it, so a job the backend no longer
+// holds has most likely been delivered. It is only sent when its fire time is still ahead, e.g.
+// because creating the schedule failed. If sending fails the schedule is put back.
+func (s *Service) fire(ctx context.Context, job Job) error {
+	deleteErr := s.Backend.Delete(ctx, job.Name)
+	switch {
+	case errors.Is(deleteErr, ErrNotFound):
+		if !job.FireAt.After(time.Now()) {
+			return fmt.Errorf("%w: the backend no longer holds it, it fired at %s", ErrNotFireable, job.FireAt.Format(time.RFC3339))
+		}
+	case deleteErr != nil:
+		return fmt.Errorf("error taking schedule %s from the backend: %w", job.Name, deleteErr)
+	}
+
+	if err := s.queues.Send(ctx, job); err != nil {
+		if deleteErr == nil {
+			if restoreErr := s.Backend.CreateOrUpdate(ctx, job); restoreErr != nil {
+				log.Printf("Failed to fire '%s' and could not restore its schedule: %v", job.Name, restoreErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// recordScheduled mirrors a create/update call in the registry. Registry errors are only logged,
+// the backend call is what matters for delivery.
+func (s *Service) recordScheduled(job Job, namePrefix, sessionID, eventID string, callErr error) {
+	if s.registry == nil {
+		return
+	}
+	record := JobRecord{
+		Name:      job.Name,
+		Prefix:    namePrefix,
+		SessionID: sessionID,
+		EventID:   eventID,
+		Target:    job.Target,
+		Payload:   job.Payload,
+		FireAt:    job.FireAt,
+	}
+	if err := s.registry.RecordScheduled(context.TODO(), record, callErr); err != nil {
+		log.Printf("[Scheduler] %v", err)
+	}
+}
+
+// recordStatus mirrors a delete, cancel or fire in the registry
+func (s *Service) recordStatus(name string, status JobStatus, callErr error) {
+	if s.registry == nil {
+		return
+	}
+	if err := s.registry.RecordStatus(context.TODO(), name, status, callErr); err != nil {
+		log.Printf("[Scheduler] %v", err)
+	}
+}
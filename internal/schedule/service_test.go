@@ -0,0 +1,183 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ms-scheduling/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBackend is an in-memory Scheduler
+type memoryBackend struct {
+	jobs map[string]Job
+	err  error
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{jobs: make(map[string]Job)}
+}
+
+func (m *memoryBackend) CreateOrUpdate(ctx context.Context, job Job) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.jobs[job.Name] = job
+	return nil
+}
+
+func (m *memoryBackend) Delete(ctx context.Context, name string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.jobs[name]; !ok {
+		return ErrNotFound
+	}
+	delete(m.jobs, name)
+	return nil
+}
+
+func (m *memoryBackend) Get(ctx context.Context, name string) (*Job, error) {
+	job, ok := m.jobs[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &job, nil
+}
+
+func (m *memoryBackend) List(ctx context.Context, namePrefix string) ([]Job, error) {
+	var jobs []Job
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func TestScheduleSessionBuildsReminderPayload(t *testing.T) {
+	backend := newMemoryBackend()
+	service := NewService(backend)
+	start := time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC)
+
+	err := service.ScheduleSession(Session{ID: "s1", EventID: "e1", StartTime: start})
+	require.NoError(t, err)
+
+	job := backend.jobs["session-start-reminder-s1"]
+	assert.Equal(t, TargetSessionReminders, job.Target)
+	assert.True(t, start.Add(-24*time.Hour).Equal(job.FireAt))
+	assert.JSONEq(t, `{"session_id":"s1","reminder_type":"SESSION_START","template_id":"session-reminder-template","notification_id":"reminder-session-start-reminder-s1"}`, job.Payload)
+}
+
+func TestRescheduleSessionMovesOnlyChangedJobs(t *testing.T) {
+	backend := newMemoryBackend()
+	service := NewService(backend)
+	service.SetRuleSource(StaticRules{
+		{Name: "start-7d", Anchor: AnchorStart, Offset: -7 * 24 * time.Hour, ReminderType: "SESSION_START"},
+		{Name: "start-2h", Anchor: AnchorStart, Offset: -2 * time.Hour, ReminderType: "SESSION_START"},
+		{Name: "sale-1h", Anchor: AnchorSalesStart, Offset: -time.Hour, ReminderType: "SALE_START"},
+	})
+	before := Session{
+		ID:             "s1",
+		StartTime:      time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC),
+		SalesStartTime: time.Date(2030, 4, 1, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, service.ScheduleSession(before))
+	saleReminder := backend.jobs["sale-1h-s1"]
+
+	after := before
+	after.StartTime = before.StartTime.Add(48 * time.Hour)
+	require.NoError(t, service.RescheduleSession(before, after))
+
+	assert.True(t, after.StartTime.Add(-7*24*time.Hour).Equal(backend.jobs["start-7d-s1"].FireAt))
+	assert.True(t, after.StartTime.Add(-2*time.Hour).Equal(backend.jobs["start-2h-s1"].FireAt))
+	assert.Equal(t, saleReminder, backend.jobs["sale-1h-s1"])
+
+	// Clearing the sales start time removes every job anchored to it
+	cleared := after
+	cleared.SalesStartTime = time.Time{}
+	require.NoError(t, service.RescheduleSession(after, cleared))
+
+	assert.NotContains(t, backend.jobs, "sale-1h-s1")
+	assert.NotContains(t, backend.jobs, "session-onsale-s1")
+	assert.Contains(t, backend.jobs, "start-2h-s1")
+}
+
+func TestDeleteSessionSchedulesCoversRulePrefixes(t *testing.T) {
+	backend := newMemoryBackend()
+	service := NewService(backend)
+	service.SetRuleSource(StaticRules{{Name: "start-2h", Anchor: AnchorStart, Offset: -2 * time.Hour, ReminderType: "SESSION_START"}})
+	session := Session{ID: "s1", StartTime: time.Date(2030, 5, 10, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2030, 5, 10, 21, 0, 0, 0, time.UTC)}
+	require.NoError(t, service.ScheduleSession(session))
+	require.Len(t, backend.jobs, 2)
+
+	require.NoError(t, service.DeleteSessionSchedules(session))
+	assert.Empty(t, backend.jobs)
+}
+
+func TestDeleteScheduleIgnoresMissingSchedule(t *testing.T) {
+	service := NewService(newMemoryBackend())
+
+	assert.NoError(t, service.DeleteSchedule("s1", "session-onsale-"))
+}
+
+func TestDeleteScheduleReturnsBackendErrors(t *testing.T) {
+	backend := newMemoryBackend()
+	backend.err = errors.New("access denied")
+	service := NewService(backend)
+
+	assert.ErrorContains(t, service.DeleteSchedule("s1", "session-onsale-"), "access denied")
+}
+
+func TestCancelRemovesSchedule(t *testing.T) {
+	backend := newMemoryBackend()
+	backend.jobs["session-closed-s1"] = Job{Name: "session-closed-s1"}
+	service := NewService(backend)
+
+	require.NoError(t, service.Cancel(context.Background(), "session-closed-s1"))
+	assert.Empty(t, backend.jobs)
+}
+
+func TestCancelUnknownScheduleReturnsNotFound(t *testing.T) {
+	service := NewService(newMemoryBackend())
+
+	assert.ErrorIs(t, service.Cancel(context.Background(), "session-closed-s1"), ErrNotFound)
+}
+
+func TestFireNowRequiresRegistry(t *testing.T) {
+	service := NewService(newMemoryBackend())
+
+	_, err := service.FireNow(context.Background(), "session-closed-s1")
+	assert.Error(t, err)
+}
+
+func TestFireSendsJobsTheBackendStillHolds(t *testing.T) {
+	backend := newMemoryBackend()
+	scheduling := queue.NewMemoryQueue("session-scheduling")
+	service := NewService(backend)
+	service.SetQueueSender(NewQueueSender(map[Target]queue.Queue{TargetSessionScheduling: scheduling}))
+	job := Job{Name: "session-onsale-s1", Target: TargetSessionScheduling, FireAt: time.Now().Add(time.Hour), Payload: `{"action":"ON_SALE"}`}
+	backend.jobs[job.Name] = job
+
+	require.NoError(t, service.fire(context.Background(), job))
+
+	assert.NotContains(t, backend.jobs, job.Name)
+	assert.Equal(t, 1, scheduling.Len())
+}
+
+func TestFireRefusesJobsTheBackendAlreadyDelivered(t *testing.T) {
+	scheduling := queue.NewMemoryQueue("session-scheduling")
+	service := NewService(newMemoryBackend())
+	service.SetQueueSender(NewQueueSender(map[Target]queue.Queue{TargetSessionScheduling: scheduling}))
+	delivered := Job{Name: "session-onsale-s1", Target: TargetSessionScheduling, FireAt: time.Now().Add(-time.Minute), Payload: `{"action":"ON_SALE"}`}
+
+	assert.ErrorIs(t, service.fire(context.Background(), delivered), ErrNotFireable)
+	assert.Equal(t, 0, scheduling.Len())
+
+	// A schedule that failed to be created has not fired yet, so it can still be sent
+	neverCreated := Job{Name: "session-closed-s1", Target: TargetSessionScheduling, FireAt: time.Now().Add(time.Hour), Payload: `{"action":"CLOSED"}`}
+	require.NoError(t, service.fire(context.Background(), neverCreated))
+	assert.Equal(t, 1, scheduling.Len())
+}
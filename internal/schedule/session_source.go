@@ -0,0 +1,146 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/config"
+	"ms-scheduling/internal/models"
+)
+
+// SessionSource pages through every session schedules are derived from
+type SessionSource interface {
+	ListSessions(ctx context.Context, page, size int) (sessions []Session, last bool, err error)
+}
+
+// EventQuerySessionSource reads sessions from the event-query service
+type EventQuerySessionSource struct {
+	cfg        config.Config
+	httpClient *http.Client
+}
+
+// NewEventQuerySessionSource creates a session source backed by the event-query service
+func NewEventQuerySessionSource(cfg config.Config, httpClient *http.Client) *EventQuerySessionSource {
+	return &EventQuerySessionSource{
+		cfg:        cfg,
+		httpClient: httpClient,
+	}
+}
+
+// sessionPage is the paged response of the event-query internal sessions endpoint
+type sessionPage struct {
+	Content []Session `json:"content"`
+	Last    bool      `json:"last"`
+}
+
+// ListSessions fetches one page (zero-based) of sessions with an M2M token
+func (s *EventQuerySessionSource) ListSessions(ctx context.Context, page, size int) ([]Session, bool, error) {
+	if s.cfg.EventQueryServiceURL == "" {
+		return nil, false, fmt.Errorf("event query service URL not configured")
+	}
+
+	token, err := auth.GetM2MToken(s.cfg, s.httpClient)
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting M2M token for session listing: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/internal/v1/sessions?page=%d&size=%d", s.cfg.EventQueryServiceURL, page, size)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Error closing sessions response body: %v", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, false, fmt.Errorf("sessions API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result sessionPage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, false, fmt.Errorf("failed to decode sessions: %w", err)
+	}
+
+	// An empty page also ends the listing, in case the service does not report "last"
+	return result.Content, result.Last || len(result.Content) == 0, nil
+}
+
+// EventQueryOrganizations resolves the organization of an event through the event-query
+// service. An event never moves between organizations, so results are cached.
+type EventQueryOrganizations struct {
+	cfg        config.Config
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewEventQueryOrganizations creates an organization resolver backed by the event-query service
+func NewEventQueryOrganizations(cfg config.Config, httpClient *http.Client) *EventQueryOrganizations {
+	return &EventQueryOrganizations{
+		cfg:        cfg,
+		httpClient: httpClient,
+		cache:      make(map[string]string),
+	}
+}
+
+// OrganizationID returns the ID of the organization that owns the event
+func (o *EventQueryOrganizations) OrganizationID(ctx context.Context, eventID string) (string, error) {
+	o.mu.Lock()
+	organizationID, ok := o.cache[eventID]
+	o.mu.Unlock()
+	if ok {
+		return organizationID, nil
+	}
+
+	if o.cfg.EventQueryServiceURL == "" {
+		return "", fmt.Errorf("event query service URL not configured")
+	}
+
+	apiURL := fmt.Sprintf("%s/v1/events/%s/basic-info", o.cfg.EventQueryServiceURL, eventID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch event info: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.Printf("Error closing event info response body: %v", cerr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("event info API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var eventInfo models.EventBasicInfo
+	if err := json.NewDecoder(resp.Body).Decode(&eventInfo); err != nil {
+		return "", fmt.Errorf("failed to decode event info: %w", err)
+	}
+
+	o.mu.Lock()
+	o.cache[eventID] = eventInfo.Organization.ID
+	o.mu.Unlock()
+	return eventInfo.Organization.ID, nil
+}
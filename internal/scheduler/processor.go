@@ -2,131 +2,74 @@ package scheduler
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"ms-scheduling/internal/auth"
 	"ms-scheduling/internal/config"
 	"ms-scheduling/internal/models"
-	"ms-scheduling/internal/sqsutil"
+	"ms-scheduling/internal/queue"
+	"ms-scheduling/internal/sqsworker"
 	"net/http"
-	"time"
-
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 // SessionProcessor handles processing of session scheduling messages from SQS
 type Processor struct {
-	sqsClient       *sqs.Client
+	queue           queue.Queue
 	httpClient      *http.Client
 	cfg             config.Config
-	queueURL        string
 	eventServiceURL string
+	dlq             *sqsworker.DeadLetterQueue
 }
 
 // NewProcessor creates a new session scheduling processor
-func NewProcessor(sqsClient *sqs.Client, httpClient *http.Client, cfg config.Config) *Processor {
+func NewProcessor(q queue.Queue, httpClient *http.Client, cfg config.Config) *Processor {
 	return &Processor{
-		sqsClient:       sqsClient,
+		queue:           q,
 		httpClient:      httpClient,
 		cfg:             cfg,
-		queueURL:        cfg.SQSSessionSchedulingQueueURL,
 		eventServiceURL: cfg.EventServiceURL,
 	}
 }
 
-// ProcessMessages processes messages from the session scheduling queue
-func (p *Processor) ProcessMessages(ctx context.Context) error {
-	if p.queueURL == "" {
-		log.Println("Session scheduling queue URL not configured, skipping processor")
-		return fmt.Errorf("session scheduling queue URL not configured")
-	}
-
-	log.Printf("Starting to process session scheduling messages from %s", p.queueURL)
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, stopping session scheduling processor")
-			return ctx.Err()
-		default:
-			// Continue processing
-		}
+// SetDeadLetterQueue sets where messages are moved once they failed AWS_SQS_SESSION_SCHEDULING_MAX_RECEIVES times
+func (p *Processor) SetDeadLetterQueue(dlq *sqsworker.DeadLetterQueue) {
+	p.dlq = dlq
+}
 
-		// Receive messages from scheduling queue
-		rawMessages, err := sqsutil.ReceiveMessage(p.sqsClient, p.queueURL)
-		if err != nil {
-			log.Printf("Error receiving messages from scheduling SQS queue: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
+// ProcessMessages processes messages from the session scheduling queue until the context is cancelled
+func (p *Processor) ProcessMessages(ctx context.Context) error {
+	worker := sqsworker.New(p.queue, sqsworker.Options{
+		Name:              "session scheduling",
+		Workers:           p.cfg.SQSSchedulingWorkers,
+		VisibilityTimeout: p.cfg.SQSVisibilityTimeout,
+		// A session the Event Service keeps failing on is retried less and less often, then dead-lettered
+		RetryBackoff:    p.cfg.SQSRetryInitialBackoff,
+		MaxRetryBackoff: p.cfg.SQSRetryMaxBackoff,
+		MaxReceives:     p.cfg.SQSSchedulingMaxReceives,
+		DeadLetterQueue: p.dlq,
+	}, p.handleMessage)
+
+	return worker.Run(ctx)
+}
 
-		if len(rawMessages) == 0 {
-			log.Println("No messages received from scheduling queue, continuing loop.")
-			continue // No need to sleep, long polling already waited
-		}
+// handleMessage gets a token for the Event Service and processes the message
+func (p *Processor) handleMessage(ctx context.Context, messageBody models.SQSMessageBody) error {
+	log.Printf("Processing SQS message from scheduling queue: %+v", messageBody)
 
-		log.Printf("Received %d messages from scheduling queue.", len(rawMessages))
-		var messagesToDelete []types.DeleteMessageBatchRequestEntry
-		var token string
-		var tokenErr error
-
-		// Process each message in the batch
-		for _, rawMessage := range rawMessages {
-			// Unmarshal and process each message individually
-			var messageBody models.SQSMessageBody
-			if err := json.Unmarshal([]byte(*rawMessage.Body), &messageBody); err != nil {
-				log.Printf("Error unmarshalling message body, will delete malformed message: %v", err)
-				// Add malformed message to the delete batch
-				messagesToDelete = append(messagesToDelete, types.DeleteMessageBatchRequestEntry{
-					Id:            rawMessage.MessageId,
-					ReceiptHandle: rawMessage.ReceiptHandle,
-				})
-				continue
-			}
-
-			log.Printf("Processing SQS message from scheduling queue: %+v", messageBody)
-
-			// Get token only once for the batch, if needed
-			if token == "" {
-				token, tokenErr = auth.GetM2MToken(p.cfg, p.httpClient)
-				if tokenErr != nil {
-					log.Printf("Error getting M2M token: %v. Will retry later.", tokenErr)
-					break // Skip processing the rest of the messages if we can't get a token
-				}
-			}
-
-			// Process the message based on its action
-			err = p.processSessionMessage(token, &messageBody)
-			if err != nil {
-				log.Printf("Error processing %s message for session %s, it will be retried: %v",
-					messageBody.Action, messageBody.SessionID, err)
-				// If processing fails, DO NOT add it to the delete batch.
-				// It will become visible again on the queue for another attempt.
-			} else {
-				log.Printf("Successfully processed %s message, adding to delete batch.", messageBody.Action)
-				// On success, add the message to our list of messages to delete.
-				messagesToDelete = append(messagesToDelete, types.DeleteMessageBatchRequestEntry{
-					Id:            rawMessage.MessageId,
-					ReceiptHandle: rawMessage.ReceiptHandle,
-				})
-			}
-		}
+	token, err := auth.GetM2MToken(p.cfg, p.httpClient)
+	if err != nil {
+		return fmt.Errorf("error getting M2M token: %w", err)
+	}
 
-		// After processing the whole batch, delete the successful ones in a single API call
-		if len(messagesToDelete) > 0 {
-			err := sqsutil.DeleteMessageBatch(p.queueURL, p.sqsClient, messagesToDelete)
-			if err != nil {
-				log.Printf("Error batch deleting messages: %v", err)
-			}
-		}
+	if err := p.processSessionMessage(ctx, token, &messageBody); err != nil {
+		return fmt.Errorf("error processing %s message for session %s: %w", messageBody.Action, messageBody.SessionID, err)
 	}
+	return nil
 }
 
 // processSessionMessage makes the API call to the Event Service to update the session status
-func (p *Processor) processSessionMessage(token string, msg *models.SQSMessageBody) error {
+func (p *Processor) processSessionMessage(ctx context.Context, token string, msg *models.SQSMessageBody) error {
 
 	var apiPath string
 	if msg.SessionID == "" || msg.Action == "" {
@@ -148,7 +91,7 @@ func (p *Processor) processSessionMessage(token string, msg *models.SQSMessageBo
 	apiURL := p.eventServiceURL + apiPath
 	log.Printf("Calling Event Service API: %s", apiURL)
 
-	req, _ := http.NewRequest("PATCH", apiURL, nil)
+	req, _ := http.NewRequestWithContext(ctx, "PATCH", apiURL, nil)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
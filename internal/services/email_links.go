@@ -0,0 +1,12 @@
+package services
+
+import (
+	"fmt"
+
+	"ms-scheduling/internal/config"
+)
+
+// generateSessionURL returns the frontend page of a session, linked from session emails
+func generateSessionURL(cfg *config.Config, eventID, sessionID string) string {
+	return fmt.Sprintf("%s/events/%s/%s", cfg.FrontendURL, eventID, sessionID)
+}
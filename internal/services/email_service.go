@@ -29,6 +29,12 @@ func NewEmailService(smtpHost, smtpPort, username, password, fromEmail, fromName
 
 // SendEmail sends an email using SMTP
 func (e *EmailService) SendEmail(to, subject, body string) error {
+	return e.SendEmailWithHeaders(to, "", subject, body, nil)
+}
+
+// SendEmailWithHeaders sends an email using SMTP with extra headers such as
+// List-Unsubscribe and List-Unsubscribe-Post (RFC 8058). The email type is not used by SMTP.
+func (e *EmailService) SendEmailWithHeaders(to, emailType, subject, body string, headers map[string]string) error {
 	// SMTP server configuration
 	smtpServer := fmt.Sprintf("%s:%s", e.SMTPHost, e.SMTPPort)
 
@@ -49,16 +55,24 @@ func (e *EmailService) SendEmail(to, subject, body string) error {
 		formattedBody = e.formatEmailBody(body) // Convert plain text to HTML
 	}
 
+	// Extra headers; line breaks are stripped so values cannot inject further headers
+	var extraHeaders strings.Builder
+	for name, value := range headers {
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		extraHeaders.WriteString(fmt.Sprintf("%s: %s\r\n", name, value))
+	}
+
 	// Compose message
 	msg := []byte(fmt.Sprintf(
 		"From: %s\r\n"+
 			"To: %s\r\n"+
 			"Subject: %s\r\n"+
+			"%s"+
 			"MIME-Version: 1.0\r\n"+
 			"Content-Type: text/html; charset=UTF-8\r\n"+
 			"\r\n"+
 			"%s\r\n",
-		from, to, subject, formattedBody))
+		from, to, subject, extraHeaders.String(), formattedBody))
 
 	// Verbose logging for debugging
 	log.Printf("[EmailService] Attempting to send email...")
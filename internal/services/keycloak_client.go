@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -158,3 +159,71 @@ func (k *KeycloakClient) GetUserDetails(userID string) (*KeycloakUserDetails, er
 
 	return &userDetails, nil
 }
+
+// GetGroupMemberEmails returns the email addresses of the members of the group at groupPath,
+// e.g. "/organizations/<id>/admins". A group that does not exist has no members.
+func (k *KeycloakClient) GetGroupMemberEmails(groupPath string) ([]string, error) {
+	token, err := k.getAdminToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin token: %v", err)
+	}
+
+	var group struct {
+		ID string `json:"id"`
+	}
+	groupURL := fmt.Sprintf("%s/admin/realms/%s/group-by-path/%s", k.BaseURL, k.Realm, strings.TrimPrefix(groupPath, "/"))
+	found, err := k.getAdminJSON(groupURL, token, &group)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up group %s: %w", groupPath, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	var members []KeycloakUser
+	membersURL := fmt.Sprintf("%s/admin/realms/%s/groups/%s/members?briefRepresentation=true&max=%d", k.BaseURL, k.Realm, group.ID, maxGroupMembers)
+	if _, err := k.getAdminJSON(membersURL, token, &members); err != nil {
+		return nil, fmt.Errorf("error listing members of group %s: %w", groupPath, err)
+	}
+
+	emails := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Email != "" {
+			emails = append(emails, member.Email)
+		}
+	}
+	return emails, nil
+}
+
+// maxGroupMembers bounds the members fetched per group; organization groups are small
+const maxGroupMembers = 100
+
+// getAdminJSON decodes the response of an admin API GET into out. It reports false if the resource does not exist.
+func (k *KeycloakClient) getAdminJSON(url, token string, out interface{}) (bool, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := k.HTTPClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("keycloak API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeycloak serves the token endpoint and a single owners group of organization org-1
+func fakeKeycloak(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/event-ticketing/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(KeycloakTokenResponse{AccessToken: "token"})
+	})
+	mux.HandleFunc("/admin/realms/event-ticketing/group-by-path/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin/realms/event-ticketing/group-by-path/organizations/org-1/owners" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "group-1"})
+	})
+	mux.HandleFunc("/admin/realms/event-ticketing/groups/group-1/members", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode([]KeycloakUser{
+			{ID: "u1", Email: "owner@example.com"},
+			{ID: "u2"},
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGetGroupMemberEmailsSkipsMembersWithoutEmail(t *testing.T) {
+	server := fakeKeycloak(t)
+	defer server.Close()
+	client := NewKeycloakClient(server.URL, "event-ticketing", "scheduler", "secret")
+
+	emails, err := client.GetGroupMemberEmails("/organizations/org-1/owners")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"owner@example.com"}, emails)
+}
+
+func TestGetGroupMemberEmailsReturnsNoneForMissingGroup(t *testing.T) {
+	server := fakeKeycloak(t)
+	defer server.Close()
+	client := NewKeycloakClient(server.URL, "event-ticketing", "scheduler", "secret")
+
+	emails, err := client.GetGroupMemberEmails("/organizations/org-1/admins")
+
+	require.NoError(t, err)
+	assert.Empty(t, emails)
+}
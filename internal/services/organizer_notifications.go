@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"ms-scheduling/internal/models"
+)
+
+// organizationIDPlaceholder is replaced with the organization ID in the organizer group paths
+const organizationIDPlaceholder = "{organizationId}"
+
+// SendEventReviewEmails tells the owners and admins of the event's organization that the event was
+// approved or rejected. source identifies the review so a redelivered change does not email them twice.
+func (s *SubscriberService) SendEventReviewEmails(event *models.Event, source string) error {
+	if event.Status != "APPROVED" && event.Status != "REJECTED" {
+		return nil
+	}
+
+	organizers, err := s.GetOrganizerEmails(event.OrganizationID)
+	if err != nil {
+		log.Printf("Error resolving organizers of organization %s: %v", event.OrganizationID, err)
+		return err
+	}
+	if len(organizers) == 0 {
+		log.Printf("No organizers found for organization %s - skipping %s email for event %s", event.OrganizationID, event.Status, event.ID)
+		return nil
+	}
+
+	organizationName := s.getOrganizationName(event.OrganizationID)
+	manager := s.EmailManager.WithSource(source)
+
+	var errs []error
+	for _, organizer := range organizers {
+		if event.Status == "APPROVED" {
+			err = manager.SendEventApprovedEmail(organizer, event, organizationName)
+		} else {
+			err = manager.SendEventRejectedEmail(organizer, event, organizationName)
+		}
+		if err != nil {
+			log.Printf("Error sending event %s email to organizer %s: %v", strings.ToLower(event.Status), organizer, err)
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Printf("Event %s email sent successfully to organizer: %s", strings.ToLower(event.Status), organizer)
+	}
+
+	// Emails already sent are deduplicated, so a retry only reaches the organizers that failed
+	return errors.Join(errs...)
+}
+
+// GetOrganizerEmails resolves the owners and admins of an organization from its Keycloak groups
+func (s *SubscriberService) GetOrganizerEmails(organizationID string) ([]string, error) {
+	if organizationID == "" || s.KeycloakClient == nil || s.Config == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var emails []string
+	for _, pathTemplate := range s.Config.OrganizerGroupPaths {
+		groupPath := strings.ReplaceAll(pathTemplate, organizationIDPlaceholder, organizationID)
+		members, err := s.KeycloakClient.GetGroupMemberEmails(groupPath)
+		if err != nil {
+			return nil, fmt.Errorf("error getting members of group %s: %w", groupPath, err)
+		}
+
+		// An owner can also be an admin, so each address is emailed once
+		for _, member := range members {
+			address := strings.ToLower(member)
+			if !seen[address] {
+				seen[address] = true
+				emails = append(emails, member)
+			}
+		}
+	}
+	return emails, nil
+}
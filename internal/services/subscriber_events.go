@@ -1,11 +1,11 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strings"
-	"time"
 
+	"ms-scheduling/internal/email"
 	"ms-scheduling/internal/models"
 )
 
@@ -97,141 +97,47 @@ func (s *SubscriberService) SendEventUpdateEmails(subscribers []models.Subscribe
 		organizationName = s.getOrganizationName(before.OrganizationID)
 	}
 
-	for _, subscriber := range subscribers {
-		var err error
-
-		switch operation {
-		case "d": // Deletion/Cancellation
-			if before != nil && s.EmailManager != nil {
-				err = s.EmailManager.SendEventCancelledEmail(subscriber.SubscriberMail, before, organizationName)
-			} else {
-				// Fallback to old method
-				subject, body := s.buildEventUpdateEmail(subscriber, eventUpdate)
-				err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-			}
-		case "u": // Update
-			if before != nil && after != nil && s.EmailManager != nil {
-				err = s.EmailManager.SendEventUpdatedEmail(subscriber.SubscriberMail, before, after, organizationName)
-			} else {
-				// Fallback to old method
-				subject, body := s.buildEventUpdateEmail(subscriber, eventUpdate)
-				err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-			}
-		default:
-			// For other operations, use old method
-			subject, body := s.buildEventUpdateEmail(subscriber, eventUpdate)
-			err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-		}
-
-		if err != nil {
-			log.Printf("Error sending event update email to %s: %v", subscriber.SubscriberMail, err)
-			continue
-		}
-
-		log.Printf("Event update email sent successfully to: %s", subscriber.SubscriberMail)
+	// Identify the source change so redelivered or replayed events don't send duplicates
+	eventID := eventUpdate.Payload.EventID
+	if eventID == "" && after != nil {
+		eventID = after.ID
+	} else if eventID == "" && before != nil {
+		eventID = before.ID
 	}
+	source := eventUpdate.Payload.Source.NotificationKey(operation, eventID)
 
-	return nil
-}
-
-func (s *SubscriberService) buildEventUpdateEmail(subscriber models.Subscriber, eventUpdate *models.DebeziumEventEvent) (string, string) {
-	after := eventUpdate.Payload.After
-	before := eventUpdate.Payload.Before
-	operation := eventUpdate.Payload.Operation
-
-	timestamp := time.UnixMilli(eventUpdate.Payload.Timestamp)
-
-	var subject string
-	var body strings.Builder
-
-	if operation == "d" {
+	var emailTemplate email.EmailTemplate
+	switch operation {
+	case "d": // Deletion/Cancellation
 		if before == nil {
-			return "", ""
+			return nil
 		}
-
-		subject = fmt.Sprintf("Event Cancelled: %s", before.Title)
-
-		body.WriteString("Dear Subscriber,\n\n")
-		body.WriteString("⚠️ IMPORTANT: An event you're subscribed to has been CANCELLED/DELETED:\n\n")
-
-		body.WriteString("Cancelled Event Details:\n")
-		body.WriteString(fmt.Sprintf("Event ID: %s\n", before.ID))
-		body.WriteString(fmt.Sprintf("Title: %s\n", before.Title))
-		body.WriteString(fmt.Sprintf("Description: %s\n", before.Description))
-		body.WriteString(fmt.Sprintf("Status: %s\n", before.Status))
-		body.WriteString(fmt.Sprintf("Created: %s\n", time.Unix(before.CreatedAt/1000000, 0).Format("2006-01-02 15:04:05")))
-		body.WriteString(fmt.Sprintf("Cancelled: %s\n\n", timestamp.Format("2006-01-02 15:04:05")))
-
-		body.WriteString("🔔 This event has been permanently removed from the schedule.\n")
-		body.WriteString("📧 If you had tickets for sessions in this event, please check your email for refund information or contact support.\n\n")
-
-	} else {
-		if after == nil {
-			return "", ""
+		emailTemplate = s.EmailManager.Templates().GenerateEventCancelledEmail(before, organizationName, s.unsubscribeURLPlaceholder())
+	case "u": // Update
+		if before == nil || after == nil {
+			return nil
 		}
+		emailTemplate = s.EmailManager.Templates().GenerateEventUpdatedEmail(before, after, organizationName, s.unsubscribeURLPlaceholder())
+	default:
+		// Approved new events are announced to organization subscribers by SendEventCreationEmails
+		log.Printf("No event update email for operation %s", operation)
+		return nil
+	}
 
-		subject = fmt.Sprintf("Event Update: %s", after.Title)
-
-		body.WriteString("Dear Subscriber,\n\n")
-		body.WriteString("An event you're subscribed to has been updated:\n\n")
-
-		body.WriteString(fmt.Sprintf("Event ID: %s\n", after.ID))
-		body.WriteString(fmt.Sprintf("Title: %s\n", after.Title))
-		body.WriteString(fmt.Sprintf("Description: %s\n", after.Description))
-		body.WriteString(fmt.Sprintf("Status: %s\n", after.Status))
-		body.WriteString(fmt.Sprintf("Created: %s\n", time.Unix(after.CreatedAt/1000000, 0).Format("2006-01-02 15:04:05")))
-		body.WriteString(fmt.Sprintf("Updated: %s\n\n", timestamp.Format("2006-01-02 15:04:05")))
-
-		if before != nil && operation == "u" {
-			body.WriteString("Changes:\n")
-
-			if before.Title != after.Title {
-				body.WriteString(fmt.Sprintf("• Title: %s → %s\n", before.Title, after.Title))
-			}
-
-			if before.Description != after.Description {
-				body.WriteString(fmt.Sprintf("• Description: %s → %s\n", before.Description, after.Description))
-			}
-
-			if before.Status != after.Status {
-				body.WriteString(fmt.Sprintf("• Status: %s → %s\n", before.Status, after.Status))
-			}
-
-			if before.Overview != after.Overview {
-				body.WriteString("• Overview: Updated\n")
-			}
-
-			if before.CategoryID != after.CategoryID {
-				body.WriteString("• Category: Updated\n")
-			}
-		} else if operation == "c" {
-			body.WriteString("New Event Details:\n")
-			body.WriteString(fmt.Sprintf("• Status: %s\n", after.Status))
-			if after.Overview != "" {
-				body.WriteString(fmt.Sprintf("• Overview: %s\n", after.Overview))
-			}
+	var errs []error
+	for _, subscriber := range subscribers {
+		err := s.sendSubscriptionEmail(subscriber, models.SubscriptionCategoryEvent, eventID, source, emailTemplate)
+		if err != nil {
+			log.Printf("Error sending event update email to %s: %v", subscriber.SubscriberMail, err)
+			errs = append(errs, err)
+			continue
 		}
 
-		if operation == "u" && before != nil && before.Status != after.Status {
-			body.WriteString("\n🔔 Status Change Notification:\n")
-			switch after.Status {
-			case "APPROVED":
-				body.WriteString("✅ This event has been APPROVED and is now available for booking!\n")
-			case "REJECTED":
-				body.WriteString("❌ This event has been REJECTED.")
-				if after.RejectionReason != "" {
-					body.WriteString(fmt.Sprintf(" Reason: %s", after.RejectionReason))
-				}
-				body.WriteString("\n")
-			case "PENDING":
-				body.WriteString("⏳ This event is now under review.\n")
-			}
-		}
+		log.Printf("Event update email sent successfully to: %s", subscriber.SubscriberMail)
 	}
 
-	body.WriteString("\nBest regards,\nTicketly Team")
-
-	return subject, body.String()
+	// Emails already sent are deduplicated, so a retry only reaches the subscribers that failed
+	return errors.Join(errs...)
 }
 
 func (s *SubscriberService) GetOrganizationSubscribers(organizationID string) ([]models.Subscriber, error) {
@@ -306,76 +212,32 @@ func (s *SubscriberService) SendEventCreationEmails(subscribers []models.Subscri
 		return nil
 	}
 
+	// Only approved events can be booked, so events still under review are not announced
+	if after.Status != "APPROVED" {
+		log.Printf("Event %s has status %s - creation email is sent once it is approved", after.ID, after.Status)
+		return nil
+	}
+
 	// Get organization name for context
 	organizationName := s.getOrganizationName(after.OrganizationID)
 
-	for _, subscriber := range subscribers {
-		var err error
+	// Identify the source change so redelivered or replayed events don't send duplicates
+	source := eventUpdate.Payload.Source.NotificationKey(eventUpdate.Payload.Operation, after.ID)
 
-		// Check if this is an approval (PENDING -> APPROVED) or initial creation with APPROVED status
-		if after.Status == "APPROVED" && s.EmailManager != nil {
-			err = s.EmailManager.SendEventCreatedEmail(subscriber.SubscriberMail, after, organizationName)
-		} else {
-			// Fallback to old method or skip if not approved
-			subject, body := s.buildEventCreationEmail(subscriber, eventUpdate)
-			if subject != "" {
-				err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-			}
-		}
+	emailTemplate := s.EmailManager.Templates().GenerateEventCreatedEmail(after, organizationName, s.unsubscribeURLPlaceholder())
 
+	var errs []error
+	for _, subscriber := range subscribers {
+		err := s.sendSubscriptionEmail(subscriber, models.SubscriptionCategoryOrganization, after.OrganizationID, source, emailTemplate)
 		if err != nil {
 			log.Printf("Error sending event creation email to %s: %v", subscriber.SubscriberMail, err)
+			errs = append(errs, err)
 			continue
 		}
 
 		log.Printf("Event creation email sent successfully to: %s", subscriber.SubscriberMail)
 	}
 
-	return nil
-}
-
-func (s *SubscriberService) buildEventCreationEmail(subscriber models.Subscriber, eventUpdate *models.DebeziumEventEvent) (string, string) {
-	after := eventUpdate.Payload.After
-
-	timestamp := time.UnixMilli(eventUpdate.Payload.Timestamp)
-	createdAt := models.MicroTimestampToTime(after.CreatedAt)
-
-	subject := fmt.Sprintf("🎉 New Event Created: %s", after.Title)
-
-	var body strings.Builder
-	body.WriteString(fmt.Sprintf("Hello %s,\n\n", subscriber.SubscriberMail))
-	body.WriteString("🎉 A new event has been created in your subscribed organization!\n\n")
-
-	body.WriteString("Event Details:\n")
-	body.WriteString(fmt.Sprintf("• Title: %s\n", after.Title))
-	body.WriteString(fmt.Sprintf("• Status: %s\n", after.Status))
-
-	if after.Description != "" {
-		body.WriteString(fmt.Sprintf("• Description: %s\n", after.Description))
-	}
-
-	if after.Overview != "" {
-		body.WriteString(fmt.Sprintf("• Overview: %s\n", after.Overview))
-	}
-
-	body.WriteString(fmt.Sprintf("• Created: %s\n", createdAt.Format("2006-01-02 15:04:05")))
-	body.WriteString(fmt.Sprintf("• Event ID: %s\n", after.ID))
-	body.WriteString(fmt.Sprintf("• Organization ID: %s\n", after.OrganizationID))
-
-	if after.CategoryID != "" {
-		body.WriteString(fmt.Sprintf("• Category ID: %s\n", after.CategoryID))
-	}
-
-	body.WriteString(fmt.Sprintf("\n📅 Notification sent at: %s\n", timestamp.Format("2006-01-02 15:04:05")))
-
-	if after.Status == "PENDING" {
-		body.WriteString("\n⏳ This event is currently pending approval. You'll be notified when it's approved and ready for booking.\n")
-	} else if after.Status == "APPROVED" {
-		body.WriteString("\n✅ This event is approved and ready for booking!\n")
-	}
-
-	body.WriteString("\nStay tuned for more updates about this event!")
-	body.WriteString("\n\nBest regards,\nTicketly Team")
-
-	return subject, body.String()
+	// Emails already sent are deduplicated, so a retry only reaches the subscribers that failed
+	return errors.Join(errs...)
 }
@@ -11,44 +11,22 @@ import (
 func (s *SubscriberService) SendOrderConfirmationEmail(subscriber *models.Subscriber, order *OrderCreatedEvent) error {
 	log.Printf("Sending order email to %s for order %s with status %s", subscriber.SubscriberMail, order.OrderID, order.Status)
 
-	// Convert to OrderData format for new template system
-	if s.EmailManager != nil {
-		orderData := convertToOrderData(order)
+	// Each order status is only announced once, even if the order event is redelivered
+	source := order.NotificationKey()
 
-		var err error
-		switch order.Status {
-		case "completed":
-			err = s.EmailManager.SendOrderConfirmedEmail(subscriber.SubscriberMail, orderData)
-		case "pending":
-			err = s.EmailManager.SendOrderPendingEmail(subscriber.SubscriberMail, orderData)
-		case "cancelled":
-			err = s.EmailManager.SendOrderCancelledEmail(subscriber.SubscriberMail, orderData)
-		case "processing":
-			err = s.EmailManager.SendOrderUpdatedEmail(subscriber.SubscriberMail, orderData)
-		default:
-			err = s.EmailManager.SendOrderPendingEmail(subscriber.SubscriberMail, orderData)
-		}
-
-		return err
-	}
+	orderData := convertToOrderData(order)
+	manager := s.EmailManager.WithSource(source)
 
-	// Fallback to old template system
-	var emailType EmailType
 	switch order.Status {
 	case "completed":
-		emailType = EmailOrderConfirmed
-	case "pending":
-		emailType = EmailOrderPending
+		return manager.SendOrderConfirmedEmail(subscriber.SubscriberMail, orderData)
 	case "cancelled":
-		emailType = EmailOrderCancelled
+		return manager.SendOrderCancelledEmail(subscriber.SubscriberMail, orderData)
 	case "processing":
-		emailType = EmailOrderProcessing
+		return manager.SendOrderUpdatedEmail(subscriber.SubscriberMail, orderData)
 	default:
-		emailType = EmailOrderPending
+		return manager.SendOrderPendingEmail(subscriber.SubscriberMail, orderData)
 	}
-
-	emailTemplate := GenerateEmailTemplate(s.Config, emailType, order)
-	return s.EmailService.SendEmail(subscriber.SubscriberMail, emailTemplate.Subject, emailTemplate.HTML)
 }
 
 // OrderCreatedEvent represents the structure of the order.created Kafka event
@@ -69,6 +47,11 @@ type OrderCreatedEvent struct {
 	Tickets        []Ticket `json:"tickets"`
 }
 
+// NotificationKey identifies the order status change for notification dedupe
+func (o *OrderCreatedEvent) NotificationKey() string {
+	return "order:" + o.OrderID + ":" + o.Status
+}
+
 type Ticket struct {
 	TicketID        string  `json:"ticket_id"`
 	OrderID         string  `json:"order_id"`
@@ -1,211 +1,107 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+
+	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/email/templates"
 	"ms-scheduling/internal/models"
-	"net/url"
-	"strings"
 )
 
-// SendSessionReminderEmails sends generic reminder emails to all subscribers
-func (s *SubscriberService) SendSessionReminderEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo) error {
+// SendSessionReminderEmails sends generic reminder emails to all subscribers.
+// source identifies the reminder so a redelivered reminder does not send it twice.
+func (s *SubscriberService) SendSessionReminderEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo, source string) error {
 	log.Printf("Sending generic session reminder emails to %d subscribers", len(subscribers))
 
-	// Generate email template using our new template system
-	emailTemplate := generateSessionStartReminderEmail(s.Config, sessionInfo)
+	// The generic reminder shows the start reminder but keeps its own type for preferences
+	emailTemplate := s.EmailManager.Templates().GenerateSessionStartReminderEmail(s.sessionReminderData(sessionInfo))
+	emailTemplate.Type = email.EmailSessionReminder
 
-	for _, subscriber := range subscribers {
-		err := s.EmailService.SendEmail(subscriber.SubscriberMail, emailTemplate.Subject, emailTemplate.HTML)
-		if err != nil {
-			log.Printf("Error sending session reminder email to %s: %v", subscriber.SubscriberMail, err)
-			// Continue with other subscribers even if one fails
-			continue
-		}
-
-		log.Printf("Session reminder email sent successfully to: %s", subscriber.SubscriberMail)
-	}
-
-	return nil
+	return s.sendSessionEmails(subscribers, sessionInfo.SessionID, source, emailTemplate, "session reminder")
 }
 
-// SendSessionStartReminderEmails sends session start reminder emails (1 day before)
-func (s *SubscriberService) SendSessionStartReminderEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo) error {
-	log.Printf("Sending session START reminder emails to %d subscribers (1 day before)", len(subscribers))
-
-	// Generate email template using our new template system
-	emailTemplate := generateSessionStartReminderEmail(s.Config, sessionInfo)
+// SendSessionStartReminderEmails sends session start reminder emails (at the offsets of the reminder rules).
+// source identifies the reminder so a redelivered reminder does not send it twice.
+func (s *SubscriberService) SendSessionStartReminderEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo, source string) error {
+	log.Printf("Sending session START reminder emails to %d subscribers", len(subscribers))
 
-	for _, subscriber := range subscribers {
-		err := s.EmailService.SendEmail(subscriber.SubscriberMail, emailTemplate.Subject, emailTemplate.HTML)
-		if err != nil {
-			log.Printf("Error sending session start reminder email to %s: %v", subscriber.SubscriberMail, err)
-			// Continue with other subscribers even if one fails
-			continue
-		}
+	emailTemplate := s.EmailManager.Templates().GenerateSessionStartReminderEmail(s.sessionReminderData(sessionInfo))
 
-		log.Printf("Session start reminder email sent successfully to: %s", subscriber.SubscriberMail)
-	}
-
-	return nil
+	return s.sendSessionEmails(subscribers, sessionInfo.SessionID, source, emailTemplate, "session start reminder")
 }
 
-// SendSessionSalesReminderEmails sends sales start reminder emails (30 min before)
-func (s *SubscriberService) SendSessionSalesReminderEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo) error {
+// SendSessionSalesReminderEmails sends sales start reminder emails (at the offsets of the reminder rules).
+// source identifies the reminder so a redelivered reminder does not send it twice.
+func (s *SubscriberService) SendSessionSalesReminderEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo, source string) error {
 	log.Printf("Sending session SALES reminder emails to %d subscribers", len(subscribers))
 
-	// Generate email template using our new template system
-	emailTemplate := generateSessionSalesReminderEmail(s.Config, sessionInfo)
+	emailTemplate := s.EmailManager.Templates().GenerateSessionSalesReminderEmail(s.sessionReminderData(sessionInfo))
+
+	return s.sendSessionEmails(subscribers, sessionInfo.SessionID, source, emailTemplate, "sales start reminder")
+}
 
+// SendSessionFollowUpEmails thanks the ticket holders of a session that ended and asks them for feedback.
+// source identifies the follow-up so a redelivered reminder does not send it twice.
+func (s *SubscriberService) SendSessionFollowUpEmails(subscribers []models.Subscriber, sessionInfo *SessionReminderInfo, source string) error {
+	log.Printf("Sending session follow-up emails to %d subscribers", len(subscribers))
+
+	emailTemplate := s.EmailManager.Templates().GenerateSessionFollowUpEmail(&templates.SessionFollowUpData{
+		SessionID:        sessionInfo.SessionID,
+		EventTitle:       sessionInfo.EventTitle,
+		OrganizationName: sessionInfo.OrganizationName,
+		StartTime:        models.MicroTimestampToTime(sessionInfo.StartTime),
+		EndTime:          models.MicroTimestampToTime(sessionInfo.EndTime),
+		FeedbackURL:      generateSessionURL(s.Config, sessionInfo.EventID, sessionInfo.SessionID) + "/feedback",
+		UnsubscribeURL:   s.unsubscribeURLPlaceholder(),
+	})
+
+	return s.sendSessionEmails(subscribers, sessionInfo.SessionID, source, emailTemplate, "session follow-up")
+}
+
+// sendSessionEmails sends an email rendered once to every session subscriber, each with their own
+// unsubscribe link. A failure for one subscriber does not stop the others; the failures are returned
+// together so the reminder is retried.
+func (s *SubscriberService) sendSessionEmails(subscribers []models.Subscriber, sessionID, source string, emailTemplate email.EmailTemplate, label string) error {
+	var errs []error
 	for _, subscriber := range subscribers {
-		err := s.EmailService.SendEmail(subscriber.SubscriberMail, emailTemplate.Subject, emailTemplate.HTML)
+		err := s.sendSubscriptionEmail(subscriber, models.SubscriptionCategorySession, sessionID, source, emailTemplate)
 		if err != nil {
-			log.Printf("Error sending sales start reminder email to %s: %v", subscriber.SubscriberMail, err)
-			// Continue with other subscribers even if one fails
+			log.Printf("Error sending %s email to %s: %v", label, subscriber.SubscriberMail, err)
+			errs = append(errs, err)
 			continue
 		}
 
-		log.Printf("Sales start reminder email sent successfully to: %s", subscriber.SubscriberMail)
+		log.Printf("%s email sent successfully to: %s", label, subscriber.SubscriberMail)
 	}
 
-	return nil
+	// Emails already sent are deduplicated, so a retry only reaches the subscribers that failed
+	return errors.Join(errs...)
 }
 
-// Note: This function is being deprecated in favor of email templates in email_common_templates.go
-// TODO: Update SendSessionReminderEmails to use GenerateEmailTemplate instead
-// buildSessionReminderEmail creates the email content for session reminders
-func (s *SubscriberService) buildSessionReminderEmail(subscriber models.Subscriber, sessionInfo *SessionReminderInfo) (string, string) {
-	// Convert timestamps to readable format
-	startTime := models.MicroTimestampToTime(sessionInfo.StartTime)
-	endTime := models.MicroTimestampToTime(sessionInfo.EndTime)
-
-	// Get subscriber name if possible
-	subscriberName := ""
-	if subscriber.UserID != nil && *subscriber.UserID != "" {
-		// Try to get user details from Keycloak
-		userDetails, err := s.KeycloakClient.GetUserDetails(*subscriber.UserID)
-		if err == nil && userDetails != nil {
-			if userDetails.FirstName != "" && userDetails.LastName != "" {
-				subscriberName = fmt.Sprintf("%s %s", userDetails.FirstName, userDetails.LastName)
-			} else if userDetails.FirstName != "" {
-				subscriberName = userDetails.FirstName
-			}
-		} else {
-			log.Printf("Failed to get Keycloak user details: %v", err)
-		}
+// sessionReminderData converts SessionReminderInfo to the data of the reminder templates
+func (s *SubscriberService) sessionReminderData(sessionInfo *SessionReminderInfo) *templates.SessionReminderData {
+	data := &templates.SessionReminderData{
+		SessionID:        sessionInfo.SessionID,
+		EventTitle:       sessionInfo.EventTitle,
+		Status:           sessionInfo.Status,
+		StartTime:        models.MicroTimestampToTime(sessionInfo.StartTime),
+		EndTime:          models.MicroTimestampToTime(sessionInfo.EndTime),
+		SalesStartTime:   models.MicroTimestampToTime(sessionInfo.SalesStartTime),
+		VenueDetails:     sessionInfo.VenueDetails,
+		EventOverview:    sessionInfo.EventOverview,
+		EventDescription: sessionInfo.EventDescription,
+		OrganizationName: sessionInfo.OrganizationName,
+		OrganizationLogo: sessionInfo.OrganizationLogo,
+		SessionURL:       generateSessionURL(s.Config, sessionInfo.EventID, sessionInfo.SessionID),
+		CalendarURL:      fmt.Sprintf("%s/calendar/event-%s.ics", s.Config.FrontendURL, sessionInfo.SessionID),
+		UnsubscribeURL:   s.unsubscribeURLPlaceholder(),
 	}
-
-	// Use email as fallback if name not available
-	if subscriberName == "" {
-		// Extract name from email if possible
-		emailParts := strings.Split(subscriber.SubscriberMail, "@")
-		subscriberName = emailParts[0]
-	}
-
-	var eventTitle string
-	if sessionInfo.EventTitle != "" {
-		eventTitle = sessionInfo.EventTitle
-	} else {
-		eventTitle = "Your Event"
+	if len(sessionInfo.EventCoverPhotos) > 0 {
+		data.CoverPhotoURL = sessionInfo.EventCoverPhotos[0]
 	}
-
-	subject := fmt.Sprintf("🔔 Reminder: %s is tomorrow!", eventTitle)
-
-	// Calculate session duration
-	duration := endTime.Sub(startTime)
-	durationHours := int(duration.Hours())
-	durationMinutes := int(duration.Minutes()) % 60
-
-	// Format duration string
-	var durationStr string
-	if durationHours > 0 {
-		if durationMinutes > 0 {
-			durationStr = fmt.Sprintf("%d hours %d minutes", durationHours, durationMinutes)
-		} else {
-			durationStr = fmt.Sprintf("%d hours", durationHours)
-		}
-	} else {
-		durationStr = fmt.Sprintf("%d minutes", durationMinutes)
-	}
-
-	// Format date and time more user-friendly
-	dateStr := startTime.Format("Monday, January 2, 2006")
-	startTimeStr := startTime.Format("3:04 PM")
-	endTimeStr := endTime.Format("3:04 PM")
-
-	// Generate calendar links
-	calendarMsg := "\n<p><strong>📱 Add to Calendar:</strong> "
-	calendarMsg += fmt.Sprintf("<a href=\"https://calendar.google.com/calendar/render?action=TEMPLATE&text=%s&dates=%s/%s&details=%s at %s&location=%s\">Google Calendar</a> | ",
-		url.QueryEscape(eventTitle),
-		startTime.Format("20060102T150405"),
-		endTime.Format("20060102T150405"),
-		url.QueryEscape(eventTitle),
-		url.QueryEscape(sessionInfo.VenueDetails),
-		url.QueryEscape(sessionInfo.VenueDetails))
-	calendarMsg += fmt.Sprintf("<a href=\"webcal://ticketly.com/calendar/event-%s.ics\">Apple Calendar</a></p>", sessionInfo.SessionID)
-
-	// Build HTML email body
-	var body strings.Builder
-	body.WriteString(fmt.Sprintf("<h2>Hello %s!</h2>", subscriberName))
-	body.WriteString("<p><strong>🔔 This is a friendly reminder that you have a session starting tomorrow!</strong></p>")
-
-	body.WriteString("<div style=\"background-color: #f8f9fa; padding: 15px; border-radius: 5px; margin: 20px 0;\">")
-	body.WriteString("<h3 style=\"color: #007bff; margin-top: 0;\">Session Details</h3>")
-
-	// Event info section
-	body.WriteString("<div style=\"margin-bottom: 20px;\">")
-	if sessionInfo.EventTitle != "" {
-		body.WriteString(fmt.Sprintf("<h4 style=\"margin-bottom: 5px;\">%s</h4>", sessionInfo.EventTitle))
-	}
-	body.WriteString(fmt.Sprintf("<p><strong>Type:</strong> %s</p>", sessionInfo.SessionType))
-	body.WriteString(fmt.Sprintf("<p><strong>Date:</strong> %s</p>", dateStr))
-	body.WriteString(fmt.Sprintf("<p><strong>Time:</strong> %s - %s (%s)</p>", startTimeStr, endTimeStr, durationStr))
-
-	// Add venue details if available
-	if sessionInfo.VenueDetails != "" {
-		body.WriteString(fmt.Sprintf("<p><strong>Location:</strong> %s</p>", sessionInfo.VenueDetails))
-	}
-
-	// Status-specific messaging
-	if sessionInfo.Status == "ON_SALE" {
-		body.WriteString("<p><span style=\"color: #28a745; font-weight: bold;\">🎫 TICKETS ON SALE NOW</span> - Don't forget to purchase your tickets!</p>")
-	} else if sessionInfo.Status == "SOLD_OUT" {
-		body.WriteString("<p><span style=\"color: #dc3545; font-weight: bold;\">SOLD OUT</span> - This session is sold out.</p>")
-	} else if sessionInfo.Status == "PENDING" {
-		body.WriteString("<p><span style=\"color: #ffc107; font-weight: bold;\">⏳ PENDING CONFIRMATION</span> - We'll update you if there are any changes.</p>")
-	} else if sessionInfo.Status == "CONFIRMED" {
-		body.WriteString("<p><span style=\"color: #28a745; font-weight: bold;\">✅ CONFIRMED</span> - This session is confirmed to take place as scheduled.</p>")
-	}
-	body.WriteString("</div>")
-
-	// Session ID for reference
-	body.WriteString(fmt.Sprintf("<p style=\"font-size: 12px; color: #6c757d;\">Reference #: %s</p>", sessionInfo.SessionID))
-	body.WriteString("</div>")
-
-	// Add countdown and calendar links
-	body.WriteString("<p style=\"font-size: 18px; font-weight: bold; color: #007bff;\">⏰ This session starts in approximately 24 hours!</p>")
-	body.WriteString(calendarMsg)
-
-	// Add checklist and recommendations
-	body.WriteString("<div style=\"background-color: #e9ecef; padding: 15px; border-radius: 5px; margin: 20px 0;\">")
-	body.WriteString("<h4>📋 Pre-Session Checklist:</h4>")
-	body.WriteString("<ul>")
-	body.WriteString("<li>Set a reminder on your phone</li>")
-	body.WriteString("<li>Check the venue location and plan your route</li>")
-	body.WriteString("<li>Prepare any required documents or tickets</li>")
-	body.WriteString("<li>Plan your travel time with extra buffer</li>")
-	body.WriteString("</ul>")
-	body.WriteString("</div>")
-
-	body.WriteString("<p>We're excited to see you tomorrow! 🎉</p>")
-	body.WriteString("<p>Best regards,<br>The Ticketly Team</p>")
-
-	// Unsubscribe option
-	body.WriteString("<p style=\"font-size: 12px; color: #6c757d; margin-top: 30px;\">")
-	body.WriteString(fmt.Sprintf("To unsubscribe from these notifications, <a href=\"https://ticketly.com/unsubscribe/%s\">click here</a>.", sessionInfo.SessionID))
-	body.WriteString("</p>")
-
-	return subject, body.String()
+	return data
 }
 
 // SessionReminderInfo holds session information for reminder emails
@@ -227,4 +123,3 @@ type SessionReminderInfo struct {
 	OrganizationLogo string
 	CategoryName     string
 }
-
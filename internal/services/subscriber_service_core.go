@@ -6,32 +6,62 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"ms-scheduling/internal/auth"
 	"ms-scheduling/internal/config"
 	"ms-scheduling/internal/email"
+	"ms-scheduling/internal/models"
 	"net/http"
+	"strings"
 )
 
 type SubscriberService struct {
 	DB             *sql.DB
 	KeycloakClient *KeycloakClient
-	EmailService   *EmailService
 	EmailManager   *email.EmailManager
+	Unsubscribe    *auth.UnsubscribeSigner
 	Config         *config.Config
 }
 
-func NewSubscriberService(db *sql.DB, keycloakClient *KeycloakClient, emailService *EmailService, cfg *config.Config) *SubscriberService {
+// NewSubscriberService creates a subscriber service that renders and sends every email through emailManager
+func NewSubscriberService(db *sql.DB, keycloakClient *KeycloakClient, emailManager *email.EmailManager, cfg *config.Config) *SubscriberService {
 	return &SubscriberService{
 		DB:             db,
 		KeycloakClient: keycloakClient,
-		EmailService:   emailService,
-		EmailManager:   nil, // Will be set later to avoid circular dependencies
+		EmailManager:   emailManager,
 		Config:         cfg,
 	}
 }
 
-// SetEmailManager sets the email manager (used to avoid circular dependencies)
-func (s *SubscriberService) SetEmailManager(emailManager *email.EmailManager) {
-	s.EmailManager = emailManager
+// SetUnsubscribeSigner enables signed one-click unsubscribe links in subscription emails
+func (s *SubscriberService) SetUnsubscribeSigner(signer *auth.UnsubscribeSigner) {
+	s.Unsubscribe = signer
+}
+
+// unsubscribeURLPlaceholder is the unsubscribe link passed to templates rendered once for all subscribers.
+// Without a signer there is no link that works, so templates leave the unsubscribe footer out.
+func (s *SubscriberService) unsubscribeURLPlaceholder() string {
+	if s.Unsubscribe == nil {
+		return ""
+	}
+	return email.UnsubscribeURLPlaceholder
+}
+
+// sendSubscriptionEmail sends an email caused by a subscription. The unsubscribe placeholder in the template
+// is replaced with a signed link for this subscriber, which is also sent as List-Unsubscribe header.
+// A non-empty source identifies the change that triggered the email and is used to skip duplicates.
+func (s *SubscriberService) sendSubscriptionEmail(subscriber models.Subscriber, category models.SubscriptionCategory, targetUUID, source string, template email.EmailTemplate) error {
+	var headers map[string]string
+
+	if s.Unsubscribe != nil {
+		unsubscribeURL, err := s.Unsubscribe.URL(subscriber.SubscriberID, category, targetUUID)
+		if err != nil {
+			return fmt.Errorf("error creating unsubscribe link for subscriber %d: %w", subscriber.SubscriberID, err)
+		}
+		template.HTML = strings.ReplaceAll(template.HTML, email.UnsubscribeURLPlaceholder, unsubscribeURL)
+		headers = email.UnsubscribeHeaders(unsubscribeURL)
+	}
+
+	return s.EmailManager.WithSource(source).SendEmailWithHeaders(subscriber.SubscriberMail, template, headers)
 }
 
 // getEventTitle fetches the event title from the database
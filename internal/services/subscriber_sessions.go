@@ -2,12 +2,11 @@ package services
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
-	"time"
 
+	"ms-scheduling/internal/email"
 	"ms-scheduling/internal/models"
 )
 
@@ -88,24 +87,24 @@ func (s *SubscriberService) ProcessSessionUpdate(sessionUpdate *models.DebeziumS
 		return fmt.Errorf("error getting session subscribers: %w", err)
 	}
 
-	// Notify event subscribers about new session creations
+	// Notify event subscribers about new session creations. A failure does not stop the session
+	// subscribers from being notified; both are returned so the change is retried.
+	var creationErr error
 	if sessionUpdate.Payload.Operation == "c" && eventID != "" {
 		eventSubscribers, err := s.GetEventSubscribers(eventID)
 		if err != nil {
-			log.Printf("Error getting event subscribers for new session notification: %v", err)
+			creationErr = fmt.Errorf("error getting event subscribers for new session notification: %w", err)
 		} else if len(eventSubscribers) > 0 {
-			if err := s.SendSessionCreationEmails(eventSubscribers, sessionUpdate); err != nil {
-				log.Printf("Error sending session creation emails: %v", err)
-			}
+			creationErr = s.SendSessionCreationEmails(eventSubscribers, sessionUpdate)
 		}
 	}
 
 	if len(subscribers) == 0 {
 		log.Printf("No subscribers found for session ID: %s", sessionID)
-		return nil
+		return creationErr
 	}
 
-	return s.SendSessionUpdateEmails(subscribers, sessionUpdate)
+	return errors.Join(creationErr, s.SendSessionUpdateEmails(subscribers, sessionUpdate))
 }
 
 func (s *SubscriberService) SendSessionUpdateEmails(subscribers []models.Subscriber, sessionUpdate *models.DebeziumSessionEvent) error {
@@ -123,41 +122,48 @@ func (s *SubscriberService) SendSessionUpdateEmails(subscribers []models.Subscri
 		eventTitle = s.getEventTitle(before.EventID)
 	}
 
-	for _, subscriber := range subscribers {
-		var err error
-
-		switch operation {
-		case "d": // Deletion/Cancellation
-			if before != nil && s.EmailManager != nil {
-				err = s.EmailManager.SendSessionCancelledEmail(subscriber.SubscriberMail, before, eventTitle)
-			} else {
-				// Fallback to old method
-				subject, body := s.buildSessionUpdateEmail(subscriber, sessionUpdate)
-				err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-			}
-		case "u": // Update
-			if before != nil && after != nil && s.EmailManager != nil {
-				err = s.EmailManager.SendSessionUpdatedEmail(subscriber.SubscriberMail, before, after, eventTitle)
-			} else {
-				// Fallback to old method
-				subject, body := s.buildSessionUpdateEmail(subscriber, sessionUpdate)
-				err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-			}
-		default:
-			// For other operations, use old method
-			subject, body := s.buildSessionUpdateEmail(subscriber, sessionUpdate)
-			err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
+	// Identify the source change so redelivered or replayed events don't send duplicates
+	sessionID := sessionUpdate.Payload.SessionID
+	if sessionID == "" && after != nil {
+		sessionID = after.ID
+	} else if sessionID == "" && before != nil {
+		sessionID = before.ID
+	}
+	source := sessionUpdate.Payload.Source.NotificationKey(operation, sessionID)
+
+	var emailTemplate email.EmailTemplate
+	switch operation {
+	case "d": // Deletion/Cancellation
+		if before == nil {
+			return nil
+		}
+		emailTemplate = s.EmailManager.Templates().GenerateSessionCancelledEmail(before, eventTitle, s.unsubscribeURLPlaceholder())
+	case "u": // Update
+		if before == nil || after == nil {
+			return nil
 		}
+		emailTemplate = s.EmailManager.Templates().GenerateSessionUpdatedEmail(before, after, eventTitle, s.unsubscribeURLPlaceholder())
+	default:
+		// New sessions are announced to event subscribers by SendSessionCreationEmails and
+		// snapshot reads are not changes, so neither is sent to session subscribers
+		log.Printf("No session update email for operation %s", operation)
+		return nil
+	}
 
+	var errs []error
+	for _, subscriber := range subscribers {
+		err := s.sendSubscriptionEmail(subscriber, models.SubscriptionCategorySession, sessionID, source, emailTemplate)
 		if err != nil {
 			log.Printf("Error sending session update email to %s: %v", subscriber.SubscriberMail, err)
+			errs = append(errs, err)
 			continue
 		}
 
 		log.Printf("Session update email sent successfully to: %s", subscriber.SubscriberMail)
 	}
 
-	return nil
+	// Emails already sent are deduplicated, so a retry only reaches the subscribers that failed
+	return errors.Join(errs...)
 }
 
 func (s *SubscriberService) SendSessionCreationEmails(subscribers []models.Subscriber, sessionUpdate *models.DebeziumSessionEvent) error {
@@ -171,166 +177,23 @@ func (s *SubscriberService) SendSessionCreationEmails(subscribers []models.Subsc
 	// Get event title for context
 	eventTitle := s.getEventTitle(after.EventID)
 
-	for _, subscriber := range subscribers {
-		var err error
+	// Identify the source change so redelivered or replayed events don't send duplicates
+	source := sessionUpdate.Payload.Source.NotificationKey(sessionUpdate.Payload.Operation, after.ID)
 
-		if s.EmailManager != nil {
-			err = s.EmailManager.SendSessionCreatedEmail(subscriber.SubscriberMail, after, eventTitle)
-		} else {
-			// Fallback to old method
-			subject, body := s.buildSessionCreationEmail(subscriber, sessionUpdate)
-			if subject == "" {
-				continue
-			}
-			err = s.EmailService.SendEmail(subscriber.SubscriberMail, subject, body)
-		}
+	emailTemplate := s.EmailManager.Templates().GenerateSessionCreatedEmail(after, eventTitle, s.unsubscribeURLPlaceholder())
 
+	var errs []error
+	for _, subscriber := range subscribers {
+		err := s.sendSubscriptionEmail(subscriber, models.SubscriptionCategoryEvent, after.EventID, source, emailTemplate)
 		if err != nil {
 			log.Printf("Error sending session creation email to %s: %v", subscriber.SubscriberMail, err)
+			errs = append(errs, err)
 			continue
 		}
 
 		log.Printf("Session creation email sent successfully to: %s", subscriber.SubscriberMail)
 	}
 
-	return nil
-}
-
-func (s *SubscriberService) buildSessionCreationEmail(subscriber models.Subscriber, sessionUpdate *models.DebeziumSessionEvent) (string, string) {
-	after := sessionUpdate.Payload.After
-	if after == nil {
-		return "", ""
-	}
-
-	subject := fmt.Sprintf("New Session Available for Event %s", after.EventID)
-
-	start := time.Unix(after.StartTime/1000000, 0)
-	end := time.Unix(after.EndTime/1000000, 0)
-
-	var body strings.Builder
-	body.WriteString("Hello,\n\n")
-	body.WriteString("A new session has been added to an event you're following:\n\n")
-	body.WriteString(fmt.Sprintf("Session ID: %s\n", after.ID))
-	body.WriteString(fmt.Sprintf("Event ID: %s\n", after.EventID))
-	body.WriteString(fmt.Sprintf("Status: %s\n", after.Status))
-	body.WriteString(fmt.Sprintf("Session Type: %s\n", after.SessionType))
-	body.WriteString(fmt.Sprintf("Start Time: %s\n", start.Format("2006-01-02 15:04:05")))
-	body.WriteString(fmt.Sprintf("End Time: %s\n\n", end.Format("2006-01-02 15:04:05")))
-
-	if after.VenueDetails != "" {
-		body.WriteString("Venue Details:\n")
-		var venue map[string]interface{}
-		if err := json.Unmarshal([]byte(after.VenueDetails), &venue); err == nil {
-			for key, value := range venue {
-				body.WriteString(fmt.Sprintf("• %s: %v\n", strings.Title(key), value))
-			}
-		} else {
-			body.WriteString(after.VenueDetails + "\n")
-		}
-		body.WriteString("\n")
-	}
-
-	body.WriteString("We hope to see you there!\n\n")
-	body.WriteString("Best regards,\nTicketly Team\n")
-
-	return subject, body.String()
-}
-
-func (s *SubscriberService) buildSessionUpdateEmail(subscriber models.Subscriber, sessionUpdate *models.DebeziumSessionEvent) (string, string) {
-	after := sessionUpdate.Payload.After
-	before := sessionUpdate.Payload.Before
-	operation := sessionUpdate.Payload.Operation
-
-	timestamp := time.UnixMilli(sessionUpdate.Payload.Timestamp)
-
-	var subject string
-	var body strings.Builder
-
-	if operation == "d" {
-		if before == nil {
-			return "", ""
-		}
-
-		subject = fmt.Sprintf("Session Cancelled: Session %s", before.ID)
-
-		body.WriteString("Dear Subscriber,\n\n")
-		body.WriteString("⚠️ IMPORTANT: A session you're subscribed to has been CANCELLED/DELETED:\n\n")
-
-		body.WriteString("Cancelled Session Details:\n")
-		body.WriteString(fmt.Sprintf("Session ID: %s\n", before.ID))
-		body.WriteString(fmt.Sprintf("Event ID: %s\n", before.EventID))
-		body.WriteString(fmt.Sprintf("Status: %s\n", before.Status))
-		body.WriteString(fmt.Sprintf("Session Type: %s\n", before.SessionType))
-		body.WriteString(fmt.Sprintf("Start Time: %s\n", time.Unix(before.StartTime/1000000, 0).Format("2006-01-02 15:04:05")))
-		body.WriteString(fmt.Sprintf("End Time: %s\n", time.Unix(before.EndTime/1000000, 0).Format("2006-01-02 15:04:05")))
-		body.WriteString(fmt.Sprintf("Cancelled: %s\n\n", timestamp.Format("2006-01-02 15:04:05")))
-
-		if before.VenueDetails != "" {
-			body.WriteString("Venue Information:\n")
-			var venueMap map[string]interface{}
-			if err := json.Unmarshal([]byte(before.VenueDetails), &venueMap); err == nil {
-				if name, ok := venueMap["name"].(string); ok {
-					body.WriteString(fmt.Sprintf("Venue: %s\n", name))
-				}
-				if address, ok := venueMap["address"].(string); ok {
-					body.WriteString(fmt.Sprintf("Address: %s\n", address))
-				}
-			}
-			body.WriteString("\n")
-		}
-
-		body.WriteString("🔔 This session has been permanently removed from the schedule.\n")
-		body.WriteString("📧 If you had tickets for this session, please check your email for refund information or contact support.\n\n")
-
-	} else {
-		if after == nil {
-			return "", ""
-		}
-
-		subject = fmt.Sprintf("Session Update: Session %s", after.ID)
-
-		body.WriteString("Dear Subscriber,\n\n")
-		body.WriteString("A session you're subscribed to has been updated:\n\n")
-
-		body.WriteString(fmt.Sprintf("Session ID: %s\n", after.ID))
-		body.WriteString(fmt.Sprintf("Event ID: %s\n", after.EventID))
-		body.WriteString(fmt.Sprintf("Status: %s\n", after.Status))
-		body.WriteString(fmt.Sprintf("Session Type: %s\n", after.SessionType))
-		body.WriteString(fmt.Sprintf("Start Time: %s\n", time.Unix(after.StartTime/1000000, 0).Format("2006-01-02 15:04:05")))
-		body.WriteString(fmt.Sprintf("End Time: %s\n", time.Unix(after.EndTime/1000000, 0).Format("2006-01-02 15:04:05")))
-		body.WriteString(fmt.Sprintf("Updated: %s\n\n", timestamp.Format("2006-01-02 15:04:05")))
-
-		if before != nil && operation == "u" {
-			body.WriteString("Changes:\n")
-
-			if before.Status != after.Status {
-				body.WriteString(fmt.Sprintf("• Status: %s → %s\n", before.Status, after.Status))
-			}
-
-			if before.StartTime != after.StartTime {
-				beforeTime := time.Unix(before.StartTime/1000000, 0).Format("2006-01-02 15:04:05")
-				afterTime := time.Unix(after.StartTime/1000000, 0).Format("2006-01-02 15:04:05")
-				body.WriteString(fmt.Sprintf("• Start Time: %s → %s\n", beforeTime, afterTime))
-			}
-
-			if before.EndTime != after.EndTime {
-				beforeTime := time.Unix(before.EndTime/1000000, 0).Format("2006-01-02 15:04:05")
-				afterTime := time.Unix(after.EndTime/1000000, 0).Format("2006-01-02 15:04:05")
-				body.WriteString(fmt.Sprintf("• End Time: %s → %s\n", beforeTime, afterTime))
-			}
-
-			if before.VenueDetails != after.VenueDetails {
-				body.WriteString("• Venue details updated\n")
-			}
-		} else if operation == "c" {
-			body.WriteString("New Session Details:\n")
-			if after.SalesStartTime > 0 {
-				body.WriteString(fmt.Sprintf("• Sales Start: %s\n", time.Unix(after.SalesStartTime/1000000, 0).Format("2006-01-02 15:04:05")))
-			}
-		}
-	}
-
-	body.WriteString("\nBest regards,\nTicketly Team")
-
-	return subject, body.String()
+	// Emails already sent are deduplicated, so a retry only reaches the subscribers that failed
+	return errors.Join(errs...)
 }
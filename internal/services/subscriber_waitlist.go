@@ -0,0 +1,45 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"ms-scheduling/internal/email/templates"
+	"ms-scheduling/internal/models"
+)
+
+// WaitlistOfferInfo describes a freed seat offered to a waitlisted subscriber
+type WaitlistOfferInfo struct {
+	SessionID string
+	EventID   string
+	ClaimURL  string
+	ExpiresAt time.Time
+}
+
+// SendWaitlistOfferEmail emails a waitlisted subscriber the link for claiming a freed seat.
+// source identifies the offer so it is not emailed twice.
+func (s *SubscriberService) SendWaitlistOfferEmail(subscriber models.Subscriber, offer *WaitlistOfferInfo, source string) error {
+	eventTitle := "your event"
+	if offer.EventID != "" {
+		if eventInfo, err := s.getEventBasicInfo(offer.EventID); err == nil && eventInfo.Title != "" {
+			eventTitle = eventInfo.Title
+		}
+	}
+
+	emailTemplate := s.EmailManager.Templates().GenerateWaitlistSeatAvailableEmail(&templates.WaitlistOfferData{
+		SessionID:      offer.SessionID,
+		EventTitle:     eventTitle,
+		ClaimURL:       offer.ClaimURL,
+		ExpiresAt:      offer.ExpiresAt,
+		UnsubscribeURL: s.unsubscribeURLPlaceholder(),
+	})
+
+	err := s.sendSubscriptionEmail(subscriber, models.SubscriptionCategoryWaitlist, offer.SessionID, source, emailTemplate)
+	if err != nil {
+		log.Printf("Error sending waitlist offer email to %s: %v", subscriber.SubscriberMail, err)
+		return err
+	}
+
+	log.Printf("Waitlist offer email sent successfully to: %s", subscriber.SubscriberMail)
+	return nil
+}
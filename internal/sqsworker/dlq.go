@@ -0,0 +1,129 @@
+package sqsworker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"ms-scheduling/internal/queue"
+)
+
+// Message attributes attached to every dead-lettered message
+const (
+	AttributeDLQError           = "dlq.error"
+	AttributeDLQAttempts        = "dlq.attempts"
+	AttributeDLQSourceQueue     = "dlq.source.queue"
+	AttributeDLQSourceMessageID = "dlq.source.message.id"
+	AttributeDLQTimestamp       = "dlq.timestamp"
+)
+
+// DLQEntry is a dead-lettered message as returned by the admin API
+type DLQEntry struct {
+	MessageID       string    `json:"messageId"`
+	Payload         string    `json:"payload"`
+	Error           string    `json:"error"`
+	Attempts        int       `json:"attempts"`
+	SourceQueue     string    `json:"sourceQueue"`
+	SourceMessageID string    `json:"sourceMessageId"`
+	FailedAt        time.Time `json:"failedAt"`
+}
+
+// DeadLetterQueue receives the messages of a source queue that kept failing and lets operators
+// inspect them and move them back
+type DeadLetterQueue struct {
+	queue  queue.Queue
+	source queue.Queue
+}
+
+// NewDeadLetterQueue creates a dead-letter queue on dlq for the messages of source
+func NewDeadLetterQueue(dlq, source queue.Queue) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		queue:  dlq,
+		source: source,
+	}
+}
+
+// Name returns the name of the dead-letter queue
+func (d *DeadLetterQueue) Name() string {
+	return d.queue.Name()
+}
+
+// Publish sends the message body unchanged to the dead-letter queue with attributes describing the failure
+func (d *DeadLetterQueue) Publish(ctx context.Context, message queue.Message, cause error, attempts int) error {
+	err := d.queue.Send(ctx, message.Body, map[string]string{
+		AttributeDLQError:           cause.Error(),
+		AttributeDLQAttempts:        strconv.Itoa(attempts),
+		AttributeDLQSourceQueue:     d.source.Name(),
+		AttributeDLQSourceMessageID: message.ID,
+		AttributeDLQTimestamp:       time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("error publishing message %s to dead-letter queue: %w", message.ID, err)
+	}
+
+	log.Printf("Dead-lettered message %s from %s after %d attempts: %v", message.ID, d.source.Name(), attempts, cause)
+	return nil
+}
+
+// List returns up to limit dead-lettered messages without removing them. SQS samples its servers,
+// so a call may return fewer messages than the queue holds.
+func (d *DeadLetterQueue) List(ctx context.Context, limit int) ([]DLQEntry, error) {
+	// Without a visibility timeout the messages stay visible for a later redrive
+	messages, err := d.queue.Receive(ctx, queue.ReceiveOptions{MaxMessages: limit})
+	if err != nil {
+		return nil, fmt.Errorf("error receiving from dead-letter queue: %w", err)
+	}
+
+	entries := make([]DLQEntry, 0, len(messages))
+	for _, message := range messages {
+		entries = append(entries, toDLQEntry(message))
+	}
+	return entries, nil
+}
+
+// Redrive moves up to limit dead-lettered messages back to the source queue, where they are
+// received with a fresh receive count. It returns the messages that were moved.
+func (d *DeadLetterQueue) Redrive(ctx context.Context, limit int) ([]DLQEntry, error) {
+	var moved []DLQEntry
+	for len(moved) < limit {
+		messages, err := d.queue.Receive(ctx, queue.ReceiveOptions{
+			MaxMessages:       limit - len(moved),
+			VisibilityTimeout: 30 * time.Second,
+		})
+		if err != nil {
+			return moved, fmt.Errorf("error receiving from dead-letter queue: %w", err)
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		for _, message := range messages {
+			if err := d.source.Send(ctx, message.Body, nil); err != nil {
+				return moved, fmt.Errorf("error sending message %s back to %s: %w", message.ID, d.source.Name(), err)
+			}
+
+			if err := d.queue.Ack(ctx, message); err != nil {
+				return moved, fmt.Errorf("error deleting redriven message %s: %w", message.ID, err)
+			}
+
+			log.Printf("Redrove message %s from %s to %s", message.ID, d.queue.Name(), d.source.Name())
+			moved = append(moved, toDLQEntry(message))
+		}
+	}
+	return moved, nil
+}
+
+func toDLQEntry(message queue.Message) DLQEntry {
+	entry := DLQEntry{
+		MessageID:       message.ID,
+		Payload:         message.Body,
+		Error:           message.Attributes[AttributeDLQError],
+		SourceQueue:     message.Attributes[AttributeDLQSourceQueue],
+		SourceMessageID: message.Attributes[AttributeDLQSourceMessageID],
+	}
+	entry.Attempts, _ = strconv.Atoi(message.Attributes[AttributeDLQAttempts])
+	entry.FailedAt, _ = time.Parse(time.RFC3339, message.Attributes[AttributeDLQTimestamp])
+	return entry
+}
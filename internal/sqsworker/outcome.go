@@ -0,0 +1,59 @@
+package sqsworker
+
+import (
+	"errors"
+	"time"
+)
+
+// A handler acknowledges a message by returning nil, after which it is deleted from the queue.
+// Any other error leaves the message on the queue so SQS redelivers it once its visibility timeout
+// expires; Poison and RetryAfter change what happens to it.
+
+// poisonError marks a message that can never be processed, such as a malformed body
+type poisonError struct {
+	err error
+}
+
+func (e *poisonError) Error() string { return e.err.Error() }
+func (e *poisonError) Unwrap() error { return e.err }
+
+// Poison marks err as permanent: the message is deleted instead of being redelivered
+func Poison(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &poisonError{err: err}
+}
+
+// IsPoison reports whether err was marked with Poison
+func IsPoison(err error) bool {
+	var poison *poisonError
+	return errors.As(err, &poison)
+}
+
+// retryError asks for the message to be redelivered after a given delay
+type retryError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+func (e *retryError) Unwrap() error { return e.err }
+
+// RetryAfter leaves the message on the queue and makes it visible again after delay,
+// instead of after the queue's visibility timeout
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryError{err: err, delay: delay}
+}
+
+// retryDelay returns the delay requested with RetryAfter, if any
+func retryDelay(err error) (time.Duration, bool) {
+	var retry *retryError
+	if errors.As(err, &retry) {
+		return retry.delay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,275 @@
+package sqsworker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"ms-scheduling/internal/queue"
+)
+
+// maxVisibilityTimeout is the longest SQS keeps a message hidden
+const maxVisibilityTimeout = 12 * time.Hour
+
+// Handler processes one message whose JSON body was decoded into T
+type Handler[T any] func(ctx context.Context, message T) error
+
+// Options configures the worker of one queue
+type Options struct {
+	Name string // used in logs, e.g. "reminder"
+	// Workers is how many messages of a received batch are handled concurrently
+	Workers int
+	// MaxMessages is how many messages are received at once, at most 10
+	MaxMessages int
+	// WaitTime is how long a receive long-polls for messages
+	WaitTime time.Duration
+	// VisibilityTimeout hides received messages from other consumers and is renewed while they are
+	// handled, default 30s
+	VisibilityTimeout time.Duration
+	// HeartbeatInterval is how often the visibility timeout is renewed, default half of it
+	HeartbeatInterval time.Duration
+	// ReceiveErrorBackoff is the pause after a failed receive
+	ReceiveErrorBackoff time.Duration
+	// RetryBackoff delays the redelivery of a failed message by RetryBackoff * 2^(receives-1),
+	// capped at MaxRetryBackoff. Zero leaves it to the visibility timeout.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// MaxReceives is how often a message is received before it is dead-lettered, 0 retries forever
+	MaxReceives int
+	// DeadLetterQueue receives messages that are poison or failed MaxReceives times.
+	// Without one poison messages are deleted and failing messages are kept.
+	DeadLetterQueue *DeadLetterQueue
+}
+
+// Worker receives messages from a queue and hands them to a typed handler
+type Worker[T any] struct {
+	queue   queue.Queue
+	opts    Options
+	handler Handler[T]
+}
+
+// New creates a worker for q, filling in defaults for unset options
+func New[T any](q queue.Queue, opts Options, handler Handler[T]) *Worker[T] {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxMessages < 1 || opts.MaxMessages > 10 {
+		opts.MaxMessages = 10
+	}
+	if opts.WaitTime <= 0 {
+		opts.WaitTime = 20 * time.Second
+	}
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = 30 * time.Second
+	}
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = opts.VisibilityTimeout / 2
+	}
+	if opts.ReceiveErrorBackoff <= 0 {
+		opts.ReceiveErrorBackoff = 5 * time.Second
+	}
+	if opts.MaxRetryBackoff <= 0 || opts.MaxRetryBackoff > maxVisibilityTimeout {
+		opts.MaxRetryBackoff = maxVisibilityTimeout
+	}
+
+	return &Worker[T]{
+		queue:   q,
+		opts:    opts,
+		handler: handler,
+	}
+}
+
+// Run receives and handles messages until the context is cancelled, then returns once the current
+// batch is handled. Messages are deleted once their handler acknowledged them; the rest are
+// redelivered by the queue.
+func (w *Worker[T]) Run(ctx context.Context) error {
+	if w.queue == nil {
+		return fmt.Errorf("%s queue not configured", w.opts.Name)
+	}
+
+	log.Printf("Starting %s worker for queue %s with %d workers", w.opts.Name, w.queue.Name(), w.opts.Workers)
+
+	for {
+		if ctx.Err() != nil {
+			log.Printf("Context cancelled, stopping %s worker", w.opts.Name)
+			return ctx.Err()
+		}
+
+		messages, err := w.queue.Receive(ctx, queue.ReceiveOptions{
+			MaxMessages:       w.opts.MaxMessages,
+			WaitTime:          w.opts.WaitTime,
+			VisibilityTimeout: w.opts.VisibilityTimeout,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			log.Printf("Error receiving messages from %s queue: %v", w.opts.Name, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.opts.ReceiveErrorBackoff):
+			}
+			continue
+		}
+
+		if len(messages) == 0 {
+			continue // No need to sleep, long polling already waited
+		}
+
+		log.Printf("Received %d messages from %s queue", len(messages), w.opts.Name)
+		// A received batch is finished on shutdown rather than abandoned halfway; main bounds the
+		// wait with SHUTDOWN_TIMEOUT
+		w.processBatch(context.WithoutCancel(ctx), messages)
+	}
+}
+
+// processBatch handles the messages of a batch on up to Workers goroutines and deletes the
+// acknowledged ones in a single call once all of them are done. Acknowledged messages are kept
+// invisible until they are deleted, so a slow message does not get its finished neighbours redelivered.
+func (w *Worker[T]) processBatch(ctx context.Context, messages []queue.Message) {
+	acked := make([]bool, len(messages))
+	stopHeartbeats := make([]func(), len(messages))
+	slots := make(chan struct{}, w.opts.Workers)
+
+	var wg sync.WaitGroup
+	for i := range messages {
+		// Messages waiting for a free worker are kept invisible as well
+		stopHeartbeats[i] = w.startHeartbeat(ctx, messages[i])
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			err := w.handle(ctx, messages[i])
+			<-slots
+
+			acked[i] = w.settle(ctx, messages[i], err, stopHeartbeats[i])
+		}(i)
+	}
+	wg.Wait()
+	defer func() {
+		for _, stop := range stopHeartbeats {
+			stop()
+		}
+	}()
+
+	var handled []queue.Message
+	for i, message := range messages {
+		if acked[i] {
+			handled = append(handled, message)
+		}
+	}
+	if len(handled) == 0 {
+		return
+	}
+
+	ackCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := w.queue.Ack(ackCtx, handled...); err != nil {
+		log.Printf("Error deleting %s messages: %v", w.opts.Name, err)
+	}
+}
+
+// handle decodes the message body and runs the handler on it. A body that cannot be decoded is poison.
+func (w *Worker[T]) handle(ctx context.Context, message queue.Message) error {
+	var body T
+	if err := json.Unmarshal([]byte(message.Body), &body); err != nil {
+		return Poison(fmt.Errorf("error unmarshalling message body: %w", err))
+	}
+	return w.handler(ctx, body)
+}
+
+// settle reports whether the message should be deleted. Failed messages are dead-lettered once they
+// are poison or were received MaxReceives times, and otherwise made visible again after a backoff;
+// stopHeartbeat is called before a message is handed back to the queue.
+func (w *Worker[T]) settle(ctx context.Context, message queue.Message, err error, stopHeartbeat func()) bool {
+	if err == nil {
+		return true
+	}
+
+	attempts := message.ReceiveCount
+	if attempts < 1 {
+		attempts = 1
+	}
+	if IsPoison(err) || (w.opts.MaxReceives > 0 && attempts >= w.opts.MaxReceives) {
+		if w.opts.DeadLetterQueue != nil {
+			if dlqErr := w.opts.DeadLetterQueue.Publish(ctx, message, err, attempts); dlqErr != nil {
+				// Keep the message so the next delivery tries again
+				log.Printf("Error dead-lettering %s message %s: %v", w.opts.Name, message.ID, dlqErr)
+				return false
+			}
+			return true
+		}
+		if IsPoison(err) {
+			log.Printf("Deleting %s message %s that cannot be processed: %v", w.opts.Name, message.ID, err)
+			return true
+		}
+	}
+
+	stopHeartbeat()
+	delay, ok := retryDelay(err)
+	if !ok && w.opts.RetryBackoff > 0 {
+		delay, ok = w.backoff(attempts), true
+	}
+	if !ok {
+		log.Printf("Error processing %s message %s (attempt %d), it will be retried: %v", w.opts.Name, message.ID, attempts, err)
+		return false
+	}
+
+	log.Printf("Error processing %s message %s (attempt %d), retrying in %s: %v", w.opts.Name, message.ID, attempts, delay, err)
+	if err := w.queue.ChangeVisibility(ctx, message, delay); err != nil {
+		log.Printf("Error delaying retry of %s message %s: %v", w.opts.Name, message.ID, err)
+	}
+	return false
+}
+
+// backoff returns the redelivery delay after the given attempt: RetryBackoff * 2^(attempt-1), capped at MaxRetryBackoff
+func (w *Worker[T]) backoff(attempt int) time.Duration {
+	delay := w.opts.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= w.opts.MaxRetryBackoff {
+			return w.opts.MaxRetryBackoff
+		}
+	}
+	return delay
+}
+
+// startHeartbeat renews the visibility timeout of the message until the returned function is first called
+func (w *Worker[T]) startHeartbeat(ctx context.Context, message queue.Message) func() {
+	if w.opts.HeartbeatInterval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.opts.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.queue.ChangeVisibility(ctx, message, w.opts.VisibilityTimeout); err != nil {
+					log.Printf("Error extending visibility of %s message %s: %v", w.opts.Name, message.ID, err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
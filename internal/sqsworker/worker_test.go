@@ -0,0 +1,225 @@
+package sqsworker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ms-scheduling/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingQueue is an in-memory queue that records visibility changes by message ID
+type recordingQueue struct {
+	*queue.MemoryQueue
+	mu         sync.Mutex
+	visibility map[string][]time.Duration
+}
+
+func newRecordingQueue(bodies ...string) *recordingQueue {
+	q := &recordingQueue{MemoryQueue: queue.NewMemoryQueue("test"), visibility: make(map[string][]time.Duration)}
+	for _, body := range bodies {
+		q.Send(context.Background(), body, nil)
+	}
+	return q
+}
+
+func (q *recordingQueue) ChangeVisibility(ctx context.Context, message queue.Message, timeout time.Duration) error {
+	q.mu.Lock()
+	q.visibility[message.ID] = append(q.visibility[message.ID], timeout)
+	q.mu.Unlock()
+	return q.MemoryQueue.ChangeVisibility(ctx, message, timeout)
+}
+
+func (q *recordingQueue) visibilityOf(id string) []time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]time.Duration(nil), q.visibility[id]...)
+}
+
+type testMessage struct {
+	Outcome string `json:"outcome"`
+}
+
+// runUntil runs the worker until done reports true, then stops it
+func runUntil[T any](t *testing.T, worker *Worker[T], done func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() { result <- worker.Run(ctx) }()
+
+	assert.Eventually(t, done, 2*time.Second, 5*time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+}
+
+func TestRunDeletesAcknowledgedAndPoisonMessagesOnly(t *testing.T) {
+	q := newRecordingQueue(`{"outcome":"ack"}`, `{"outcome":"poison"}`, `{"outcome":"retry"}`, `{"outcome":"later"}`, `not json`)
+
+	var mu sync.Mutex
+	handled := 0
+	worker := New(q, Options{Name: "test", Workers: 3, WaitTime: 10 * time.Millisecond}, func(ctx context.Context, message testMessage) error {
+		mu.Lock()
+		handled++
+		mu.Unlock()
+
+		switch message.Outcome {
+		case "poison":
+			return Poison(errors.New("bad message"))
+		case "retry":
+			return errors.New("temporary failure")
+		case "later":
+			return RetryAfter(errors.New("not ready"), 30*time.Second)
+		}
+		return nil
+	})
+
+	runUntil(t, worker, func() bool { return q.Len() == 2 })
+	assert.Equal(t, 4, handled)
+	assert.Equal(t, []time.Duration{30 * time.Second}, q.visibilityOf("4"))
+	assert.Empty(t, q.visibilityOf("3"))
+}
+
+func TestRunExtendsVisibilityWhileHandlerRuns(t *testing.T) {
+	q := newRecordingQueue(`{}`)
+
+	worker := New(q, Options{
+		Name:              "test",
+		WaitTime:          10 * time.Millisecond,
+		VisibilityTimeout: time.Minute,
+		HeartbeatInterval: 10 * time.Millisecond,
+	}, func(ctx context.Context, message testMessage) error {
+		time.Sleep(55 * time.Millisecond)
+		return nil
+	})
+
+	runUntil(t, worker, func() bool { return q.Len() == 0 })
+	visibility := q.visibilityOf("1")
+	assert.GreaterOrEqual(t, len(visibility), 3)
+	assert.Equal(t, time.Minute, visibility[0])
+}
+
+func TestRunKeepsFinishedMessagesInvisibleUntilTheBatchIsDeleted(t *testing.T) {
+	q := newRecordingQueue(`{"outcome":"fast"}`, `{"outcome":"slow"}`)
+
+	worker := New(q, Options{
+		Name:              "test",
+		Workers:           2,
+		WaitTime:          10 * time.Millisecond,
+		VisibilityTimeout: time.Minute,
+		HeartbeatInterval: 10 * time.Millisecond,
+	}, func(ctx context.Context, message testMessage) error {
+		if message.Outcome == "slow" {
+			time.Sleep(55 * time.Millisecond)
+		}
+		return nil
+	})
+
+	runUntil(t, worker, func() bool { return q.Len() == 0 })
+	assert.GreaterOrEqual(t, len(q.visibilityOf("1")), 3)
+}
+
+func TestRunLimitsConcurrentHandlersToWorkers(t *testing.T) {
+	q := newRecordingQueue(`{}`, `{}`, `{}`, `{}`, `{}`)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	worker := New(q, Options{Name: "test", Workers: 2, WaitTime: 10 * time.Millisecond}, func(ctx context.Context, message testMessage) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	runUntil(t, worker, func() bool { return q.Len() == 0 })
+	assert.Equal(t, 2, peak)
+}
+
+func TestRunBacksOffByReceiveCountAndDeadLettersAfterMaxReceives(t *testing.T) {
+	q := newRecordingQueue(`{"sessionId":"s1"}`)
+	dlq := queue.NewMemoryQueue("test-dlq")
+
+	worker := New(q, Options{
+		Name:            "test",
+		WaitTime:        10 * time.Millisecond,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Hour,
+		MaxReceives:     3,
+		DeadLetterQueue: NewDeadLetterQueue(dlq, q),
+	}, func(ctx context.Context, message testMessage) error {
+		return errors.New("event service returned 500")
+	})
+
+	runUntil(t, worker, func() bool { return dlq.Len() == 1 })
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond}, q.visibilityOf("1"))
+
+	entries, err := NewDeadLetterQueue(dlq, q).List(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, `{"sessionId":"s1"}`, entries[0].Payload)
+	assert.Equal(t, "event service returned 500", entries[0].Error)
+	assert.Equal(t, 3, entries[0].Attempts)
+	assert.Equal(t, "test", entries[0].SourceQueue)
+	assert.Equal(t, "1", entries[0].SourceMessageID)
+}
+
+func TestRedriveSendsMessagesBackToTheSourceQueue(t *testing.T) {
+	source := queue.NewMemoryQueue("test")
+	dlq := queue.NewMemoryQueue("test-dlq")
+	require.NoError(t, dlq.Send(context.Background(), `{"sessionId":"s1"}`, map[string]string{AttributeDLQAttempts: "8"}))
+
+	moved, err := NewDeadLetterQueue(dlq, source).Redrive(context.Background(), 5)
+
+	require.NoError(t, err)
+	require.Len(t, moved, 1)
+	assert.Equal(t, 8, moved[0].Attempts)
+	assert.Equal(t, 0, dlq.Len())
+
+	messages, err := source.Receive(context.Background(), queue.ReceiveOptions{VisibilityTimeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, `{"sessionId":"s1"}`, messages[0].Body)
+	assert.Equal(t, 1, messages[0].ReceiveCount)
+}
+
+func TestRunFinishesTheCurrentBatchWhenCancelled(t *testing.T) {
+	q := newRecordingQueue(`{}`, `{}`)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{}, 2)
+	worker := New(q, Options{Name: "test", Workers: 2, WaitTime: 10 * time.Millisecond}, func(handlerCtx context.Context, message testMessage) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return handlerCtx.Err()
+	})
+
+	result := make(chan error, 1)
+	go func() { result <- worker.Run(ctx) }()
+	<-started
+	<-started
+	cancel()
+
+	assert.ErrorIs(t, <-result, context.Canceled)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestRunRequiresQueue(t *testing.T) {
+	worker := New(nil, Options{Name: "test"}, func(ctx context.Context, message testMessage) error { return nil })
+
+	assert.EqualError(t, worker.Run(context.Background()), "test queue not configured")
+}
@@ -0,0 +1,182 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunFunc is a long-running worker such as a consumer or processor. It returns once the context
+// is cancelled; returning earlier with an error or panicking counts as a crash.
+type RunFunc func(ctx context.Context) error
+
+// Supervisor runs the background workers of the service, restarts crashed workers with an
+// exponential backoff and waits for all of them to stop on shutdown
+type Supervisor struct {
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	workers map[string]*workerState
+}
+
+type workerState struct {
+	running   bool
+	startedAt time.Time
+	restarts  int
+	lastError error
+}
+
+// New creates a supervisor; a crashed worker is restarted after initialBackoff, doubled on every
+// crash up to maxBackoff. A worker that ran for maxBackoff without crashing counts as recovered.
+func New(initialBackoff, maxBackoff time.Duration) *Supervisor {
+	if initialBackoff <= 0 {
+		initialBackoff = time.Second
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+
+	return &Supervisor{
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		workers:        make(map[string]*workerState),
+	}
+}
+
+// Go starts run under the given name until ctx is cancelled
+func (s *Supervisor) Go(ctx context.Context, name string, run RunFunc) {
+	s.mu.Lock()
+	s.workers[name] = &workerState{}
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.supervise(ctx, name, run)
+}
+
+func (s *Supervisor) supervise(ctx context.Context, name string, run RunFunc) {
+	defer s.wg.Done()
+
+	backoff := s.initialBackoff
+	for {
+		startedAt := time.Now()
+		s.update(name, func(state *workerState) {
+			state.running = true
+			state.startedAt = startedAt
+		})
+
+		err := runSafely(ctx, run)
+		if ctx.Err() != nil {
+			s.update(name, func(state *workerState) { state.running = false })
+			log.Printf("[Supervisor] %s stopped", name)
+			return
+		}
+		if err == nil {
+			// Nothing to do, e.g. no topics configured
+			s.update(name, func(state *workerState) { state.running = false })
+			log.Printf("[Supervisor] %s finished", name)
+			return
+		}
+
+		if time.Since(startedAt) >= s.maxBackoff {
+			backoff = s.initialBackoff
+		}
+		s.update(name, func(state *workerState) {
+			state.running = false
+			state.restarts++
+			state.lastError = err
+		})
+		log.Printf("[Supervisor] %s crashed, restarting in %s: %v", name, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("[Supervisor] %s stopped", name)
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// runSafely runs the worker, turning a panic into an error
+func runSafely(ctx context.Context, run RunFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return run(ctx)
+}
+
+func (s *Supervisor) update(name string, apply func(state *workerState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	apply(s.workers[name])
+}
+
+// Check returns an error naming the workers that crashed and have not run for maxBackoff since.
+// It is registered as a liveness check.
+func (s *Supervisor) Check() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var unhealthy []string
+	for name, state := range s.workers {
+		if state.lastError == nil {
+			continue
+		}
+		if state.running && time.Since(state.startedAt) >= s.maxBackoff {
+			continue
+		}
+		unhealthy = append(unhealthy, fmt.Sprintf("%s (%d restarts): %v", name, state.restarts, firstLine(state.lastError)))
+	}
+	if len(unhealthy) == 0 {
+		return nil
+	}
+
+	sort.Strings(unhealthy)
+	return fmt.Errorf("workers crashed: %s", strings.Join(unhealthy, "; "))
+}
+
+// Wait blocks until every worker has returned or ctx is done, in which case it returns an error
+// naming the workers still running
+func (s *Supervisor) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var running []string
+	for name, state := range s.workers {
+		if state.running {
+			running = append(running, name)
+		}
+	}
+	sort.Strings(running)
+	return fmt.Errorf("workers still running: %s", strings.Join(running, ", "))
+}
+
+// firstLine drops the stack trace of a recovered panic
+func firstLine(err error) string {
+	message, _, _ := strings.Cut(err.Error(), "\n")
+	return message
+}
@@ -0,0 +1,88 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoRestartsCrashedWorkersAndReportsThem(t *testing.T) {
+	s := New(time.Millisecond, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	s.Go(ctx, "consumer", func(ctx context.Context) error {
+		switch runs.Add(1) {
+		case 1:
+			return errors.New("connection reset")
+		case 2:
+			panic("nil map")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.Eventually(t, func() bool { return runs.Load() == 3 }, time.Second, time.Millisecond)
+	err := s.Check()
+	require.Error(t, err)
+	assert.Equal(t, "workers crashed: consumer (2 restarts): panic: nil map", err.Error())
+
+	cancel()
+	assert.NoError(t, s.Wait(context.Background()))
+}
+
+func TestCheckRecoversAfterWorkerRanForMaxBackoff(t *testing.T) {
+	s := New(time.Millisecond, 20*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	s.Go(ctx, "processor", func(ctx context.Context) error {
+		if runs.Add(1) == 1 {
+			return errors.New("boom")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond)
+	assert.Error(t, s.Check())
+	assert.Eventually(t, func() bool { return s.Check() == nil }, time.Second, 5*time.Millisecond)
+}
+
+func TestWorkerFinishingWithoutErrorIsNotRestarted(t *testing.T) {
+	s := New(time.Millisecond, time.Hour)
+
+	var runs atomic.Int32
+	s.Go(context.Background(), "order consumer", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	require.NoError(t, s.Wait(context.Background()))
+	assert.Equal(t, int32(1), runs.Load())
+	assert.NoError(t, s.Check())
+}
+
+func TestWaitReportsWorkersStillRunningAtTheDeadline(t *testing.T) {
+	s := New(time.Millisecond, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+	s.Go(ctx, "outbox dispatcher", func(ctx context.Context) error {
+		<-release // ignores the cancellation, e.g. stuck on SMTP
+		return ctx.Err()
+	})
+
+	deadline, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	assert.EqualError(t, s.Wait(deadline), "workers still running: outbox dispatcher")
+}
@@ -3,26 +3,21 @@ package trending
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"ms-scheduling/internal/auth"
 	"ms-scheduling/internal/config"
-	"ms-scheduling/internal/sqsutil"
+	"ms-scheduling/internal/queue"
+	"ms-scheduling/internal/sqsworker"
 	"net/http"
-	"time"
-
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 // TrendingProcessor handles processing of trending calculation jobs from SQS
 type Processor struct {
-	sqsClient         *sqs.Client
+	queue             queue.Queue
 	httpClient        *http.Client
 	cfg               config.Config
-	queueURL          string
 	eventQueryBaseURL string
 }
 
@@ -34,106 +29,41 @@ type Message struct {
 }
 
 // NewProcessor creates a new trending job processor
-func NewProcessor(sqsClient *sqs.Client, httpClient *http.Client, cfg config.Config) *Processor {
+func NewProcessor(q queue.Queue, httpClient *http.Client, cfg config.Config) *Processor {
 	return &Processor{
-		sqsClient:         sqsClient,
+		queue:             q,
 		httpClient:        httpClient,
 		cfg:               cfg,
-		queueURL:          cfg.SQSTrendingQueueURL,
 		eventQueryBaseURL: cfg.EventQueryServiceURL,
 	}
 }
 
-// ProcessMessages processes messages from the trending queue
+// ProcessMessages processes messages from the trending queue until the context is cancelled
 func (p *Processor) ProcessMessages(ctx context.Context) error {
-	if p.queueURL == "" {
-		log.Println("Trending queue URL not configured, skipping trending job processing")
-		return fmt.Errorf("trending queue URL not configured")
-	}
-
-	log.Printf("Starting to process trending job messages from %s", p.queueURL)
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, stopping trending job processing")
-			return ctx.Err()
-		default:
-			// Continue processing
-		}
-
-		// Receive messages from trending queue
-		rawMessages, err := sqsutil.ReceiveMessage(p.sqsClient, p.queueURL)
-		if err != nil {
-			log.Printf("Error receiving messages from trending SQS queue: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
-		}
+	worker := sqsworker.New(p.queue, sqsworker.Options{
+		Name:              "trending",
+		Workers:           p.cfg.SQSTrendingWorkers,
+		VisibilityTimeout: p.cfg.SQSVisibilityTimeout,
+	}, p.processTrendingMessage)
 
-		if len(rawMessages) == 0 {
-			log.Println("No messages received from trending queue, continuing loop.")
-			continue // No need to sleep, long polling already waited
-		}
-
-		log.Printf("Received %d messages from trending queue.", len(rawMessages))
-		var messagesToDelete []types.DeleteMessageBatchRequestEntry
-		var token string
-		var tokenErr error
-
-		// Process each message in the batch
-		for _, rawMessage := range rawMessages {
-			log.Printf("Processing trending job message: %s", *rawMessage.Body)
-
-			// Get token only once for the batch, if needed
-			if token == "" {
-				token, tokenErr = auth.GetM2MToken(p.cfg, p.httpClient)
-				if tokenErr != nil {
-					log.Printf("Error getting M2M token for trending job: %v. Will retry later.", tokenErr)
-					break // Skip processing the rest of the messages if we can't get a token
-				}
-			}
-
-			// Process the message
-			err = p.processTrendingMessage(token, *rawMessage.Body)
-			if err != nil {
-				log.Printf("Error processing trending job message: %v, it will be retried", err)
-				// If processing fails, DO NOT add it to the delete batch.
-				// It will become visible again on the queue for another attempt.
-			} else {
-				log.Printf("Successfully processed trending job message, adding to delete batch.")
-				// On success, add the message to our list of messages to delete.
-				messagesToDelete = append(messagesToDelete, types.DeleteMessageBatchRequestEntry{
-					Id:            rawMessage.MessageId,
-					ReceiptHandle: rawMessage.ReceiptHandle,
-				})
-			}
-		}
-
-		// After processing the whole batch, delete the successful ones in a single API call
-		if len(messagesToDelete) > 0 {
-			err := sqsutil.DeleteMessageBatch(p.queueURL, p.sqsClient, messagesToDelete)
-			if err != nil {
-				log.Printf("Error batch deleting trending job messages: %v", err)
-			}
-		}
-	}
+	return worker.Run(ctx)
 }
 
 // processTrendingMessage processes a single trending job message
-func (p *Processor) processTrendingMessage(token, messageBody string) error {
-	// Parse the message body if needed - adjust based on your actual message structure
-	var message Message
-	err := json.Unmarshal([]byte(messageBody), &message)
+func (p *Processor) processTrendingMessage(ctx context.Context, message Message) error {
+	log.Printf("Processing trending job message: %+v", message)
+
+	token, err := auth.GetM2MToken(p.cfg, p.httpClient)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling trending message: %w", err)
+		return fmt.Errorf("error getting M2M token for trending job: %w", err)
 	}
 
 	// Call the event query service to calculate trends
-	return p.calculateTrends(token)
+	return p.calculateTrends(ctx, token)
 }
 
 // calculateTrends calls the event query service to calculate trending events
-func (p *Processor) calculateTrends(token string) error {
+func (p *Processor) calculateTrends(ctx context.Context, token string) error {
 	endpoint := fmt.Sprintf("%s/internal/v1/trending/calculate-all", p.eventQueryBaseURL)
 	log.Printf("Sending request to calculate trends: %s", endpoint)
 
@@ -141,7 +71,7 @@ func (p *Processor) calculateTrends(token string) error {
 	reqBody := []byte("{}")
 
 	// Create the request
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
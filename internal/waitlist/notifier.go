@@ -0,0 +1,135 @@
+package waitlist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/services"
+)
+
+// OfferSender emails a claim link to a waitlisted subscriber
+type OfferSender interface {
+	SendWaitlistOfferEmail(subscriber models.Subscriber, offer *services.WaitlistOfferInfo, source string) error
+}
+
+// offerStore is the part of the Store the notifier works with
+type offerStore interface {
+	ExpireOffers(ctx context.Context) (int, error)
+	PendingSessions(ctx context.Context) ([]PendingSession, error)
+	CreateOffers(ctx context.Context, sessionID string, limit int, ttl time.Duration) ([]Offer, error)
+}
+
+// Notifier offers freed seats to waitlisted subscribers in FIFO order. Every interval it sends at
+// most batchSize offers per session, so a batch of freed seats is not announced to the whole
+// waitlist at once. Offers that expire unclaimed free their seat for the next subscriber.
+type Notifier struct {
+	store     offerStore
+	sender    OfferSender
+	signer    *auth.WaitlistClaimSigner
+	batchSize int
+	interval  time.Duration
+	claimTTL  time.Duration
+}
+
+// NewNotifier creates a notifier that emails claim links signed by signer, valid for claimTTL
+func NewNotifier(store *Store, sender OfferSender, signer *auth.WaitlistClaimSigner, batchSize int, interval, claimTTL time.Duration) *Notifier {
+	if batchSize <= 0 {
+		batchSize = 5
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if claimTTL <= 0 {
+		claimTTL = 30 * time.Minute
+	}
+	return &Notifier{
+		store:     store,
+		sender:    sender,
+		signer:    signer,
+		batchSize: batchSize,
+		interval:  interval,
+		claimTTL:  claimTTL,
+	}
+}
+
+// Run sends offers every interval until the context is cancelled
+func (n *Notifier) Run(ctx context.Context) error {
+	log.Printf("[Waitlist] Starting notifier (%d offers per session every %s, claim window %s)", n.batchSize, n.interval, n.claimTTL)
+
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := n.NotifyOnce(ctx); err != nil {
+			log.Printf("[Waitlist] Error sending waitlist offers: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("[Waitlist] Context cancelled, stopping notifier")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// NotifyOnce expires overdue offers, then sends one batch of offers per session with freed seats.
+// It returns how many offers were sent.
+func (n *Notifier) NotifyOnce(ctx context.Context) (int, error) {
+	expired, err := n.store.ExpireOffers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if expired > 0 {
+		log.Printf("[Waitlist] %d offers expired unclaimed, their seats go to the next subscribers", expired)
+	}
+
+	sessions, err := n.store.PendingSessions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, session := range sessions {
+		offers, err := n.store.CreateOffers(ctx, session.SessionID, n.batchSize, n.claimTTL)
+		if err != nil {
+			log.Printf("[Waitlist] Error creating offers for session %s: %v", session.SessionID, err)
+			continue
+		}
+		for _, offer := range offers {
+			if err := n.send(offer); err != nil {
+				// The offer stays open and expires like an ignored one, passing the seat on
+				log.Printf("[Waitlist] Error sending offer %d to %s: %v", offer.ID, offer.Subscriber.SubscriberMail, err)
+				continue
+			}
+			sent++
+		}
+		if len(offers) > 0 {
+			log.Printf("[Waitlist] Offered %d of %d freed seats of session %s", len(offers), session.Seats, session.SessionID)
+		}
+	}
+	return sent, nil
+}
+
+// send emails the claim link of one offer
+func (n *Notifier) send(offer Offer) error {
+	claimURL, err := n.signer.URL(offer.ID, offer.Subscriber.SubscriberID, offer.SessionID, offer.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	info := &services.WaitlistOfferInfo{
+		SessionID: offer.SessionID,
+		EventID:   offer.EventID,
+		ClaimURL:  claimURL,
+		ExpiresAt: offer.ExpiresAt,
+	}
+	if err := n.sender.SendWaitlistOfferEmail(offer.Subscriber, info, fmt.Sprintf("waitlist-offer:%d", offer.ID)); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package waitlist
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ms-scheduling/internal/auth"
+	"ms-scheduling/internal/models"
+	"ms-scheduling/internal/services"
+)
+
+// fakeStore hands out offers to a FIFO queue of subscribers
+type fakeStore struct {
+	pending map[string]int
+	queue   []models.Subscriber
+	nextID  int
+}
+
+func (f *fakeStore) ExpireOffers(ctx context.Context) (int, error) { return 0, nil }
+
+func (f *fakeStore) PendingSessions(ctx context.Context) ([]PendingSession, error) {
+	var sessions []PendingSession
+	for id, seats := range f.pending {
+		if seats > 0 {
+			sessions = append(sessions, PendingSession{SessionID: id, Seats: seats})
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeStore) CreateOffers(ctx context.Context, sessionID string, limit int, ttl time.Duration) ([]Offer, error) {
+	var offers []Offer
+	for len(offers) < limit && f.pending[sessionID] > 0 && len(f.queue) > 0 {
+		f.nextID++
+		offers = append(offers, Offer{ID: f.nextID, SessionID: sessionID, EventID: "e1", Subscriber: f.queue[0], ExpiresAt: time.Now().Add(ttl)})
+		f.queue = f.queue[1:]
+		f.pending[sessionID]--
+	}
+	return offers, nil
+}
+
+type sentOffer struct {
+	subscriber models.Subscriber
+	offer      *services.WaitlistOfferInfo
+	source     string
+}
+
+type recordingOfferSender struct {
+	sent []sentOffer
+}
+
+func (r *recordingOfferSender) SendWaitlistOfferEmail(subscriber models.Subscriber, offer *services.WaitlistOfferInfo, source string) error {
+	r.sent = append(r.sent, sentOffer{subscriber, offer, source})
+	return nil
+}
+
+func TestNotifyOnceOffersOneBatchInQueueOrder(t *testing.T) {
+	store := &fakeStore{pending: map[string]int{"s1": 4}}
+	for i := 1; i <= 6; i++ {
+		store.queue = append(store.queue, models.Subscriber{SubscriberID: i})
+	}
+	sender := &recordingOfferSender{}
+	signer := auth.NewWaitlistClaimSigner("secret", "https://api.ticketly.com/api/scheduler/waitlist/v1/claim")
+	notifier := &Notifier{store: store, sender: sender, signer: signer, batchSize: 3, interval: time.Minute, claimTTL: 30 * time.Minute}
+
+	sent, err := notifier.NotifyOnce(context.Background())
+	require.NoError(t, err)
+
+	// Four seats are free, but only one batch goes out per interval
+	assert.Equal(t, 3, sent)
+	require.Len(t, sender.sent, 3)
+	for i, s := range sender.sent {
+		assert.Equal(t, i+1, s.subscriber.SubscriberID)
+	}
+	assert.Equal(t, "waitlist-offer:1", sender.sent[0].source)
+
+	link, err := url.Parse(sender.sent[0].offer.ClaimURL)
+	require.NoError(t, err)
+	claims, err := signer.Verify(link.Query().Get("token"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, claims.OfferID)
+	assert.Equal(t, "s1", claims.SessionID)
+
+	// The remaining seat goes to the next subscriber on the next run
+	sent, err = notifier.NotifyOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, 4, sender.sent[3].subscriber.SubscriberID)
+}
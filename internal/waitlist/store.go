@@ -0,0 +1,447 @@
+package waitlist
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ms-scheduling/internal/models"
+)
+
+var (
+	// ErrOfferNotFound is returned when claiming an offer that does not exist
+	ErrOfferNotFound = errors.New("waitlist offer not found")
+	// ErrOfferExpired is returned when claiming an offer after its deadline
+	ErrOfferExpired = errors.New("waitlist offer expired")
+)
+
+// Offer is a time-limited chance for a waitlisted subscriber to buy a freed seat
+type Offer struct {
+	ID         int               `json:"offerId"`
+	SessionID  string            `json:"sessionId"`
+	EventID    string            `json:"eventId,omitempty"`
+	Subscriber models.Subscriber `json:"-"`
+	OfferedAt  time.Time         `json:"offeredAt"`
+	ExpiresAt  time.Time         `json:"expiresAt"`
+	ClaimedAt  *time.Time        `json:"claimedAt,omitempty"`
+}
+
+// Status is a subscriber's place on the waitlist of a session
+type Status struct {
+	SessionID  string `json:"sessionId"`
+	OnWaitlist bool   `json:"onWaitlist"`
+	// Position counts from 1 among the subscribers still waiting for an offer; 0 while an offer is open
+	Position int    `json:"position,omitempty"`
+	Offer    *Offer `json:"offer,omitempty"`
+}
+
+// PendingSession is a session with freed seats that still have to be offered
+type PendingSession struct {
+	SessionID string
+	EventID   string
+	Seats     int
+}
+
+// Store keeps waitlist subscriptions, freed seats and claim offers in Postgres.
+// The waitlist of a session is its "waitlist" subscriptions in subscribed_at order.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore creates a waitlist store
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Join puts the subscriber at the end of the session's waitlist. Joining again while on the
+// waitlist keeps the current place; joining after an expired offer queues up again.
+func (s *Store) Join(ctx context.Context, subscriberID int, sessionID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO subscriptions (subscriber_id, category, target_uuid)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscriber_id, category, target_uuid) DO NOTHING`,
+		subscriberID, models.SubscriptionCategoryWaitlist, sessionID)
+	if err != nil {
+		return fmt.Errorf("error joining waitlist of session %s: %w", sessionID, err)
+	}
+
+	// A new entry drops the offer of an earlier entry, which was claimed or expired
+	if added, _ := result.RowsAffected(); added > 0 {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM waitlist_offers WHERE session_id = $1 AND subscriber_id = $2`,
+			sessionID, subscriberID); err != nil {
+			return fmt.Errorf("error clearing earlier waitlist offer: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing waitlist entry: %w", err)
+	}
+	return nil
+}
+
+// Leave removes the subscriber from the session's waitlist. An open offer is withdrawn and its
+// seat goes to the next subscriber.
+func (s *Store) Leave(ctx context.Context, subscriberID int, sessionID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE subscriber_id = $1 AND category = $2 AND target_uuid = $3`,
+		subscriberID, models.SubscriptionCategoryWaitlist, sessionID); err != nil {
+		return fmt.Errorf("error leaving waitlist of session %s: %w", sessionID, err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE waitlist_offers SET expired_at = NOW()
+		WHERE session_id = $1 AND subscriber_id = $2 AND claimed_at IS NULL AND expired_at IS NULL`,
+		sessionID, subscriberID)
+	if err != nil {
+		return fmt.Errorf("error withdrawing waitlist offer: %w", err)
+	}
+	if withdrawn, _ := result.RowsAffected(); withdrawn > 0 {
+		if err := addPendingSeats(ctx, tx, sessionID, "", int(withdrawn)); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing waitlist removal: %w", err)
+	}
+	return nil
+}
+
+// Status returns the subscriber's place on the session's waitlist
+func (s *Store) Status(ctx context.Context, subscriberID int, sessionID string) (*Status, error) {
+	status := &Status{SessionID: sessionID}
+
+	var subscribedAt time.Time
+	var subscriptionID int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT subscription_id, subscribed_at FROM subscriptions
+		WHERE subscriber_id = $1 AND category = $2 AND target_uuid = $3`,
+		subscriberID, models.SubscriptionCategoryWaitlist, sessionID).Scan(&subscriptionID, &subscribedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting waitlist entry: %w", err)
+	}
+	status.OnWaitlist = true
+
+	offer := Offer{SessionID: sessionID}
+	var eventID sql.NullString
+	err = s.db.QueryRowContext(ctx, `
+		SELECT offer_id, event_id, offered_at, expires_at FROM waitlist_offers
+		WHERE session_id = $1 AND subscriber_id = $2 AND claimed_at IS NULL AND expired_at IS NULL`,
+		sessionID, subscriberID).Scan(&offer.ID, &eventID, &offer.OfferedAt, &offer.ExpiresAt)
+	if err == nil {
+		offer.EventID = eventID.String
+		status.Offer = &offer
+		return status, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error getting waitlist offer: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM subscriptions sub
+		WHERE sub.category = $1 AND sub.target_uuid = $2
+		  AND (sub.subscribed_at, sub.subscription_id) <= ($3, $4)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM waitlist_offers o
+		      WHERE o.session_id = sub.target_uuid AND o.subscriber_id = sub.subscriber_id)`,
+		models.SubscriptionCategoryWaitlist, sessionID, subscribedAt, subscriptionID).Scan(&status.Position)
+	if err != nil {
+		return nil, fmt.Errorf("error getting waitlist position: %w", err)
+	}
+	return status, nil
+}
+
+// Release records seats freed for the session's waitlist. source identifies the change that freed
+// them; releasing the same source again, or while nobody is waiting, is ignored and reported as false.
+func (s *Store) Release(ctx context.Context, source, sessionID, eventID string, seats int) (bool, error) {
+	if seats <= 0 {
+		return false, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Most sessions never sell out, so freed seats are only kept for sessions with a waitlist
+	var waiting bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+		    SELECT 1 FROM subscriptions sub
+		    WHERE sub.category = $1 AND sub.target_uuid = $2
+		      AND NOT EXISTS (
+		          SELECT 1 FROM waitlist_offers o
+		          WHERE o.session_id = sub.target_uuid AND o.subscriber_id = sub.subscriber_id))`,
+		models.SubscriptionCategoryWaitlist, sessionID).Scan(&waiting)
+	if err != nil {
+		return false, fmt.Errorf("error checking waitlist of session %s: %w", sessionID, err)
+	}
+	if !waiting {
+		return false, nil
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO waitlist_releases (source, session_id, seats) VALUES ($1, $2, $3)
+		ON CONFLICT (source) DO NOTHING`, source, sessionID, seats)
+	if err != nil {
+		return false, fmt.Errorf("error recording seat release %s: %w", source, err)
+	}
+	if added, _ := result.RowsAffected(); added == 0 {
+		return false, nil
+	}
+
+	if err := addPendingSeats(ctx, tx, sessionID, eventID, seats); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error committing seat release %s: %w", source, err)
+	}
+	return true, nil
+}
+
+// ExpireOffers closes open offers past their deadline, drops their subscribers from the waitlist
+// and frees their seats for the next subscribers. It returns how many offers expired.
+func (s *Store) ExpireOffers(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE waitlist_offers SET expired_at = NOW()
+		WHERE claimed_at IS NULL AND expired_at IS NULL AND expires_at <= NOW()
+		RETURNING session_id, COALESCE(event_id, ''), subscriber_id`)
+	if err != nil {
+		return 0, fmt.Errorf("error expiring waitlist offers: %w", err)
+	}
+
+	type expired struct {
+		sessionID    string
+		eventID      string
+		subscriberID int
+	}
+	var offers []expired
+	for rows.Next() {
+		var offer expired
+		if err := rows.Scan(&offer.sessionID, &offer.eventID, &offer.subscriberID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning expired waitlist offer: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating expired waitlist offers: %w", err)
+	}
+
+	for _, offer := range offers {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE subscriber_id = $1 AND category = $2 AND target_uuid = $3`,
+			offer.subscriberID, models.SubscriptionCategoryWaitlist, offer.sessionID); err != nil {
+			return 0, fmt.Errorf("error removing expired waitlist entry: %w", err)
+		}
+		if err := addPendingSeats(ctx, tx, offer.sessionID, offer.eventID, 1); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing expired waitlist offers: %w", err)
+	}
+	return len(offers), nil
+}
+
+// PendingSessions returns the sessions with freed seats still to offer
+func (s *Store) PendingSessions(ctx context.Context) ([]PendingSession, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT session_id, COALESCE(event_id, ''), pending_seats FROM waitlist_sessions
+		WHERE pending_seats > 0
+		ORDER BY updated_at`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sessions with freed seats: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []PendingSession
+	for rows.Next() {
+		var session PendingSession
+		if err := rows.Scan(&session.SessionID, &session.EventID, &session.Seats); err != nil {
+			return nil, fmt.Errorf("error scanning session with freed seats: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions with freed seats: %w", err)
+	}
+	return sessions, nil
+}
+
+// CreateOffers offers up to limit of the session's freed seats to the longest waiting subscribers,
+// one seat each, open for ttl. Seats left over once nobody is waiting are dropped.
+func (s *Store) CreateOffers(ctx context.Context, sessionID string, limit int, ttl time.Duration) ([]Offer, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var pending int
+	var eventID sql.NullString
+	err = tx.QueryRowContext(ctx, `SELECT pending_seats, event_id FROM waitlist_sessions WHERE session_id = $1 FOR UPDATE`,
+		sessionID).Scan(&pending, &eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking freed seats of session %s: %w", sessionID, err)
+	}
+	if pending < limit {
+		limit = pending
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.subscriber_id, s.subscriber_mail, s.user_id, s.created_at
+		FROM subscriptions sub
+		JOIN subscribers s ON s.subscriber_id = sub.subscriber_id
+		WHERE sub.category = $1 AND sub.target_uuid = $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM waitlist_offers o
+		      WHERE o.session_id = sub.target_uuid AND o.subscriber_id = sub.subscriber_id)
+		ORDER BY sub.subscribed_at, sub.subscription_id
+		LIMIT $3
+		FOR UPDATE OF sub SKIP LOCKED`,
+		models.SubscriptionCategoryWaitlist, sessionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying waitlist of session %s: %w", sessionID, err)
+	}
+	var subscribers []models.Subscriber
+	for rows.Next() {
+		var subscriber models.Subscriber
+		var userID sql.NullString
+		if err := rows.Scan(&subscriber.SubscriberID, &subscriber.SubscriberMail, &userID, &subscriber.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning waitlisted subscriber: %w", err)
+		}
+		if userID.Valid {
+			subscriber.UserID = &userID.String
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating waitlist of session %s: %w", sessionID, err)
+	}
+
+	offers := make([]Offer, 0, len(subscribers))
+	expiresAt := time.Now().Add(ttl)
+	for _, subscriber := range subscribers {
+		offer := Offer{SessionID: sessionID, EventID: eventID.String, Subscriber: subscriber}
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO waitlist_offers (session_id, event_id, subscriber_id, expires_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING offer_id, offered_at, expires_at`,
+			sessionID, eventID, subscriber.SubscriberID, expiresAt).Scan(&offer.ID, &offer.OfferedAt, &offer.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("error creating waitlist offer for subscriber %d: %w", subscriber.SubscriberID, err)
+		}
+		offers = append(offers, offer)
+	}
+
+	remaining := pending - len(offers)
+	if len(offers) < limit {
+		// Nobody else is waiting, the seats are back on general sale
+		remaining = 0
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE waitlist_sessions SET pending_seats = $2, updated_at = NOW() WHERE session_id = $1`,
+		sessionID, remaining); err != nil {
+		return nil, fmt.Errorf("error updating freed seats of session %s: %w", sessionID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing waitlist offers: %w", err)
+	}
+	return offers, nil
+}
+
+// Claim accepts an open offer and takes the subscriber off the waitlist. Claiming an offer again
+// returns it unchanged.
+func (s *Store) Claim(ctx context.Context, offerID, subscriberID int) (*Offer, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	offer := Offer{ID: offerID}
+	var eventID sql.NullString
+	var expiredAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT session_id, event_id, offered_at, expires_at, claimed_at, expired_at
+		FROM waitlist_offers
+		WHERE offer_id = $1 AND subscriber_id = $2
+		FOR UPDATE`, offerID, subscriberID).Scan(&offer.SessionID, &eventID, &offer.OfferedAt, &offer.ExpiresAt, &offer.ClaimedAt, &expiredAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOfferNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting waitlist offer %d: %w", offerID, err)
+	}
+	offer.EventID = eventID.String
+
+	if offer.ClaimedAt != nil {
+		return &offer, nil
+	}
+	if expiredAt.Valid || !offer.ExpiresAt.After(time.Now()) {
+		return nil, ErrOfferExpired
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE waitlist_offers SET claimed_at = $2 WHERE offer_id = $1`, offerID, now); err != nil {
+		return nil, fmt.Errorf("error claiming waitlist offer %d: %w", offerID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE subscriber_id = $1 AND category = $2 AND target_uuid = $3`,
+		subscriberID, models.SubscriptionCategoryWaitlist, offer.SessionID); err != nil {
+		return nil, fmt.Errorf("error removing claimed waitlist entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing waitlist claim: %w", err)
+	}
+	offer.ClaimedAt = &now
+	return &offer, nil
+}
+
+// addPendingSeats adds freed seats to the session inside tx
+func addPendingSeats(ctx context.Context, tx *sql.Tx, sessionID, eventID string, seats int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO waitlist_sessions (session_id, event_id, pending_seats) VALUES ($1, NULLIF($2, ''), $3)
+		ON CONFLICT (session_id) DO UPDATE
+		SET pending_seats = waitlist_sessions.pending_seats + EXCLUDED.pending_seats,
+		    event_id = COALESCE(EXCLUDED.event_id, waitlist_sessions.event_id),
+		    updated_at = NOW()`, sessionID, eventID, seats)
+	if err != nil {
+		return fmt.Errorf("error adding freed seats to session %s: %w", sessionID, err)
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"flag"
 	"log"
 	"net/http"
-	"sync"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -21,10 +25,18 @@ import (
 	"ms-scheduling/internal/eventbridge"
 	"ms-scheduling/internal/handlers"
 	"ms-scheduling/internal/kafka"
+	"ms-scheduling/internal/notify"
+	"ms-scheduling/internal/outbox"
+	"ms-scheduling/internal/preferences"
+	"ms-scheduling/internal/queue"
 	"ms-scheduling/internal/reminder"
+	"ms-scheduling/internal/schedule"
 	"ms-scheduling/internal/scheduler"
 	"ms-scheduling/internal/services"
+	"ms-scheduling/internal/sqsworker"
+	"ms-scheduling/internal/supervisor"
 	"ms-scheduling/internal/trending"
+	"ms-scheduling/internal/waitlist"
 )
 
 // Types moved to internal packages.
@@ -47,6 +59,13 @@ func main() {
 		return
 	}
 
+	// SIGINT and SIGTERM cancel the root context: workers finish their current batch and the HTTP server drains
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers are restarted with a backoff when they crash and reported on /livez
+	workers := supervisor.New(cfg.WorkerRestartInitialBackoff, cfg.WorkerRestartMaxBackoff)
+
 	// Load AWS configuration with credentials from environment variables
 	awsOptions := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(cfg.AWSRegion),
@@ -79,11 +98,6 @@ func main() {
 	})
 	log.Println("Clients initialized")
 
-	schedulerClient := awsscheduler.NewFromConfig(awsCfg)
-
-	// Initialize the scheduler service
-	schedulerService := eventbridge.NewService(cfg, schedulerClient)
-
 	// Initialize database service
 	dbService, err := services.NewDatabaseService(cfg.PostgresDSN)
 	if err != nil {
@@ -96,38 +110,159 @@ func main() {
 		log.Fatalf("Failed to initialize database tables: %v", err)
 	}
 
+	// Queues live on SQS, or on Postgres or in memory to run without AWS
+	queueFactory, err := queue.NewFactory(cfg.QueueBackend, sqsClient, dbService.DB, cfg.QueuePollInterval)
+	if err != nil {
+		log.Fatalf("Failed to initialize queues: %v", err)
+	}
+	if queueFactory.Backend() != queue.BackendSQS && cfg.SchedulerBackend == "eventbridge" {
+		// EventBridge Scheduler can only deliver to SQS
+		log.Fatalf("QUEUE_BACKEND %q requires SCHEDULER_BACKEND=postgres", cfg.QueueBackend)
+	}
+	schedulingQueue := queueFactory.Queue("session-scheduling", cfg.SQSSessionSchedulingQueueURL)
+	remindersQueue := queueFactory.Queue("session-reminders", cfg.SQSSessionRemindersQueueURL)
+	trendingQueue := queueFactory.Queue("trending", cfg.SQSTrendingQueueURL)
+	log.Printf("Using %s queue backend", queueFactory.Backend())
+
+	// Schedule payloads can also be sent straight to the session queues (Postgres backend, force-fire)
+	scheduleQueues := schedule.NewQueueSender(map[schedule.Target]queue.Queue{
+		schedule.TargetSessionScheduling: schedulingQueue,
+		schedule.TargetSessionReminders:  remindersQueue,
+	})
+
+	// Initialize the scheduler service on the configured backend
+	var scheduleBackend schedule.Scheduler
+	switch cfg.SchedulerBackend {
+	case "postgres":
+		// Due jobs are fired from this process straight onto the session queues
+		postgresScheduler := schedule.NewPostgresScheduler(dbService.DB, scheduleQueues, cfg.SchedulerPollInterval, cfg.SchedulerBatchSize)
+		postgresScheduler.SetRetryPolicy(cfg.SchedulerMaxAttempts, cfg.SchedulerRetryInitialBackoff, cfg.SchedulerRetryMaxBackoff)
+		workers.Go(ctx, "postgres scheduler", postgresScheduler.Run)
+		scheduleBackend = postgresScheduler
+	case "eventbridge":
+		scheduleBackend = eventbridge.NewService(cfg, awsscheduler.NewFromConfig(awsCfg))
+	default:
+		log.Fatalf("Unknown SCHEDULER_BACKEND %q, expected eventbridge or postgres", cfg.SchedulerBackend)
+	}
+	schedulerService := schedule.NewService(scheduleBackend)
+	schedulerService.SetQueueSender(scheduleQueues)
+
+	// Jobs already due when they are scheduled are skipped, fired right away or clamped to shortly from now
+	for target, policyValue := range map[schedule.Target]string{
+		schedule.TargetSessionScheduling: cfg.SchedulePastDuePolicy,
+		schedule.TargetSessionReminders:  cfg.ReminderPastDuePolicy,
+	} {
+		policy, err := schedule.ParsePastDuePolicy(policyValue)
+		if err != nil {
+			log.Fatalf("Invalid past-due policy for %s: %v", target, err)
+		}
+		schedulerService.SetPastDuePolicy(target, policy)
+	}
+	schedulerService.SetPastDueClampDelay(cfg.PastDueClampDelay)
+
+	// Every schedule change is mirrored in the scheduled_jobs table for the admin API
+	scheduleRegistry := schedule.NewRegistry(dbService.DB, map[schedule.Target]string{
+		schedule.TargetSessionScheduling: cfg.SQSSessionSchedulingQueueARN,
+		schedule.TargetSessionReminders:  cfg.SQSSessionRemindersQueueARN,
+	})
+	schedulerService.SetRegistry(scheduleRegistry)
+
+	// Reminder rules: the REMINDER_RULES defaults plus the follow-up email, overridden per organization from the database
+	defaultReminderRules, err := schedule.ParseReminderRules(cfg.ReminderRules)
+	if err != nil {
+		log.Fatalf("Invalid REMINDER_RULES: %v", err)
+	}
+	defaultReminderRules = schedule.WithFollowUp(defaultReminderRules, cfg.FollowUpDelay)
+	reminderRuleStore := schedule.NewRuleStore(dbService.DB, defaultReminderRules, schedule.NewEventQueryOrganizations(cfg, httpClient))
+	schedulerService.SetRuleSource(reminderRuleStore)
+
+	// Periodically repair drift between sessions in the event-query service and their schedules
+	scheduleReconciler := schedule.NewReconciler(schedulerService, schedule.NewEventQuerySessionSource(cfg, httpClient),
+		cfg.ScheduleReconcilePageSize, cfg.ScheduleReconcileInterval, cfg.ScheduleReconcileDryRun)
+	if cfg.ScheduleReconcileInterval > 0 {
+		workers.Go(ctx, "schedule reconciler", scheduleReconciler.Run)
+	} else {
+		log.Println("Schedule reconcile interval is 0, periodic reconciliation disabled")
+	}
+	log.Printf("Scheduler initialized with %s backend", cfg.SchedulerBackend)
+
 	// Initialize Keycloak client
 	keycloakClient := services.NewKeycloakClient(cfg.KeycloakURL, cfg.KeycloakRealm, cfg.ClientID, cfg.ClientSecret)
 
 	// Initialize email service
 	emailService := services.NewEmailService(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.FromEmail, cfg.FromName)
 
-	// Initialize subscriber service
-	subscriberService := services.NewSubscriberService(dbService.DB, keycloakClient, emailService, &cfg)
+	// Route all outgoing email through the outbox; the dispatcher delivers it via SMTP
+	outboxStore := outbox.NewStore(dbService.DB, cfg.EmailOutboxMaxAttempts)
+	outboxSender := outbox.NewSender(outboxStore)
+
+	// Notifications are routed to the channels chosen per subscriber and email type; email goes to the outbox
+	notificationRouter := notify.NewRouter(notify.ParseChannelTypes(cfg.NotifyDefaultChannels)...)
+	notificationRouter.Register(notify.NewEmailChannel(outboxSender))
+	if cfg.NotifyWebhookURL != "" {
+		notificationRouter.Register(notify.NewWebhookChannel(cfg.NotifyWebhookURL, httpClient))
+	}
+
+	// Every notification consults the subscriber's preferences before it is delivered
+	preferenceStore := preferences.NewStore(dbService.DB)
+	notificationRouter.SetPreferences(preferenceStore)
 
-	// Initialize email manager with templates for better emails
+	// Every email is rendered by the template generator and sent through the email manager
 	templateGenerator := templates.NewStandardTemplateGenerator()
-	emailManager := email.NewEmailManager(emailService, cfg, templateGenerator)
-	subscriberService.SetEmailManager(emailManager)
+	emailManager := email.NewEmailManager(notificationRouter, cfg, templateGenerator)
+
+	// Skip notifications already sent for the same source change (Kafka redelivery, Debezium replay)
+	dedupeStore := outbox.NewDedupeStore(dbService.DB)
+	emailManager.SetDeduplicator(dedupeStore)
 	log.Printf("Email manager initialized with professional templates")
 
+	// Initialize subscriber service
+	subscriberService := services.NewSubscriberService(dbService.DB, keycloakClient, emailManager, &cfg)
+
+	// Signed one-click unsubscribe links; without a secret emails link to the frontend instead
+	var unsubscribeSigner *auth.UnsubscribeSigner
+	if cfg.UnsubscribeSecret != "" {
+		unsubscribeSigner = auth.NewUnsubscribeSigner(cfg.UnsubscribeSecret, cfg.UnsubscribeTokenTTL, cfg.UnsubscribeBaseURL)
+		subscriberService.SetUnsubscribeSigner(unsubscribeSigner)
+	}
+
+	// Waitlisted subscribers are offered freed seats in FIFO order, a batch per session at a time.
+	// Without a claim secret no offers can be sent, so the waitlist is neither filled nor released.
+	var waitlistStore *waitlist.Store
+	var waitlistSigner *auth.WaitlistClaimSigner
+	if cfg.WaitlistClaimSecret != "" {
+		waitlistStore = waitlist.NewStore(dbService.DB)
+		waitlistSigner = auth.NewWaitlistClaimSigner(cfg.WaitlistClaimSecret, cfg.WaitlistClaimBaseURL)
+		waitlistNotifier := waitlist.NewNotifier(waitlistStore, subscriberService, waitlistSigner,
+			cfg.WaitlistNotifyBatch, cfg.WaitlistNotifyInterval, cfg.WaitlistClaimTTL)
+		workers.Go(ctx, "waitlist notifier", waitlistNotifier.Run)
+	} else {
+		log.Println("No waitlist claim secret configured, waitlist offers disabled")
+	}
+
+	// Start the email outbox dispatcher
+	outboxDispatcher := outbox.NewDispatcher(outboxStore, emailService, cfg.EmailOutboxBatchSize,
+		cfg.EmailOutboxPollInterval, cfg.EmailOutboxBaseBackoff, cfg.EmailOutboxMaxBackoff)
+	workers.Go(ctx, "email outbox dispatcher", outboxDispatcher.Run)
+
+	// Messages that keep failing are published to "<topic><KAFKA_DLQ_SUFFIX>" for inspection and replay
+	var deadLetterQueue *kafka.DeadLetterQueue
+	if cfg.KafkaURL != "" && cfg.KafkaDLQSuffix != "" {
+		deadLetterQueue = kafka.NewDeadLetterQueue(cfg.KafkaURL, cfg.KafkaDLQSuffix)
+		defer deadLetterQueue.Close()
+	}
+
 	// Start Kafka consumers in separate goroutines if Kafka URL is configured
 	if cfg.KafkaURL != "" {
-		var wg sync.WaitGroup
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
 		// Start event sessions consumer if topic is configured
 		if cfg.EventSessionsKafkaTopic != "" {
 			log.Printf("Starting event sessions consumer for topic %s at %s", cfg.EventSessionsKafkaTopic, cfg.KafkaURL)
 			sessionConsumer := kafka.NewSessionConsumer(cfg, schedulerService, subscriberService)
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				if err := sessionConsumer.StartConsuming(ctx); err != nil {
-					log.Printf("Error in session consumer: %v", err)
-				}
-			}()
+			sessionConsumer.SetDeadLetterQueue(deadLetterQueue)
+			if waitlistStore != nil {
+				sessionConsumer.SetWaitlist(waitlistStore, cfg.WaitlistReopenSeats)
+			}
+			workers.Go(ctx, "session consumer", sessionConsumer.StartConsuming)
 		}
 
 		// Start orders consumer if any order topic is configured
@@ -136,90 +271,93 @@ func main() {
 		log.Printf("Starting orders consumer for topics (created: %s, updated: %s, cancelled: %s) at %s",
 			cfg.OrdersKafkaTopic, cfg.OrdersUpdatedKafkaTopic, cfg.OrdersCancelledKafkaTopic, cfg.KafkaURL)
 		orderConsumer := kafka.NewOrderConsumer(cfg, subscriberService)
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := orderConsumer.StartConsuming(ctx); err != nil {
-				log.Printf("Error in order consumer: %v", err)
-			}
-		}() // Start events consumer if topic is configured
+		orderConsumer.SetDeadLetterQueue(deadLetterQueue)
+		if waitlistStore != nil {
+			orderConsumer.SetWaitlist(waitlistStore)
+		}
+		workers.Go(ctx, "order consumer", orderConsumer.StartConsuming)
+
+		// Start events consumer if topic is configured
 		if cfg.EventsKafkaTopic != "" {
 			log.Printf("Starting events consumer for topic %s at %s", cfg.EventsKafkaTopic, cfg.KafkaURL)
 			eventConsumer := kafka.NewEventConsumer(cfg, subscriberService)
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				if err := eventConsumer.StartConsuming(ctx); err != nil {
-					log.Printf("Error in event consumer: %v", err)
-				}
-			}()
+			eventConsumer.SetDeadLetterQueue(deadLetterQueue)
+			workers.Go(ctx, "event consumer", eventConsumer.StartConsuming)
 		}
 
-		// We don't wait for wg.Wait() so the SQS processing can continue
+		// Start payment consumer; like the order consumer it skips topics that are not configured
+		log.Printf("Starting payment consumer for topics (success: %s, failed: %s, refunded: %s) at %s",
+			cfg.PaymentSuccessKafkaTopic, cfg.PaymentFailedKafkaTopic, cfg.PaymentRefundedKafkaTopic, cfg.KafkaURL)
+		paymentConsumer := kafka.NewPaymentConsumer(cfg, subscriberService)
+		paymentConsumer.SetDeadLetterQueue(deadLetterQueue)
+		workers.Go(ctx, "payment consumer", paymentConsumer.StartConsuming)
 	} else {
 		log.Println("Kafka URL not configured, skipping Kafka consumers setup")
 	}
 
-	// Start trending job processor in a separate goroutine if trending queue URL is configured
-	if cfg.SQSTrendingQueueURL != "" {
-		log.Printf("Starting trending job processor for queue: %s", cfg.SQSTrendingQueueURL)
-		trendingProcessor := trending.NewProcessor(sqsClient, httpClient, cfg)
-		var trendingWg sync.WaitGroup
-		trendingWg.Add(1)
-		go func() {
-			defer trendingWg.Done()
-			err := trendingProcessor.ProcessMessages(context.Background())
-			if err != nil {
-				log.Printf("Error processing trending messages: %v", err)
-			}
-		}()
-		// We don't wait for trendingWg.Wait() so other processing can continue
+	// Start trending job processor in a separate goroutine if the trending queue is configured
+	if trendingQueue != nil {
+		log.Printf("Starting trending job processor for queue: %s", trendingQueue.Name())
+		trendingProcessor := trending.NewProcessor(trendingQueue, httpClient, cfg)
+		workers.Go(ctx, "trending processor", trendingProcessor.ProcessMessages)
 	} else {
 		log.Println("Trending queue URL not configured, skipping trending processor setup")
 	}
 
-	// Start session scheduling processor in a separate goroutine if session scheduling queue URL is configured
-	if cfg.SQSSessionSchedulingQueueURL != "" {
-		log.Printf("Starting session scheduling processor for queue: %s", cfg.SQSSessionSchedulingQueueURL)
-		sessionProcessor := scheduler.NewProcessor(sqsClient, httpClient, cfg)
-		var sessionWg sync.WaitGroup
-		sessionWg.Add(1)
-		go func() {
-			defer sessionWg.Done()
-			err := sessionProcessor.ProcessMessages(context.Background())
-			if err != nil {
-				log.Printf("Error processing session scheduling messages: %v", err)
-			}
-		}()
-		// We don't wait for sessionWg.Wait() so other processing can continue
+	// Session scheduling messages that keep failing are moved to their own dead-letter queue
+	sqsDeadLetterQueues := make(map[string]*sqsworker.DeadLetterQueue)
+	var schedulingDLQ *sqsworker.DeadLetterQueue
+	if dlq := queueFactory.Queue("session-scheduling-dlq", cfg.SQSSchedulingDLQURL); schedulingQueue != nil && dlq != nil {
+		schedulingDLQ = sqsworker.NewDeadLetterQueue(dlq, schedulingQueue)
+		sqsDeadLetterQueues["session-scheduling"] = schedulingDLQ
+	}
+
+	// Start session scheduling processor in a separate goroutine if the session scheduling queue is configured
+	if schedulingQueue != nil {
+		log.Printf("Starting session scheduling processor for queue: %s", schedulingQueue.Name())
+		sessionProcessor := scheduler.NewProcessor(schedulingQueue, httpClient, cfg)
+		sessionProcessor.SetDeadLetterQueue(schedulingDLQ)
+		workers.Go(ctx, "session scheduling processor", sessionProcessor.ProcessMessages)
 	} else {
 		log.Println("Session scheduling queue URL not configured, skipping session processor setup")
 	}
 
-	// Start reminder processor in a separate goroutine if reminder queue URL is configured
-	if cfg.SQSSessionRemindersQueueURL != "" {
-		log.Printf("Starting reminder processor for queue: %s", cfg.SQSSessionRemindersQueueURL)
-		reminderProcessor := reminder.NewProcessor(sqsClient, httpClient, cfg, subscriberService)
-		var reminderWg sync.WaitGroup
-		reminderWg.Add(1)
-		go func() {
-			defer reminderWg.Done()
-			err := reminderProcessor.ProcessMessages(context.Background())
-			if err != nil {
-				log.Printf("Error processing reminder messages: %v", err)
-			}
-		}()
-		// We don't wait for reminderWg.Wait() so other processing can continue
+	// Start reminder processor in a separate goroutine if the reminder queue is configured
+	if remindersQueue != nil {
+		log.Printf("Starting reminder processor for queue: %s", remindersQueue.Name())
+		reminderProcessor := reminder.NewProcessor(remindersQueue, httpClient, cfg, subscriberService)
+		workers.Go(ctx, "reminder processor", reminderProcessor.ProcessMessages)
 	} else {
 		log.Println("Reminder queue URL not configured, skipping reminder processor setup")
 	}
 
 	// Set up the HTTP server for subscription API
-	setupHTTPServer(cfg, subscriberService, dbService)
+	server := setupHTTPServer(cfg, subscriberService, dbService, preferenceStore, unsubscribeSigner, deadLetterQueue, sqsDeadLetterQueues, schedulerService, scheduleRegistry, scheduleReconciler, reminderRuleStore, waitlistStore, waitlistSigner, outboxStore, workers)
+	log.Printf("Starting HTTP server on %s", server.Addr)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server failed: %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Printf("Shutting down, waiting up to %s for the HTTP server and workers", cfg.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down HTTP server: %v", err)
+	}
+	if err := workers.Wait(shutdownCtx); err != nil {
+		log.Printf("Shutdown timed out: %v", err)
+		return
+	}
+	log.Println("Shutdown complete")
 }
 
-// setupHTTPServer configures and starts the HTTP server
-func setupHTTPServer(cfg config.Config, subscriberService *services.SubscriberService, dbService *services.DatabaseService) {
+// setupHTTPServer configures the routes of the HTTP server and returns it, ready to be started
+func setupHTTPServer(cfg config.Config, subscriberService *services.SubscriberService, dbService *services.DatabaseService, preferenceStore *preferences.Store, unsubscribeSigner *auth.UnsubscribeSigner, deadLetterQueue *kafka.DeadLetterQueue, sqsDeadLetterQueues map[string]*sqsworker.DeadLetterQueue, schedulerService *schedule.Service, scheduleRegistry *schedule.Registry, scheduleReconciler *schedule.Reconciler, reminderRuleStore *schedule.RuleStore, waitlistStore *waitlist.Store, waitlistSigner *auth.WaitlistClaimSigner, outboxStore *outbox.Store, workers *supervisor.Supervisor) *http.Server {
 	router := mux.NewRouter()
 
 	// Add global OPTIONS handler for CORS preflight requests
@@ -230,13 +368,20 @@ func setupHTTPServer(cfg config.Config, subscriberService *services.SubscriberSe
 	// Apply CORS middleware to all routes
 	router.Use(auth.CORSMiddleware(cfg))
 
+	// Verify access tokens against the Keycloak realm JWKS
+	tokenVerifier := auth.NewTokenVerifier(cfg, &http.Client{Timeout: 10 * time.Second})
+	authMiddleware := auth.AuthMiddleware(tokenVerifier)
+	roleAuthorizer := auth.NewRoleAuthorizer(cfg.ClientID)
+
 	// Create subscription handlers
 	subscriptionHandler := handlers.NewSubscriptionHandler(subscriberService, cfg)
 	sessionSubscriptionHandler := handlers.NewSessionSubscriptionHandler(subscriberService, cfg)
+	organizationSubscriptionHandler := handlers.NewOrganizationSubscriptionHandler(subscriberService, cfg)
+	preferenceHandler := handlers.NewPreferenceHandler(subscriberService, preferenceStore)
 
 	// Event subscription API routes with authentication
 	eventApiRouter := router.PathPrefix("/api/scheduler/subscription/v1").Subrouter()
-	eventApiRouter.Use(auth.AuthMiddleware)
+	eventApiRouter.Use(authMiddleware)
 
 	// Regular user endpoints for event subscriptions
 	eventApiRouter.HandleFunc("/subscribe", subscriptionHandler.Subscribe).Methods("POST", "OPTIONS")
@@ -246,12 +391,12 @@ func setupHTTPServer(cfg config.Config, subscriberService *services.SubscriberSe
 
 	// Admin endpoints for event subscriptions with additional middleware
 	eventAdminRouter := eventApiRouter.PathPrefix("/event-subscribers").Subrouter()
-	eventAdminRouter.Use(auth.AdminMiddleware)
+	eventAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.EventSubscribersRoles...))
 	eventAdminRouter.HandleFunc("/{eventId}", subscriptionHandler.GetEventSubscribers).Methods("GET", "OPTIONS")
 
 	// Session subscription API routes with authentication
 	sessionApiRouter := router.PathPrefix("/api/scheduler/session-subscription/v1").Subrouter()
-	sessionApiRouter.Use(auth.AuthMiddleware)
+	sessionApiRouter.Use(authMiddleware)
 
 	// Regular user endpoints for session subscriptions
 	sessionApiRouter.HandleFunc("/subscribe", sessionSubscriptionHandler.Subscribe).Methods("POST", "OPTIONS")
@@ -261,11 +406,112 @@ func setupHTTPServer(cfg config.Config, subscriberService *services.SubscriberSe
 
 	// Admin endpoints for session subscriptions with additional middleware
 	sessionAdminRouter := sessionApiRouter.PathPrefix("/session-subscribers").Subrouter()
-	sessionAdminRouter.Use(auth.AdminMiddleware)
+	sessionAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.SessionSubscribersRoles...))
 	sessionAdminRouter.HandleFunc("/{sessionId}", sessionSubscriptionHandler.GetSessionSubscribers).Methods("GET", "OPTIONS")
 
+	// Organization subscription API routes with authentication
+	organizationApiRouter := router.PathPrefix("/api/scheduler/organization-subscription/v1").Subrouter()
+	organizationApiRouter.Use(authMiddleware)
+
+	// Regular user endpoints for organization subscriptions
+	organizationApiRouter.HandleFunc("/subscribe", organizationSubscriptionHandler.Subscribe).Methods("POST", "OPTIONS")
+	organizationApiRouter.HandleFunc("/unsubscribe/{organizationId}", organizationSubscriptionHandler.Unsubscribe).Methods("DELETE", "OPTIONS")
+	organizationApiRouter.HandleFunc("/is-subscribed/{organizationId}", organizationSubscriptionHandler.IsSubscribed).Methods("GET", "OPTIONS")
+	organizationApiRouter.HandleFunc("/user-subscriptions", organizationSubscriptionHandler.GetUserSubscriptions).Methods("GET", "OPTIONS")
+
+	// Admin endpoints for organization subscriptions with additional middleware
+	organizationAdminRouter := organizationApiRouter.PathPrefix("/organization-subscribers").Subrouter()
+	organizationAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.OrganizationSubscribersRoles...))
+	organizationAdminRouter.HandleFunc("/{organizationId}", organizationSubscriptionHandler.GetOrganizationSubscribers).Methods("GET", "OPTIONS")
+
+	if waitlistSigner != nil {
+		// Waitlist claim links from emails (no authentication required, the link is signed). They are
+		// registered before the authenticated waitlist routes sharing their prefix.
+		waitlistHandler := handlers.NewWaitlistHandler(subscriberService, waitlistStore, waitlistSigner, cfg)
+		router.HandleFunc("/api/scheduler/waitlist/v1/claim", waitlistHandler.ConfirmClaim).Methods("GET", "OPTIONS")
+		router.HandleFunc("/api/scheduler/waitlist/v1/claim", waitlistHandler.Claim).Methods("POST")
+
+		// Waitlist API routes with authentication
+		waitlistApiRouter := router.PathPrefix("/api/scheduler/waitlist/v1").Subrouter()
+		waitlistApiRouter.Use(authMiddleware)
+		waitlistApiRouter.HandleFunc("/subscribe", waitlistHandler.Subscribe).Methods("POST", "OPTIONS")
+		waitlistApiRouter.HandleFunc("/unsubscribe/{sessionId}", waitlistHandler.Unsubscribe).Methods("DELETE", "OPTIONS")
+		waitlistApiRouter.HandleFunc("/status/{sessionId}", waitlistHandler.GetStatus).Methods("GET", "OPTIONS")
+		waitlistApiRouter.HandleFunc("/user-subscriptions", waitlistHandler.GetUserSubscriptions).Methods("GET", "OPTIONS")
+	}
+
+	// Notification preference API routes with authentication
+	preferenceApiRouter := router.PathPrefix("/api/scheduler/preferences/v1").Subrouter()
+	preferenceApiRouter.Use(authMiddleware)
+	preferenceApiRouter.HandleFunc("", preferenceHandler.GetPreferences).Methods("GET", "OPTIONS")
+	preferenceApiRouter.HandleFunc("", preferenceHandler.SavePreference).Methods("PUT", "OPTIONS")
+	preferenceApiRouter.HandleFunc("/{category}/{emailType}", preferenceHandler.DeletePreference).Methods("DELETE", "OPTIONS")
+
+	// Unsubscribe links from emails (no authentication required, the link is signed)
+	if unsubscribeSigner != nil {
+		unsubscribeHandler := handlers.NewUnsubscribeHandler(subscriberService, unsubscribeSigner)
+		router.HandleFunc("/api/scheduler/unsubscribe/v1", unsubscribeHandler.ConfirmUnsubscribe).Methods("GET", "OPTIONS")
+		router.HandleFunc("/api/scheduler/unsubscribe/v1", unsubscribeHandler.Unsubscribe).Methods("POST")
+	}
+
+	// Admin endpoints for dead-lettered Kafka messages
+	if deadLetterQueue != nil {
+		dlqHandler := handlers.NewDLQHandler(deadLetterQueue, cfg)
+		dlqAdminRouter := router.PathPrefix("/api/scheduler/admin/v1/dlq").Subrouter()
+		dlqAdminRouter.Use(authMiddleware)
+		dlqAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.AdminRoles...))
+		dlqAdminRouter.HandleFunc("/{topic}", dlqHandler.ListEntries).Methods("GET", "OPTIONS")
+		dlqAdminRouter.HandleFunc("/{topic}/replay", dlqHandler.Replay).Methods("POST", "OPTIONS")
+	}
+
+	// Admin endpoints for dead-lettered SQS messages
+	if len(sqsDeadLetterQueues) > 0 {
+		sqsDLQHandler := handlers.NewSQSDLQHandler(sqsDeadLetterQueues)
+		sqsDLQAdminRouter := router.PathPrefix("/api/scheduler/admin/v1/sqs-dlq").Subrouter()
+		sqsDLQAdminRouter.Use(authMiddleware)
+		sqsDLQAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.AdminRoles...))
+		sqsDLQAdminRouter.HandleFunc("/{queue}", sqsDLQHandler.ListEntries).Methods("GET", "OPTIONS")
+		sqsDLQAdminRouter.HandleFunc("/{queue}/redrive", sqsDLQHandler.Redrive).Methods("POST", "OPTIONS")
+	}
+
+	// Admin endpoints for the email delivery log
+	emailHistoryHandler := handlers.NewEmailHistoryHandler(outboxStore)
+	emailHistoryAdminRouter := router.PathPrefix("/api/scheduler/admin/v1/emails").Subrouter()
+	emailHistoryAdminRouter.Use(authMiddleware)
+	emailHistoryAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.AdminRoles...))
+	emailHistoryAdminRouter.HandleFunc("/subscribers/{subscriberId}", emailHistoryHandler.GetSubscriberHistory).Methods("GET", "OPTIONS")
+	emailHistoryAdminRouter.HandleFunc("/types/{emailType}", emailHistoryHandler.GetTypeHistory).Methods("GET", "OPTIONS")
+
+	// Admin endpoints for the schedule registry
+	scheduleHandler := handlers.NewScheduleHandler(schedulerService, scheduleRegistry, scheduleReconciler)
+	scheduleAdminRouter := router.PathPrefix("/api/scheduler/admin/v1/schedules").Subrouter()
+	scheduleAdminRouter.Use(authMiddleware)
+	scheduleAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.AdminRoles...))
+	scheduleAdminRouter.HandleFunc("", scheduleHandler.ListJobs).Methods("GET", "OPTIONS")
+	scheduleAdminRouter.HandleFunc("/reconcile", scheduleHandler.GetReconcileReport).Methods("GET", "OPTIONS")
+	scheduleAdminRouter.HandleFunc("/reconcile", scheduleHandler.Reconcile).Methods("POST")
+	scheduleAdminRouter.HandleFunc("/{name}/cancel", scheduleHandler.CancelJob).Methods("POST", "OPTIONS")
+	scheduleAdminRouter.HandleFunc("/{name}/fire", scheduleHandler.FireJob).Methods("POST", "OPTIONS")
+
+	// Runtime counters (expvar), e.g. scheduler_past_due_jobs
+	metricsAdminRouter := router.PathPrefix("/api/scheduler/admin/v1/metrics").Subrouter()
+	metricsAdminRouter.Use(authMiddleware)
+	metricsAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.AdminRoles...))
+	metricsAdminRouter.Handle("", expvar.Handler()).Methods("GET", "OPTIONS")
+
+	// Admin endpoints for per-organization reminder rules
+	reminderRuleHandler := handlers.NewReminderRuleHandler(reminderRuleStore)
+	reminderRuleAdminRouter := router.PathPrefix("/api/scheduler/admin/v1/reminder-rules").Subrouter()
+	reminderRuleAdminRouter.Use(authMiddleware)
+	reminderRuleAdminRouter.Use(auth.AdminMiddleware(roleAuthorizer, cfg.AdminRoles...))
+	reminderRuleAdminRouter.HandleFunc("/defaults", reminderRuleHandler.GetDefaultRules).Methods("GET", "OPTIONS")
+	reminderRuleAdminRouter.HandleFunc("/{organizationId}", reminderRuleHandler.GetRules).Methods("GET", "OPTIONS")
+	reminderRuleAdminRouter.HandleFunc("/{organizationId}", reminderRuleHandler.PutRules).Methods("PUT")
+	reminderRuleAdminRouter.HandleFunc("/{organizationId}", reminderRuleHandler.DeleteRules).Methods("DELETE")
+
 	// Create health handler for health check endpoints
 	healthHandler := handlers.NewHealthHandler(dbService)
+	healthHandler.AddLivenessCheck("workers", workers.Check)
 
 	// Healthcheck endpoints (no authentication required)
 	router.HandleFunc("/api/scheduler/health", healthHandler.HandleHealth).Methods("GET", "OPTIONS")
@@ -274,15 +520,10 @@ func setupHTTPServer(cfg config.Config, subscriberService *services.SubscriberSe
 	router.HandleFunc("/healthz", healthHandler.HandleHealth).Methods("GET", "OPTIONS")   // General health endpoint for both liveness and readiness
 	router.HandleFunc("/readyz", healthHandler.HandleReadiness).Methods("GET", "OPTIONS") // Specific readiness probe endpoint
 	router.HandleFunc("/livez", healthHandler.HandleLiveness).Methods("GET", "OPTIONS")   // Specific liveness probe endpoint	// Start HTTP server
-	serverAddr := cfg.ServerHost + ":" + cfg.ServerPort
-	log.Printf("Starting HTTP server on %s", serverAddr)
-
-	server := &http.Server{
-		Addr:    serverAddr,
+	return &http.Server{
+		Addr:    cfg.ServerHost + ":" + cfg.ServerPort,
 		Handler: router,
 	}
-
-	log.Fatal(server.ListenAndServe())
 }
 
 // testGetUserEmail tests the GetUserEmailByID function with the provided user ID
@@ -0,0 +1,29 @@
+-- Migration: Create Email Outbox
+-- Version: 004
+-- Description: Transactional outbox and delivery log for every outgoing email
+
+-- Create ENUM for outbox delivery states
+CREATE TYPE email_outbox_status AS ENUM ('pending', 'sending', 'sent', 'dead');
+
+-- Create email_outbox table
+CREATE TABLE email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    subscriber_id INT REFERENCES subscribers(subscriber_id) ON DELETE SET NULL,
+    recipient VARCHAR(255) NOT NULL,
+    email_type VARCHAR(100) NOT NULL,   -- e.g. SESSION_UPDATED, ORDER_CONFIRMED
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status email_outbox_status NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),  -- also used as the lease expiry while 'sending'
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP
+);
+
+-- Create indexes for the dispatcher and for delivery history lookups
+CREATE INDEX idx_email_outbox_due ON email_outbox(status, next_attempt_at) WHERE status IN ('pending', 'sending');
+CREATE INDEX idx_email_outbox_subscriber_id ON email_outbox(subscriber_id, created_at);
+CREATE INDEX idx_email_outbox_email_type ON email_outbox(email_type, created_at);
+CREATE INDEX idx_email_outbox_recipient ON email_outbox(recipient);
@@ -0,0 +1,15 @@
+-- Migration: Create Notification Dedupe
+-- Version: 005
+-- Description: Remember which notifications were sent so redelivered or replayed source events are skipped
+
+-- Create notification_dedupe table
+CREATE TABLE notification_dedupe (
+    fingerprint CHAR(64) PRIMARY KEY,   -- SHA-256 of source change, recipient and email type
+    source_key TEXT NOT NULL,           -- e.g. public.event_sessions:<id>:u:lsn=...:tx=...
+    recipient VARCHAR(255) NOT NULL,
+    email_type VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create index for pruning old entries
+CREATE INDEX idx_notification_dedupe_created_at ON notification_dedupe(created_at);
@@ -0,0 +1,18 @@
+-- Migration: Create Notification Preferences
+-- Version: 006
+-- Description: Let subscribers opt in/out of notification types and choose delivery channels
+
+-- Create notification_preferences table
+CREATE TABLE notification_preferences (
+    preference_id SERIAL PRIMARY KEY,
+    subscriber_id INT NOT NULL REFERENCES subscribers(subscriber_id) ON DELETE CASCADE,
+    category VARCHAR(50) NOT NULL,                  -- SESSION / EVENT / ORDER / PAYMENT / ORGANIZATION
+    email_type VARCHAR(100) NOT NULL DEFAULT '*',   -- e.g. SESSION_START_REMINDER, '*' for the whole category
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    channels TEXT[] NOT NULL DEFAULT '{EMAIL}',     -- delivery channels when enabled
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(subscriber_id, category, email_type)
+);
+
+-- Create index for looking up a subscriber's preferences
+CREATE INDEX idx_notification_preferences_subscriber_id ON notification_preferences(subscriber_id);
@@ -0,0 +1,5 @@
+-- Migration: Add Email Outbox Headers
+-- Version: 007
+-- Description: Store extra email headers (e.g. List-Unsubscribe) with each outbox message
+
+ALTER TABLE email_outbox ADD COLUMN headers JSONB;
@@ -0,0 +1,17 @@
+-- Migration: Create Local Schedules
+-- Version: 008
+-- Description: One-off schedules for the Postgres scheduler backend (SCHEDULER_BACKEND=postgres)
+
+CREATE TABLE local_schedules (
+    name VARCHAR(255) PRIMARY KEY,      -- e.g. session-onsale-<session id>
+    target VARCHAR(50) NOT NULL,        -- SESSION_SCHEDULING or SESSION_REMINDERS
+    fire_at TIMESTAMPTZ NOT NULL,
+    payload TEXT NOT NULL,              -- JSON message body sent to the target queue
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create index for the ticker that fires due schedules
+CREATE INDEX idx_local_schedules_fire_at ON local_schedules(fire_at);
@@ -0,0 +1,24 @@
+-- Migration: Create Scheduled Jobs
+-- Version: 009
+-- Description: Registry mirroring every session schedule created, updated or deleted in the scheduler backend
+
+-- Create scheduled_jobs table
+CREATE TABLE scheduled_jobs (
+    name VARCHAR(255) PRIMARY KEY,      -- schedule name: prefix + session id
+    prefix VARCHAR(100) NOT NULL,       -- e.g. session-onsale-, sale-start-reminder-
+    session_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255),
+    target VARCHAR(50) NOT NULL,        -- SESSION_SCHEDULING or SESSION_REMINDERS
+    target_arn TEXT,                    -- SQS queue ARN the schedule delivers to
+    payload TEXT NOT NULL,
+    fire_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL,        -- scheduled, failed, deleted, cancelled, fired
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for the admin lookups
+CREATE INDEX idx_scheduled_jobs_session_id ON scheduled_jobs(session_id);
+CREATE INDEX idx_scheduled_jobs_event_id ON scheduled_jobs(event_id);
+CREATE INDEX idx_scheduled_jobs_fire_at ON scheduled_jobs(fire_at);
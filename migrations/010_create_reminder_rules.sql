@@ -0,0 +1,16 @@
+-- Migration: Create Reminder Rules
+-- Version: 010
+-- Description: Per-organization reminder rules replacing the default REMINDER_RULES for that organization's sessions
+
+-- Create reminder_rules table
+CREATE TABLE reminder_rules (
+    id SERIAL PRIMARY KEY,
+    organization_id VARCHAR(255) NOT NULL,
+    name VARCHAR(27) NOT NULL,          -- schedule name prefix without the trailing dash, fits the 64 character schedule name
+    anchor VARCHAR(20) NOT NULL,        -- start, sales-start or end
+    offset_seconds BIGINT NOT NULL,     -- negative fires before the anchor
+    reminder_type VARCHAR(50) NOT NULL, -- SESSION_START or SALE_START
+    template_id VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (organization_id, name)
+);
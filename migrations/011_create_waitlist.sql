@@ -0,0 +1,38 @@
+-- Migration: Create Waitlist
+-- Version: 011
+-- Description: Waitlist subscriptions for sold-out sessions, seats freed for them and the claim offers sent
+
+-- Waitlist entries are subscriptions with the waitlist category, queued by subscribed_at
+ALTER TYPE subscription_category ADD VALUE IF NOT EXISTS 'waitlist';
+
+-- Seats freed per session that still have to be offered to the waitlist
+CREATE TABLE waitlist_sessions (
+    session_id VARCHAR(255) PRIMARY KEY,
+    event_id VARCHAR(255),
+    pending_seats INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- One row per change that freed seats, so a redelivered event frees them only once
+CREATE TABLE waitlist_releases (
+    source VARCHAR(255) PRIMARY KEY,    -- e.g. order-cancelled:<order id>
+    session_id VARCHAR(255) NOT NULL,
+    seats INT NOT NULL,
+    released_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Time-limited claim offers sent to waitlisted subscribers
+CREATE TABLE waitlist_offers (
+    offer_id SERIAL PRIMARY KEY,
+    session_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255),
+    subscriber_id INT NOT NULL REFERENCES subscribers(subscriber_id) ON DELETE CASCADE,
+    offered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    claimed_at TIMESTAMPTZ,
+    expired_at TIMESTAMPTZ,
+    UNIQUE(session_id, subscriber_id)
+);
+
+-- Create index for finding open offers past their deadline
+CREATE INDEX idx_waitlist_offers_open ON waitlist_offers(expires_at) WHERE claimed_at IS NULL AND expired_at IS NULL;
@@ -0,0 +1,17 @@
+-- Migration: Create Queue Messages
+-- Version: 012
+-- Description: Messages of the Postgres queue backend (QUEUE_BACKEND=postgres)
+
+CREATE TABLE queue_messages (
+    id BIGSERIAL PRIMARY KEY,
+    queue VARCHAR(100) NOT NULL,        -- e.g. session-scheduling, session-reminders
+    body TEXT NOT NULL,
+    attributes JSONB,                   -- e.g. dlq.error on dead-lettered messages
+    receive_count INT NOT NULL DEFAULT 0,
+    receipt_handle VARCHAR(64),         -- changes on every receive, so a stale delivery cannot ack the message
+    visible_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Create index for receiving the oldest visible messages of a queue
+CREATE INDEX idx_queue_messages_visible ON queue_messages(queue, visible_at, id);
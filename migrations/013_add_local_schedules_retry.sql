@@ -0,0 +1,10 @@
+-- Migration: Add Local Schedules Retry
+-- Version: 013
+-- Description: Claim due local schedules before sending them and back off failed sends
+
+ALTER TABLE local_schedules ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE local_schedules ADD COLUMN claim_token VARCHAR(64);   -- set while a ticker is sending the schedule
+
+-- Replace the fire time index with one covering the claim query
+DROP INDEX IF EXISTS idx_local_schedules_fire_at;
+CREATE INDEX idx_local_schedules_due ON local_schedules(fire_at, next_attempt_at);
@@ -0,0 +1,5 @@
+-- Migration: Add Email Outbox Claim Token
+-- Version: 014
+-- Description: Fence outbox deliveries so a dispatcher whose lease expired cannot record the outcome of a reclaimed message
+
+ALTER TABLE email_outbox ADD COLUMN claim_token VARCHAR(64);   -- set while a dispatcher is sending the message